@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"podium/internal/store"
+)
+
+// runCommand runs a CLI command against a running Podium server, and
+// reports whether args name one.
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "backup":
+		runBackup(args[1:])
+	case "restore":
+		runRestore(args[1:])
+	default:
+		return false
+	}
+	return true
+}
+
+// runBackup saves a snapshot of the server's state to a file.
+func runBackup(args []string) {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	server := flags.String("server", "http://localhost:8080", "Address of the Podium server")
+	output := flags.String("o", fmt.Sprintf("podium-%s.db", time.Now().UTC().Format("20060102T150405Z")), "File to write the snapshot to")
+	flags.Parse(args)
+
+	resp, err := http.Get(*server + "/api/admin/backup")
+	if err != nil {
+		log.Fatalf("Backup failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("Backup failed: %s", responseError(resp))
+	}
+
+	// The snapshot is checked before it replaces anything at the output path.
+	f, err := os.CreateTemp(".", ".podium-backup-*")
+	if err != nil {
+		log.Fatalf("Backup failed: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	n, err := io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatalf("Backup failed: %v", err)
+	}
+	if err := store.ValidateSnapshotFile(f.Name()); err != nil {
+		log.Fatalf("Backup failed: %v", err)
+	}
+	if err := os.Rename(f.Name(), *output); err != nil {
+		log.Fatalf("Backup failed: %v", err)
+	}
+	fmt.Printf("Snapshot of %d bytes written to %s\n", n, *output)
+}
+
+// runRestore restores the server's state from a snapshot file and prints
+// how the runtime was reconciled.
+func runRestore(args []string) {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	server := flags.String("server", "http://localhost:8080", "Address of the Podium server")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: podium restore [--server address] <snapshot>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	path := flags.Arg(0)
+
+	if err := store.ValidateSnapshotFile(path); err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+	defer f.Close()
+
+	resp, err := http.Post(*server+"/api/admin/restore", "application/octet-stream", f)
+	if err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("Restore failed: %s", responseError(resp))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+	var report bytes.Buffer
+	if err := json.Indent(&report, body, "", "  "); err != nil {
+		report.Write(body)
+	}
+	fmt.Println(report.String())
+}
+
+// responseError returns the error message of an API error response.
+func responseError(resp *http.Response) string {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+		return resp.Status
+	}
+	return body.Error
+}
@@ -1,39 +1,129 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strings"
 	"time"
 	
+	"podium/internal/admin"
 	"podium/internal/api"
+	"podium/internal/dns"
+	"podium/internal/events"
 	"podium/internal/health"
+	"podium/internal/image"
+	"podium/internal/metrics"
+	"podium/internal/network"
+	"podium/internal/proxy"
 	"podium/internal/runtime"
 	"podium/internal/service"
+	"podium/internal/stats"
 	"podium/internal/store"
+	"podium/internal/volume"
+	"podium/internal/webhook"
 )
 
 func main() {
+	if runCommand(os.Args[1:]) {
+		return
+	}
+
+	storeName := flag.String("store", "bolt", "State store to use (bolt, sqlite, memory)")
+	runtimeName := flag.String("runtime", "docker", "Container runtime to use (docker, containerd, fake)")
+	containerdAddress := flag.String("containerd-address", "/run/containerd/containerd.sock", "containerd socket address")
+	containerdNamespace := flag.String("containerd-namespace", "podium", "containerd namespace for Podium containers")
+	ingressHTTP := flag.String("ingress-http", "", "Address of the ingress's HTTP entry point, such as :80 (disabled if empty)")
+	ingressHTTPS := flag.String("ingress-https", "", "Address of the ingress's HTTPS entry point, such as :443 (disabled if empty)")
+	certDir := flag.String("cert-dir", "certs", "Directory of ingress TLS certificates and the internal CA")
+	dnsEnabled := flag.Bool("dns", false, "Run the embedded DNS server and make it the resolver of Podium's containers")
+	dnsAddr := flag.String("dns-addr", "", "Address of the embedded DNS server (default port 53 of the address containers reach the host at)")
+	backupDir := flag.String("backup-dir", "backups", "Directory of scheduled volume backups")
+	dnsUpstream := flag.String("dns-upstream", "", "Comma-separated name servers other queries are forwarded to (default from /etc/resolv.conf)")
+	flag.Parse()
+
 	log.Println("It's Podium baby")
 	
-	boltStore, err := store.NewBoltStore("podium.db")
+	stateStore, err := newStore(*storeName)
 	if err != nil {
-		log.Fatalf("Failed to create store: %v", err)
+		log.Fatalf("Failed to create %s store: %v", *storeName, err)
 	}
-	defer boltStore.Close()
+	defer stateStore.Close()
 	
-	dockerRuntime, err := runtime.NewDockerRuntime()
+	metrics.RegisterContainerCollector(stateStore.ListContainers)
+
+	containerRuntime, err := newRuntime(*runtimeName, *containerdAddress, *containerdNamespace)
 	if err != nil {
-		log.Fatalf("Failed to create Docker runtime: %v", err)
+		log.Fatalf("Failed to create %s runtime: %v", *runtimeName, err)
 	}
 	
-	serviceManager := service.NewManager(dockerRuntime, boltStore)
+	var dnsServer *dns.Server
+	if *dnsEnabled {
+		var upstreams []string
+		if *dnsUpstream != "" {
+			upstreams = strings.Split(*dnsUpstream, ",")
+		}
+		dnsServer, err = dns.NewServer(stateStore, containerRuntime, *dnsAddr, upstreams, 5*time.Second)
+		if err != nil {
+			log.Printf("DNS server disabled: %v", err)
+		} else if resolver := dnsServer.Resolver(); resolver != nil {
+			containerRuntime = runtime.WithResolver(containerRuntime, resolver, []string{dns.Domain})
+		}
+	}
+
+	eventBus := events.NewBus(stateStore, 10000)
+
+	webhookDispatcher := webhook.NewDispatcher(stateStore, eventBus)
+	if err := webhookDispatcher.Start(); err != nil {
+		log.Fatalf("Failed to start webhook dispatcher: %v", err)
+	}
+	defer webhookDispatcher.Stop()
+
+	puller := image.NewPuller(containerRuntime, stateStore)
+
+	networkManager := network.NewManager(stateStore, containerRuntime, time.Minute)
+	networkManager.Start()
+	defer networkManager.Stop()
+
+	volumeManager := volume.NewManager(stateStore, containerRuntime, *backupDir, time.Minute)
+	volumeManager.Start()
+	defer volumeManager.Stop()
+
+	serviceManager := service.NewManager(containerRuntime, stateStore, puller, networkManager, volumeManager, eventBus)
+
+	if dnsServer != nil {
+		dnsServer.Start(serviceManager)
+		defer dnsServer.Stop()
+	}
+
+	loadBalancer := proxy.NewProxy(stateStore, containerRuntime, serviceManager, 5*time.Second)
+	if *ingressHTTP != "" || *ingressHTTPS != "" {
+		err := loadBalancer.EnableIngress(proxy.IngressConfig{
+			HTTPAddr:  *ingressHTTP,
+			HTTPSAddr: *ingressHTTPS,
+			CertDir:   *certDir,
+		})
+		if err != nil {
+			log.Printf("Ingress disabled: %v", err)
+		}
+	}
+	loadBalancer.Start()
+	defer loadBalancer.Stop()
 	
 	reconciler := service.NewReconciler(serviceManager, 30*time.Second)
 	reconciler.Start()
 	defer reconciler.Stop()
 	
-	server := api.NewServer(boltStore, dockerRuntime, serviceManager)
+	statsCollector := stats.NewCollector(stateStore, containerRuntime, 15*time.Second, time.Hour)
+	statsCollector.Start()
+	defer statsCollector.Stop()
 	
-	healthWorker := health.NewWorker(boltStore, dockerRuntime, 30*time.Second, 3)
+	restorer := admin.NewRestorer(stateStore, containerRuntime, puller, serviceManager)
+
+	server := api.NewServer(stateStore, containerRuntime, serviceManager, puller, statsCollector, eventBus, webhookDispatcher, networkManager, volumeManager, restorer)
+
+	healthWorker := health.NewWorker(stateStore, containerRuntime, eventBus, 30*time.Second, 3)
 	healthWorker.Start()
 	defer healthWorker.Stop()
 	
@@ -41,4 +131,31 @@ func main() {
 	if err := server.Start(":8080"); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
+}
+
+func newStore(name string) (store.Store, error) {
+	switch name {
+	case "bolt":
+		return store.NewBoltStore("podium.db")
+	case "sqlite":
+		return store.NewSQLiteStore("podium.sqlite")
+	case "memory":
+		log.Println("Using the memory store: state is lost when Podium stops")
+		return store.NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown store: %s", name)
+	}
+}
+
+func newRuntime(name, containerdAddress, containerdNamespace string) (runtime.Runtime, error) {
+	switch name {
+	case "docker":
+		return runtime.NewDockerRuntime()
+	case "containerd":
+		return runtime.NewContainerdRuntime(containerdAddress, containerdNamespace, "logs", "volumes")
+	case "fake":
+		return runtime.NewFakeRuntime(), nil
+	default:
+		return nil, fmt.Errorf("unknown runtime: %s", name)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,194 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"podium/internal/image"
+	"podium/internal/models"
+	"podium/internal/runtime"
+	"podium/internal/service"
+	"podium/internal/store"
+)
+
+// Restorer restores the store from a snapshot and brings the runtime in line
+// with the restored desired state.
+type Restorer struct {
+	store    store.Store
+	runtime  runtime.Runtime
+	puller   *image.Puller
+	services service.Manager
+
+	// mu keeps restores from overlapping.
+	mu sync.Mutex
+}
+
+// Report says what a restore changed in the runtime. Errors lists what could
+// not be reconciled; the restored state is kept regardless.
+type Report struct {
+	ContainersRemoved   []string `json:"containersRemoved"`
+	ContainersRecreated []string `json:"containersRecreated"`
+	ContainersStarted   []string `json:"containersStarted"`
+	ContainersStopped   []string `json:"containersStopped"`
+	Errors              []string `json:"errors"`
+}
+
+func (r *Report) fail(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	log.Printf("Restore: %s", message)
+	r.Errors = append(r.Errors, message)
+}
+
+func NewRestorer(store store.Store, runtime runtime.Runtime, puller *image.Puller, services service.Manager) *Restorer {
+	return &Restorer{
+		store:    store,
+		runtime:  runtime,
+		puller:   puller,
+		services: services,
+	}
+}
+
+// Restore validates the snapshot at path, replaces the store with it and
+// reconciles the runtime against it.
+func (r *Restorer) Restore(ctx context.Context, path string) (Report, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.store.ValidateSnapshot(path); err != nil {
+		return Report{}, err
+	}
+	if err := r.store.Restore(path); err != nil {
+		return Report{}, fmt.Errorf("failed to restore store: %w", err)
+	}
+	log.Println("Store restored from snapshot, reconciling runtime")
+
+	report := r.reconcile(ctx)
+	log.Printf("Restore reconciled: %d containers removed, %d recreated, %d started, %d stopped, %d errors",
+		len(report.ContainersRemoved), len(report.ContainersRecreated), len(report.ContainersStarted),
+		len(report.ContainersStopped), len(report.Errors))
+	return report, nil
+}
+
+// reconcile makes the runtime match the store:
+//   - networks and volumes in the store are created if missing;
+//   - containers the store does not know of are removed;
+//   - standalone containers missing from the runtime are recreated, while
+//     missing service replicas are left to the service reconciler;
+//   - containers are started or stopped to match their stored state;
+//   - finally, every service is reconciled.
+func (r *Restorer) reconcile(ctx context.Context) Report {
+	report := Report{
+		ContainersRemoved:   []string{},
+		ContainersRecreated: []string{},
+		ContainersStarted:   []string{},
+		ContainersStopped:   []string{},
+		Errors:              []string{},
+	}
+
+	networks, err := r.store.ListNetworks()
+	if err != nil {
+		report.fail("failed to list networks: %v", err)
+	}
+	for _, network := range networks {
+		if err := r.runtime.CreateNetwork(ctx, network); err != nil {
+			report.fail("network %s: %v", network.Name, err)
+		}
+	}
+
+	volumes, err := r.store.ListVolumes()
+	if err != nil {
+		report.fail("failed to list volumes: %v", err)
+	}
+	for _, volume := range volumes {
+		if _, err := r.runtime.CreateVolume(ctx, volume); err != nil {
+			report.fail("volume %s: %v", volume.Name, err)
+		}
+	}
+
+	containers, err := r.store.ListContainers()
+	if err != nil {
+		report.fail("failed to list containers: %v", err)
+		return report
+	}
+	ids, err := r.runtime.ListContainers(ctx)
+	if err != nil {
+		report.fail("failed to list runtime containers: %v", err)
+		return report
+	}
+
+	stored := make(map[string]bool, len(containers))
+	for _, container := range containers {
+		stored[container.ID] = true
+	}
+	existing := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		existing[id] = true
+		if stored[id] {
+			continue
+		}
+		if err := r.runtime.DeleteContainer(ctx, id); err != nil {
+			report.fail("container %s: %v", id, err)
+			continue
+		}
+		report.ContainersRemoved = append(report.ContainersRemoved, id)
+	}
+
+	for _, container := range containers {
+		wantRunning := container.State == models.ContainerStateRunning
+
+		if !existing[container.ID] {
+			if container.Labels["podium.service.id"] != "" {
+				continue
+			}
+			if err := r.recreate(ctx, container, wantRunning); err != nil {
+				report.fail("container %s: %v", container.ID, err)
+				continue
+			}
+			report.ContainersRecreated = append(report.ContainersRecreated, container.ID)
+			continue
+		}
+
+		state, err := r.runtime.GetContainerStatus(ctx, container.ID)
+		if err != nil {
+			report.fail("container %s: %v", container.ID, err)
+			continue
+		}
+		running := state == models.ContainerStateRunning
+		switch {
+		case wantRunning && !running:
+			if err := r.runtime.StartContainer(ctx, container.ID); err != nil {
+				report.fail("container %s: %v", container.ID, err)
+				continue
+			}
+			report.ContainersStarted = append(report.ContainersStarted, container.ID)
+		case !wantRunning && running:
+			if err := r.runtime.StopContainer(ctx, container.ID); err != nil {
+				report.fail("container %s: %v", container.ID, err)
+				continue
+			}
+			report.ContainersStopped = append(report.ContainersStopped, container.ID)
+		}
+	}
+
+	if err := r.services.ReconcileServices(ctx); err != nil {
+		report.fail("failed to reconcile services: %v", err)
+	}
+	return report
+}
+
+// recreate creates a standalone container from its stored spec, and starts
+// it if it should be running.
+func (r *Restorer) recreate(ctx context.Context, container models.Container, start bool) error {
+	if err := r.puller.EnsureImage(ctx, container.Image, models.PullPolicyIfNotPresent); err != nil {
+		return err
+	}
+	if err := r.runtime.CreateContainer(ctx, container); err != nil {
+		return err
+	}
+	if !start {
+		return nil
+	}
+	return r.runtime.StartContainer(ctx, container.ID)
+}
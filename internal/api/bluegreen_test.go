@@ -0,0 +1,130 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"podium/internal/models"
+)
+
+// blueGreenSpec is a service updated blue-green, whose replica 0 publishes a
+// host port. Its replicas pass their health check as soon as they run.
+const blueGreenSpec = `{
+	"name": "web",
+	"image": "%s",
+	"replicas": 2,
+	"ports": [{"containerPort": 80, "hostPort": 8080}],
+	"ingress": [{"host": "web.example.com"}],
+	"healthCheck": {"type": "command", "command": ["true"], "interval": 20000000},
+	"updateConfig": {"strategy": "blue-green", "standbyRetention": %d}
+}`
+
+// cutOver creates a blue-green service on nginx:1.26 and updates it to
+// nginx:1.27, returning the service and its replicas from before the update.
+func cutOver(t *testing.T, server *testServer, retention time.Duration) (models.Service, map[string]models.Container) {
+	t.Helper()
+
+	svc := server.createService(t, fmt.Sprintf(blueGreenSpec, "nginx:1.26", retention))
+	blue := server.replicas(t, svc.ID)
+	server.do(t, "PUT", "/api/services/"+svc.ID, fmt.Sprintf(blueGreenSpec, "nginx:1.27", retention), http.StatusAccepted, nil)
+	return server.waitForUpdate(t, svc.ID, models.UpdateStateCompleted), blue
+}
+
+func TestBlueGreenCutover(t *testing.T) {
+	server := newTestServer(t)
+	updated, blue := cutOver(t, server, time.Hour)
+
+	green := server.replicas(t, updated.ID)
+	if len(green) != 2 || len(updated.ContainerIDs) != 2 {
+		t.Fatalf("got replicas %v, want 2", updated.ContainerIDs)
+	}
+	for index, replica := range green {
+		if replica.Image != "nginx:1.27" {
+			t.Errorf("replica %s runs %s, want nginx:1.27", index, replica.Image)
+		}
+	}
+	if ports := green["0"].Ports; len(ports) != 1 || ports[0].HostPort != 8080 {
+		t.Errorf("replica 0 publishes %+v, want host port 8080", ports)
+	}
+	if ports := green["1"].Ports; len(ports) != 1 || ports[0].HostPort != 0 {
+		t.Errorf("replica 1 publishes %+v, want no host port", ports)
+	}
+
+	standby := updated.Standby
+	if standby == nil || standby.Revision != 1 || len(standby.ContainerIDs) != 2 {
+		t.Fatalf("got standby %+v, want both revision 1 replicas", standby)
+	}
+	// The blue replica that held the host ports is stopped to free them;
+	// the other keeps running.
+	for index, want := range map[string]models.ContainerState{"0": models.ContainerStateSucceeded, "1": models.ContainerStateRunning} {
+		container, err := server.store.GetContainer(blue[index].ID)
+		if err != nil {
+			t.Fatalf("getting standby replica %s: %v", index, err)
+		}
+		if container.State != want {
+			t.Errorf("standby replica %s is %s, want %s", index, container.State, want)
+		}
+	}
+}
+
+func TestRollbackSwitchesToStandby(t *testing.T) {
+	server := newTestServer(t)
+	updated, blue := cutOver(t, server, time.Hour)
+
+	server.do(t, "POST", "/api/services/"+updated.ID+"/rollback", "", http.StatusAccepted, nil)
+	restored := server.waitForUpdate(t, updated.ID, models.UpdateStateRolledBack)
+
+	if restored.Revision != 1 || restored.Image != "nginx:1.26" {
+		t.Errorf("got revision %d with image %s, want revision 1 with nginx:1.26", restored.Revision, restored.Image)
+	}
+	replicas := server.replicas(t, updated.ID)
+	for index, replica := range blue {
+		if replicas[index].ID != replica.ID {
+			t.Errorf("replica %s is %s, want the standby replica %s back", index, replicas[index].ID, replica.ID)
+		}
+		if replicas[index].State != models.ContainerStateRunning {
+			t.Errorf("replica %s is %s, want running", index, replicas[index].State)
+		}
+	}
+	if restored.Standby == nil || restored.Standby.Revision != 2 || len(restored.Standby.ContainerIDs) != 2 {
+		t.Errorf("got standby %+v, want the revision 2 replicas", restored.Standby)
+	}
+}
+
+func TestStandbyReplicasExpire(t *testing.T) {
+	server := newTestServer(t)
+	updated, _ := cutOver(t, server, 100*time.Millisecond)
+
+	eventually(t, "standby replicas to be removed", func() bool {
+		return server.service(t, updated.ID).Standby == nil
+	})
+	if ids := server.runtime.ContainerIDs(); len(ids) != 2 {
+		t.Errorf("got %d containers in the runtime, want the 2 current replicas", len(ids))
+	}
+}
+
+func TestDeleteServiceRemovesStandbyReplicas(t *testing.T) {
+	server := newTestServer(t)
+	svc, _ := cutOver(t, server, time.Hour)
+	if svc.Standby == nil || len(svc.Standby.ContainerIDs) != 2 {
+		t.Fatalf("got standby %+v, want the two old replicas", svc.Standby)
+	}
+
+	server.do(t, "DELETE", "/api/services/"+svc.ID, "", http.StatusOK, nil)
+
+	if ids := server.runtime.ContainerIDs(); len(ids) != 0 {
+		t.Errorf("containers %v are left in the runtime", ids)
+	}
+	containers, err := server.store.ListContainers()
+	if err != nil {
+		t.Fatalf("listing containers: %v", err)
+	}
+	if len(containers) != 0 {
+		t.Errorf("got %d containers in the store, want none", len(containers))
+	}
+	if _, err := server.store.GetService(svc.ID); err == nil {
+		t.Errorf("service %s is still in the store", svc.ID)
+	}
+}
@@ -0,0 +1,98 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"podium/internal/models"
+	"podium/internal/service"
+)
+
+// canarySpec is a service of four replicas whose updates stop after
+// replacing one of them.
+const canarySpec = `{
+	"name": "web",
+	"image": "%s",
+	"replicas": 4,
+	"loadBalancer": {},
+	"healthCheck": {"type": "command", "command": ["true"], "interval": 20000000},
+	"updateConfig": {"strategy": "canary", "maxSurge": 1, "canaryWeight": 25}
+}`
+
+// startCanary creates a canary service on web:1 and updates it to web:2,
+// waiting for the canary to be running.
+func startCanary(t *testing.T, server *testServer) models.Service {
+	t.Helper()
+
+	svc := server.createService(t, fmt.Sprintf(canarySpec, "web:1"))
+	server.do(t, "PUT", "/api/services/"+svc.ID, fmt.Sprintf(canarySpec, "web:2"), http.StatusAccepted, nil)
+	return server.waitForUpdate(t, svc.ID, models.UpdateStateCanary)
+}
+
+func TestCanaryWaitsForPromotion(t *testing.T) {
+	server := newTestServer(t)
+	svc := startCanary(t, server)
+
+	// Replicas are replaced from the highest index down.
+	for index, replica := range server.replicas(t, svc.ID) {
+		want := "web:1"
+		if index == "3" {
+			want = "web:2"
+		}
+		if replica.Image != want {
+			t.Errorf("replica %s runs %s, want %s", index, replica.Image, want)
+		}
+	}
+
+	var status service.ServiceStatus
+	eventually(t, "the canary to be healthy", func() bool {
+		server.do(t, "GET", "/api/services/"+svc.ID+"/status", "", http.StatusOK, &status)
+		return status.Canary != nil && status.Canary.Canary.Healthy == 1
+	})
+	canary := status.Canary
+	if canary.Weight != 25 || canary.Revision != 2 || canary.StableRevision != 1 {
+		t.Errorf("got canary weight %d, revision %d and stable revision %d, want 25, 2 and 1", canary.Weight, canary.Revision, canary.StableRevision)
+	}
+	if canary.Canary.Replicas != 1 || canary.Stable.Replicas != 3 || canary.Canary.Running != 1 || canary.Stable.Running != 3 {
+		t.Errorf("got canary %+v and stable %+v, want 1 and 3 running replicas", canary.Canary, canary.Stable)
+	}
+
+	server.do(t, "POST", "/api/services/"+svc.ID+"/canary/promote", "", http.StatusOK, nil)
+	server.waitForUpdate(t, svc.ID, models.UpdateStateCompleted)
+	for index, replica := range server.replicas(t, svc.ID) {
+		if replica.Image != "web:2" {
+			t.Errorf("replica %s runs %s after promotion, want web:2", index, replica.Image)
+		}
+	}
+	server.do(t, "GET", "/api/services/"+svc.ID+"/status", "", http.StatusOK, &status)
+	if status.Canary != nil {
+		t.Errorf("got canary status %+v after promotion, want none", status.Canary)
+	}
+}
+
+func TestAbortCanary(t *testing.T) {
+	server := newTestServer(t)
+	svc := startCanary(t, server)
+
+	server.do(t, "POST", "/api/services/"+svc.ID+"/canary/abort", "", http.StatusOK, nil)
+	server.waitForUpdate(t, svc.ID, models.UpdateStateRolledBack)
+	for index, replica := range server.replicas(t, svc.ID) {
+		if replica.Image != "web:1" {
+			t.Errorf("replica %s runs %s after the abort, want web:1", index, replica.Image)
+		}
+	}
+}
+
+func TestPromoteCanaryWithoutCanary(t *testing.T) {
+	server := newTestServer(t)
+	svc := server.createService(t, fmt.Sprintf(canarySpec, "web:1"))
+	server.do(t, "POST", "/api/services/"+svc.ID+"/canary/promote", "", http.StatusConflict, nil)
+
+	// An aborted canary cannot be promoted either.
+	server.do(t, "PUT", "/api/services/"+svc.ID, fmt.Sprintf(canarySpec, "web:2"), http.StatusAccepted, nil)
+	server.waitForUpdate(t, svc.ID, models.UpdateStateCanary)
+	server.do(t, "POST", "/api/services/"+svc.ID+"/canary/abort", "", http.StatusOK, nil)
+	server.waitForUpdate(t, svc.ID, models.UpdateStateRolledBack)
+	server.do(t, "POST", "/api/services/"+svc.ID+"/canary/promote", "", http.StatusConflict, nil)
+}
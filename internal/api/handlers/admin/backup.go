@@ -0,0 +1,25 @@
+package admin
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// HandleBackup streams a snapshot of the store, taken while Podium keeps
+// running.
+func (h *Handler) HandleBackup(w http.ResponseWriter, r *http.Request) {
+	filename := fmt.Sprintf("podium-%s.db", time.Now().UTC().Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	n, err := h.store.Backup(w)
+	if err != nil {
+		// The status has been sent with the first bytes, so the client only
+		// sees a truncated snapshot, which restoring rejects.
+		log.Printf("Store backup failed after %d bytes: %v", n, err)
+		return
+	}
+	log.Printf("Store backup of %d bytes sent", n)
+}
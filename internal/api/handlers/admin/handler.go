@@ -0,0 +1,18 @@
+package admin
+
+import (
+	"podium/internal/admin"
+	"podium/internal/store"
+)
+
+type Handler struct {
+	store    store.Store
+	restorer *admin.Restorer
+}
+
+func NewHandler(store store.Store, restorer *admin.Restorer) *Handler {
+	return &Handler{
+		store:    store,
+		restorer: restorer,
+	}
+}
@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	"podium/internal/api/handlers"
+	"podium/internal/store"
+)
+
+// HandleRestore replaces the store with the snapshot in the request body and
+// reconciles the runtime against it.
+func (h *Handler) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	f, err := os.CreateTemp("", "podium-snapshot-*.db")
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to store snapshot: %v", err))
+		return
+	}
+	defer os.Remove(f.Name())
+
+	_, err = io.Copy(f, r.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Failed to receive snapshot: %v", err))
+		return
+	}
+
+	report, err := h.restorer.Restore(r.Context(), f.Name())
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, store.ErrInvalidSnapshot) {
+			status = http.StatusBadRequest
+		}
+		log.Printf("Restore failed: %v", err)
+		handlers.RespondWithError(w, status, fmt.Sprintf("Failed to restore: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, report)
+}
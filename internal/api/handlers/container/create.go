@@ -1,7 +1,9 @@
 package container
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,6 +12,9 @@ import (
 	"github.com/google/uuid"
 	"podium/internal/api/handlers"
 	"podium/internal/models"
+	"podium/internal/network"
+	"podium/internal/store"
+	"podium/internal/volume"
 )
 
 func (h *Handler) HandleCreate(w http.ResponseWriter, r *http.Request) {
@@ -27,11 +32,22 @@ func (h *Handler) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		log.Println("Validation failed: name or image is missing")
 		return
 	}
+	if err := network.ValidateAttachments(req.Networks); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid networks: "+err.Error())
+		log.Printf("Validation failed: %v", err)
+		return
+	}
+	if err := volume.ValidateMounts(req.Mounts); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid mounts: "+err.Error())
+		log.Printf("Validation failed: %v", err)
+		return
+	}
 
 	container := models.Container{
 		ID:            uuid.New().String(),
 		Name:          req.Name,
 		Image:         req.Image,
+		PullPolicy:    req.PullPolicy,
 		Command:       req.Command,
 		Env:           req.Env,
 		Ports:         req.Ports,
@@ -40,6 +56,46 @@ func (h *Handler) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		NodeID:        "local",
 		CreatedAt:     time.Now(),
 		RestartPolicy: req.RestartPolicy,
+		HealthCheck:   req.HealthCheck,
+		Networks:      req.Networks,
+		Mounts:        req.Mounts,
+	}
+
+	if err := h.networks.EnsureNetworks(r.Context(), container.Networks); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Failed to prepare networks: "+err.Error())
+		log.Printf("Network preparation failed: %v", err)
+		return
+	}
+	if err := h.volumes.EnsureVolumes(r.Context(), container.Mounts); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Failed to prepare volumes: "+err.Error())
+		log.Printf("Volume preparation failed: %v", err)
+		return
+	}
+
+	op, err := h.puller.Pull(r.Context(), container.Image, container.PullPolicy)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Failed to prepare image: "+err.Error())
+		log.Printf("Image preparation failed: %v", err)
+		return
+	}
+
+	// The image has to be pulled first, so the container is stored as pending
+	// and created once the pull operation finishes.
+	if op != nil {
+		if err := h.store.CreateContainer(container); err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Failed to store container")
+			log.Printf("Storing container failed: %v", err)
+			return
+		}
+
+		go h.createAfterPull(container, op.ID)
+
+		handlers.RespondWithJSON(w, http.StatusAccepted, map[string]interface{}{
+			"container": container,
+			"operation": op,
+		})
+		log.Printf("Container %s accepted, waiting for image pull operation %s", container.ID, op.ID)
+		return
 	}
 
 	if err := h.runtime.CreateContainer(r.Context(), container); err != nil {
@@ -57,6 +113,58 @@ func (h *Handler) HandleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.publishCreated(container)
+
 	handlers.RespondWithJSON(w, http.StatusCreated, container)
 	log.Printf("Container created successfully: ID=%s", container.ID)
 }
+
+func (h *Handler) createAfterPull(container models.Container, operationID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 35*time.Minute)
+	defer cancel()
+
+	op, err := h.puller.Wait(ctx, operationID)
+	if err == nil && op.State == models.OperationStateFailed {
+		err = fmt.Errorf("image pull failed: %s", op.Error)
+	}
+	// The container may have been deleted while its image was being pulled.
+	if _, lookupErr := h.store.GetContainer(container.ID); lookupErr != nil {
+		log.Printf("Not creating container %s after image pull: %v", container.ID, lookupErr)
+		return
+	}
+	if err == nil {
+		err = h.runtime.CreateContainer(ctx, container)
+	}
+	if err == nil {
+		if _, lookupErr := h.store.GetContainer(container.ID); errors.Is(lookupErr, store.ErrNotFound) {
+			log.Printf("Container %s was deleted while it was being created, removing it", container.ID)
+			if err := h.runtime.DeleteContainer(ctx, container.ID); err != nil {
+				log.Printf("Cleanup failed: could not delete container from runtime: %v", err)
+			}
+			return
+		}
+	}
+
+	if err != nil {
+		log.Printf("Container creation after image pull failed: %v", err)
+		container.State = models.ContainerStateFailed
+		now := time.Now()
+		container.FinishedAt = &now
+		if err := h.store.UpdateContainer(container); err != nil {
+			log.Printf("Failed to update container state: %v", err)
+		}
+		return
+	}
+
+	h.publishCreated(container)
+	log.Printf("Container created successfully after image pull: ID=%s", container.ID)
+}
+
+func (h *Handler) publishCreated(container models.Container) {
+	h.events.Publish(models.Event{
+		Type:        models.EventContainerCreated,
+		ContainerID: container.ID,
+		Message:     "Container created",
+		Attributes:  map[string]string{"name": container.Name, "image": container.Image},
+	})
+}
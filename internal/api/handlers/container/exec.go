@@ -0,0 +1,195 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+	"podium/internal/runtime"
+)
+
+// Exec WebSocket messages are binary frames whose first byte selects the
+// stream, in the style of Kubernetes' channel protocol.
+const (
+	execChannelStdin  byte = 0
+	execChannelStdout byte = 1
+	execChannelStderr byte = 2
+	execChannelStatus byte = 3
+	execChannelResize byte = 4
+)
+
+var execUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+}
+
+type execResize struct {
+	Width  uint `json:"width"`
+	Height uint `json:"height"`
+}
+
+type execStatus struct {
+	ExitCode *int   `json:"exitCode,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// execConn serialises writes to the WebSocket, which gorilla/websocket does
+// not allow concurrently.
+type execConn struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+func (c *execConn) send(channel byte, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	frame := make([]byte, 0, len(data)+1)
+	frame = append(frame, channel)
+	frame = append(frame, data...)
+	return c.conn.WriteMessage(websocket.BinaryMessage, frame)
+}
+
+func (c *execConn) sendStatus(status execStatus) {
+	data, _ := json.Marshal(status)
+	if err := c.send(execChannelStatus, data); err != nil {
+		log.Printf("Failed to send exec status: %v", err)
+	}
+}
+
+type execStreamWriter struct {
+	conn    *execConn
+	channel byte
+}
+
+func (w *execStreamWriter) Write(p []byte) (int, error) {
+	if err := w.conn.send(w.channel, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// HandleExec upgrades the request to a WebSocket and runs an interactive
+// command in the container. Query parameters: cmd (repeatable, defaults to
+// /bin/sh), tty, stdin (default true), width and height.
+//
+// Clients send stdin on channel 0 (an empty stdin frame closes stdin) and
+// {"width":W,"height":H} on channel 4 to resize the terminal. The server
+// sends stdout on channel 1, stderr on channel 2, and {"exitCode":N} or
+// {"error":"..."} on channel 3 before closing the connection.
+func (h *Handler) HandleExec(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	container, err := h.store.GetContainer(id)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Container not found: %v", err))
+		return
+	}
+	if container.State != models.ContainerStateRunning {
+		handlers.RespondWithError(w, http.StatusConflict, "Container is not running")
+		return
+	}
+
+	query := r.URL.Query()
+	opts := runtime.ExecOptions{
+		Cmd:   query["cmd"],
+		TTY:   query.Get("tty") == "true",
+		Stdin: query.Get("stdin") != "false",
+	}
+	if len(opts.Cmd) == 0 {
+		opts.Cmd = []string{"/bin/sh"}
+	}
+	if width, err := strconv.ParseUint(query.Get("width"), 10, 16); err == nil {
+		opts.Width = uint(width)
+	}
+	if height, err := strconv.ParseUint(query.Get("height"), 10, 16); err == nil {
+		opts.Height = uint(height)
+	}
+
+	ws, err := execUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed for exec in container %s: %v", id, err)
+		return
+	}
+	defer ws.Close()
+
+	conn := &execConn{conn: ws}
+	stdout := &execStreamWriter{conn: conn, channel: execChannelStdout}
+	stderr := &execStreamWriter{conn: conn, channel: execChannelStderr}
+
+	session, err := h.runtime.StartExec(r.Context(), id, opts, stdout, stderr)
+	if err != nil {
+		log.Printf("Error starting exec in container %s: %v", id, err)
+		conn.sendStatus(execStatus{Error: err.Error()})
+		return
+	}
+	defer session.Close()
+
+	log.Printf("Exec session started in container %s: %v", id, opts.Cmd)
+
+	go h.readExecInput(ws, session, id)
+
+	exitCode, err := session.Wait(r.Context())
+	if err != nil {
+		log.Printf("Exec session in container %s failed: %v", id, err)
+		conn.sendStatus(execStatus{Error: err.Error()})
+	} else {
+		log.Printf("Exec session in container %s exited with code %d", id, exitCode)
+		conn.sendStatus(execStatus{ExitCode: &exitCode})
+	}
+
+	conn.mu.Lock()
+	ws.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+	conn.mu.Unlock()
+}
+
+func (h *Handler) readExecInput(ws *websocket.Conn, session runtime.ExecSession, id string) {
+	for {
+		_, message, err := ws.ReadMessage()
+		if err != nil {
+			// The client went away; end the command rather than leave it running.
+			session.Close()
+			return
+		}
+		if len(message) == 0 {
+			continue
+		}
+
+		channel, data := message[0], message[1:]
+		switch channel {
+		case execChannelStdin:
+			if len(data) == 0 {
+				if err := session.CloseStdin(); err != nil {
+					log.Printf("Failed to close exec stdin in container %s: %v", id, err)
+				}
+				continue
+			}
+			if _, err := session.Write(data); err != nil {
+				log.Printf("Failed to write exec stdin in container %s: %v", id, err)
+			}
+		case execChannelResize:
+			var size execResize
+			if err := json.Unmarshal(data, &size); err != nil {
+				log.Printf("Invalid exec resize message: %v", err)
+				continue
+			}
+			if err := session.Resize(context.Background(), size.Width, size.Height); err != nil {
+				log.Printf("Failed to resize exec terminal in container %s: %v", id, err)
+			}
+		default:
+			log.Printf("Ignoring exec message on unknown channel %d", channel)
+		}
+	}
+}
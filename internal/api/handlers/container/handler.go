@@ -1,18 +1,33 @@
 package container
 
 import (
+	"podium/internal/events"
+	"podium/internal/image"
+	"podium/internal/network"
 	"podium/internal/runtime"
+	"podium/internal/stats"
 	"podium/internal/store"
+	"podium/internal/volume"
 )
 
 type Handler struct {
-	store   *store.BoltStore
-	runtime runtime.Runtime
+	store    store.Store
+	runtime  runtime.Runtime
+	puller   *image.Puller
+	networks *network.Manager
+	volumes  *volume.Manager
+	stats    *stats.Collector
+	events   *events.Bus
 }
 
-func NewHandler(store *store.BoltStore, runtime runtime.Runtime) *Handler {
+func NewHandler(store store.Store, runtime runtime.Runtime, puller *image.Puller, networks *network.Manager, volumes *volume.Manager, stats *stats.Collector, bus *events.Bus) *Handler {
 	return &Handler{
-		store:   store,
-		runtime: runtime,
+		store:    store,
+		runtime:  runtime,
+		puller:   puller,
+		networks: networks,
+		volumes:  volumes,
+		stats:    stats,
+		events:   bus,
 	}
 }
\ No newline at end of file
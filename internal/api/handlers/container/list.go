@@ -4,85 +4,58 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
 
 	"podium/internal/api/handlers"
 	"podium/internal/models"
 )
 
+// HandleList returns a page of containers. Query parameters: state, name,
+// service (a service ID), label (key=value, repeatable), sort (id, name,
+// state or createdAt), order (asc or desc), limit (default 100) and offset.
+// Filtering, sorting and paging are left to the store.
 func (h *Handler) HandleList(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request to list containers")
-	
+
 	query := r.URL.Query()
-	stateFilter := query.Get("state")
-	
-	limitStr := query.Get("limit")
-	offsetStr := query.Get("offset")
-	
-	var limit, offset int
-	var err error
-	
-	if limitStr != "" {
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil || limit < 1 {
-			limit = 100 
-		}
-	} else {
-		limit = 100 
-	}
-	
-	if offsetStr != "" {
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			offset = 0 
-		}
+	options, err := handlers.ParseListOptions(r, models.ContainerSortFields)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	
-	containers, err := h.store.ListContainers()
+	labels, err := handlers.ParseLabels(r)
 	if err != nil {
-		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list containers: %v", err))
-		log.Printf("Error listing containers from database: %v", err)
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	
-	var filteredContainers []models.Container
-	
-	if stateFilter != "" {
-		log.Printf("Filtering containers by state: %s", stateFilter)
-		for _, container := range containers {
-			if string(container.State) == stateFilter {
-				filteredContainers = append(filteredContainers, container)
-			}
-		}
-	} else {
-		filteredContainers = containers
+	filter := models.ContainerFilter{
+		State:     models.ContainerState(query.Get("state")),
+		Name:      query.Get("name"),
+		ServiceID: query.Get("service"),
+		Labels:    labels,
 	}
-	
-	totalCount := len(filteredContainers)
-	
-	if offset >= totalCount {
-		offset = 0
+
+	containers, totalCount, err := h.store.QueryContainers(filter, options)
+	// An offset past the end starts over from the first page.
+	if err == nil && len(containers) == 0 && options.Offset > 0 && options.Offset >= totalCount {
+		options.Offset = 0
+		containers, totalCount, err = h.store.QueryContainers(filter, options)
 	}
-	
-	endIndex := offset + limit
-	if endIndex > totalCount {
-		endIndex = totalCount
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list containers: %v", err))
+		log.Printf("Error listing containers from database: %v", err)
+		return
 	}
-	
-	var paginatedContainers []models.Container
-	if offset < totalCount {
-		paginatedContainers = filteredContainers[offset:endIndex]
-	} else {
-		paginatedContainers = []models.Container{}
+	if containers == nil {
+		containers = []models.Container{}
 	}
-	
+
 	response := map[string]interface{}{
-		"items":      paginatedContainers,
+		"items":      containers,
 		"totalCount": totalCount,
-		"limit":      limit,
-		"offset":     offset,
+		"limit":      options.Limit,
+		"offset":     options.Offset,
 	}
-	
+
 	handlers.RespondWithJSON(w, http.StatusOK, response)
-	log.Printf("Listed %d containers (filtered from %d total)", len(paginatedContainers), totalCount)
-}
\ No newline at end of file
+	log.Printf("Listed %d containers (filtered from %d total)", len(containers), totalCount)
+}
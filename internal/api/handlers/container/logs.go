@@ -2,12 +2,17 @@ package container
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gorilla/mux"
 	"podium/internal/api/handlers"
+	"podium/internal/runtime"
 )
 
+// HandleLogs returns a container's logs, demultiplexed into stdout and
+// stderr entries. With follow=true new entries are streamed until the
+// container stops or the client disconnects.
 func (h *Handler) HandleLogs(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -18,11 +23,33 @@ func (h *Handler) HandleLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logs, err := h.runtime.GetContainerLogs(r.Context(), id)
+	query, err := handlers.ParseLogQuery(r)
 	if err != nil {
-		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get container logs: %v", err))
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	handlers.RespondWithJSON(w, http.StatusOK, map[string]string{"logs": logs})
-}
\ No newline at end of file
+	if !query.Options.Follow {
+		entries := []handlers.LogEntry{}
+		err := h.runtime.GetContainerLogs(r.Context(), id, query.Options, func(entry runtime.LogEntry) error {
+			entries = append(entries, query.Entry(entry))
+			return nil
+		})
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get container logs: %v", err))
+			return
+		}
+		handlers.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"logs": entries})
+		return
+	}
+
+	stream := handlers.NewStream(w, r)
+	err = h.runtime.GetContainerLogs(r.Context(), id, query.Options, func(entry runtime.LogEntry) error {
+		logEntry := query.Entry(entry)
+		return stream.Send(logEntry.Stream, logEntry)
+	})
+	if err != nil && r.Context().Err() == nil {
+		log.Printf("Error streaming logs for container %s: %v", id, err)
+		stream.SendError(err)
+	}
+}
@@ -51,6 +51,12 @@ func (h *Handler) HandleStart(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("Warning: Failed to update container state in database: %v", err)
 	}
+
+	h.events.Publish(models.Event{
+		Type:        models.EventContainerStarted,
+		ContainerID: id,
+		Message:     "Container started",
+	})
 	
 	handlers.RespondWithJSON(w, http.StatusOK, container)
 	log.Printf("Container %s started successfully", id)
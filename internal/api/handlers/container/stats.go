@@ -0,0 +1,131 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+)
+
+const (
+	defaultStatsInterval = 2 * time.Second
+	minStatsInterval     = 500 * time.Millisecond
+)
+
+// HandleStats returns the container's current resource usage. With
+// stream=true a new sample is sent every interval (default 2s) until the
+// client disconnects.
+func (h *Handler) HandleStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if _, err := h.store.GetContainer(id); err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Container not found: %v", err))
+		return
+	}
+
+	query := r.URL.Query()
+	interval := defaultStatsInterval
+	if value := query.Get("interval"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < minStatsInterval {
+			handlers.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid interval: %s", value))
+			return
+		}
+		interval = d
+	}
+
+	sample, err := h.sampleStats(r.Context(), id)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get container stats: %v", err))
+		return
+	}
+
+	if query.Get("stream") != "true" {
+		handlers.RespondWithJSON(w, http.StatusOK, sample)
+		return
+	}
+
+	stream := handlers.NewStream(w, r)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := stream.Send("stats", sample); err != nil {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		next, err := h.runtime.GetContainerStats(r.Context(), id)
+		if err != nil {
+			if r.Context().Err() == nil {
+				log.Printf("Error streaming stats for container %s: %v", id, err)
+				stream.SendError(err)
+			}
+			return
+		}
+		next.CalculateCPUPercent(sample)
+		sample = next
+	}
+}
+
+// sampleStats takes a sample and works out its CPU percentage against the
+// collector's latest sample, or against a second sample taken a second
+// later when the collector has none.
+func (h *Handler) sampleStats(ctx context.Context, id string) (models.ContainerStats, error) {
+	sample, err := h.runtime.GetContainerStats(ctx, id)
+	if err != nil {
+		return models.ContainerStats{}, err
+	}
+
+	if prev, ok := h.stats.Latest(id); ok && prev.Timestamp.Before(sample.Timestamp) {
+		sample.CalculateCPUPercent(prev)
+		return sample, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return models.ContainerStats{}, ctx.Err()
+	case <-time.After(time.Second):
+	}
+
+	next, err := h.runtime.GetContainerStats(ctx, id)
+	if err != nil {
+		return models.ContainerStats{}, err
+	}
+	next.CalculateCPUPercent(sample)
+	return next, nil
+}
+
+// HandleStatsHistory returns the samples the stats collector has kept for
+// the container, optionally limited to those taken since a given time.
+func (h *Handler) HandleStatsHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if _, err := h.store.GetContainer(id); err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Container not found: %v", err))
+		return
+	}
+
+	since, err := handlers.ParseTime(r.URL.Query().Get("since"))
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid since: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"containerId": id,
+		"samples":     h.stats.History(id, since),
+	})
+}
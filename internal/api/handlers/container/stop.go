@@ -51,6 +51,12 @@ func (h *Handler) HandleStop(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("Warning: Failed to update container state in database: %v", err)
 	}
+
+	h.events.Publish(models.Event{
+		Type:        models.EventContainerStopped,
+		ContainerID: id,
+		Message:     "Container stopped",
+	})
 	
 	handlers.RespondWithJSON(w, http.StatusOK, container)
 	log.Printf("Container %s stopped successfully", id)
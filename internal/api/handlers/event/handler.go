@@ -0,0 +1,15 @@
+package event
+
+import (
+	"podium/internal/events"
+)
+
+type Handler struct {
+	events *events.Bus
+}
+
+func NewHandler(bus *events.Bus) *Handler {
+	return &Handler{
+		events: bus,
+	}
+}
@@ -0,0 +1,123 @@
+package event
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+)
+
+const defaultEventLimit = 100
+
+// HandleList returns recent events, oldest first. Query parameters: type
+// (comma-separated or repeated), container, service, since, until, after (an
+// event ID) and limit (default 100). With follow=true the matching events
+// are sent as Server-Sent Events, followed by new ones as they happen. The
+// stream ends if the client falls behind, and resumes from Last-Event-ID.
+func (h *Handler) HandleList(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if r.URL.Query().Get("follow") != "true" {
+		events, err := h.events.List(filter)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list events: %v", err))
+			return
+		}
+		if events == nil {
+			events = []models.Event{}
+		}
+		handlers.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"items":      events,
+			"totalCount": len(events),
+		})
+		return
+	}
+
+	// Resume after the last event the client saw when it reconnects.
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+		if id, err := strconv.ParseUint(lastID, 10, 64); err == nil && id > filter.AfterID {
+			filter.AfterID = id
+			filter.Limit = 0
+		}
+	}
+
+	backlog, live, cancel, err := h.events.Subscribe(filter)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list events: %v", err))
+		return
+	}
+	defer cancel()
+
+	stream := handlers.NewEventStream(w)
+
+	for _, event := range backlog {
+		if err := stream.SendWithID(strconv.FormatUint(event.ID, 10), string(event.Type), event); err != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-live:
+			// The bus drops subscribers that fall behind. Ending the stream
+			// makes the client reconnect with Last-Event-ID and catch up
+			// from the store.
+			if !ok {
+				return
+			}
+			if err := stream.SendWithID(strconv.FormatUint(event.ID, 10), string(event.Type), event); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func parseFilter(r *http.Request) (models.EventFilter, error) {
+	query := r.URL.Query()
+	filter := models.EventFilter{
+		ContainerID: query.Get("container"),
+		ServiceID:   query.Get("service"),
+		Limit:       defaultEventLimit,
+	}
+
+	for _, value := range query["type"] {
+		for _, t := range strings.Split(value, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				filter.Types = append(filter.Types, models.EventType(t))
+			}
+		}
+	}
+
+	var err error
+	if filter.Since, err = handlers.ParseTime(query.Get("since")); err != nil {
+		return filter, fmt.Errorf("invalid since: %v", err)
+	}
+	if filter.Until, err = handlers.ParseTime(query.Get("until")); err != nil {
+		return filter, fmt.Errorf("invalid until: %v", err)
+	}
+
+	if after := query.Get("after"); after != "" {
+		if filter.AfterID, err = strconv.ParseUint(after, 10, 64); err != nil {
+			return filter, fmt.Errorf("invalid after: %s", after)
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return filter, fmt.Errorf("invalid limit: %s", limit)
+		}
+		filter.Limit = n
+	}
+
+	return filter, nil
+}
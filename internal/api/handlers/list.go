@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"podium/internal/models"
+)
+
+const defaultListLimit = 100
+
+// ParseListOptions reads the limit (default 100), offset, sort and order
+// (asc or desc) query parameters of a list request. The list can be sorted
+// by one of fields. A limit or offset that is not a valid number falls back
+// to its default.
+func ParseListOptions(r *http.Request, fields []string) (models.ListOptions, error) {
+	query := r.URL.Query()
+	options := models.ListOptions{Limit: defaultListLimit, Sort: query.Get("sort")}
+
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit >= 1 {
+		options.Limit = limit
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset >= 0 {
+		options.Offset = offset
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		options.Descending = true
+	default:
+		return options, fmt.Errorf("order must be asc or desc")
+	}
+
+	return options, options.Validate(fields)
+}
+
+// ParseLabels reads the repeated label query parameter, each of the form
+// key=value.
+func ParseLabels(r *http.Request) (map[string]string, error) {
+	values := r.URL.Query()["label"]
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	labels := make(map[string]string, len(values))
+	for _, value := range values {
+		key, labelValue, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("label must be key=value: %q", value)
+		}
+		labels[key] = labelValue
+	}
+	return labels, nil
+}
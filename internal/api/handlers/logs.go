@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"podium/internal/runtime"
+)
+
+// LogEntry is a log line as returned by the logs endpoints.
+type LogEntry struct {
+	Stream    string `json:"stream"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Replica   *int   `json:"replica,omitempty"`
+	Line      string `json:"line"`
+}
+
+// LogQuery holds the query parameters shared by the logs endpoints.
+type LogQuery struct {
+	Options    runtime.LogOptions
+	Timestamps bool
+}
+
+// ParseLogQuery reads follow, since, until, tail, timestamps, stdout and
+// stderr from the request. since and until accept an RFC 3339 time, a Unix
+// timestamp or a duration such as 10m, meaning that long ago.
+func ParseLogQuery(r *http.Request) (LogQuery, error) {
+	query := r.URL.Query()
+	q := LogQuery{
+		Options: runtime.LogOptions{
+			Follow: query.Get("follow") == "true",
+			Tail:   -1,
+			Stdout: query.Get("stdout") != "false",
+			Stderr: query.Get("stderr") != "false",
+		},
+		Timestamps: query.Get("timestamps") == "true",
+	}
+
+	if !q.Options.Stdout && !q.Options.Stderr {
+		return q, fmt.Errorf("at least one of stdout and stderr must be selected")
+	}
+
+	var err error
+	if q.Options.Since, err = ParseTime(query.Get("since")); err != nil {
+		return q, fmt.Errorf("invalid since: %v", err)
+	}
+	if q.Options.Until, err = ParseTime(query.Get("until")); err != nil {
+		return q, fmt.Errorf("invalid until: %v", err)
+	}
+
+	if tail := query.Get("tail"); tail != "" && tail != "all" {
+		n, err := strconv.Atoi(tail)
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("invalid tail: %s", tail)
+		}
+		q.Options.Tail = n
+	}
+
+	return q, nil
+}
+
+// ParseTime parses a time given as an RFC 3339 time, a Unix timestamp or a
+// duration, which is taken to mean that long ago. An empty value is the zero
+// time.
+func ParseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+	return time.Time{}, fmt.Errorf("expected an RFC 3339 time, Unix timestamp or duration: %s", value)
+}
+
+// Entry converts a runtime log entry for a response.
+func (q LogQuery) Entry(entry runtime.LogEntry) LogEntry {
+	e := LogEntry{Stream: entry.Stream, Line: entry.Line}
+	if q.Timestamps && !entry.Timestamp.IsZero() {
+		e.Timestamp = entry.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	return e
+}
@@ -0,0 +1,37 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+	"podium/internal/runtime"
+)
+
+func (h *Handler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var network models.Network
+	if err := json.NewDecoder(r.Body).Decode(&network); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	network.Auto = false
+	if _, err := h.store.GetNetwork(network.Name); err == nil {
+		handlers.RespondWithError(w, http.StatusConflict, fmt.Sprintf("Network %s already exists", network.Name))
+		return
+	}
+
+	if err := h.networks.CreateNetwork(r.Context(), &network); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, runtime.ErrNetworkExists) {
+			status = http.StatusConflict
+		}
+		handlers.RespondWithError(w, status, fmt.Sprintf("Failed to create network: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusCreated, network)
+}
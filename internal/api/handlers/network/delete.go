@@ -0,0 +1,31 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+	"podium/internal/network"
+)
+
+func (h *Handler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if _, err := h.store.GetNetwork(name); err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Network not found: %v", err))
+		return
+	}
+
+	if err := h.networks.DeleteNetwork(r.Context(), name); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, network.ErrNetworkInUse) {
+			status = http.StatusConflict
+		}
+		handlers.RespondWithError(w, status, fmt.Sprintf("Failed to delete network: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,34 @@
+package network
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+	"podium/internal/network"
+)
+
+// networkResponse is a network along with what is attached to it.
+type networkResponse struct {
+	models.Network
+	network.Usage
+}
+
+func (h *Handler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	stored, err := h.store.GetNetwork(name)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Network not found: %v", err))
+		return
+	}
+	usage, err := h.networks.Usage(name)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get network usage: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, networkResponse{Network: stored, Usage: usage})
+}
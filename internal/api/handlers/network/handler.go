@@ -0,0 +1,18 @@
+package network
+
+import (
+	"podium/internal/network"
+	"podium/internal/store"
+)
+
+type Handler struct {
+	store    store.Store
+	networks *network.Manager
+}
+
+func NewHandler(store store.Store, networks *network.Manager) *Handler {
+	return &Handler{
+		store:    store,
+		networks: networks,
+	}
+}
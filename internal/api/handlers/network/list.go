@@ -0,0 +1,25 @@
+package network
+
+import (
+	"fmt"
+	"net/http"
+
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+)
+
+func (h *Handler) HandleList(w http.ResponseWriter, r *http.Request) {
+	networks, err := h.store.ListNetworks()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list networks: %v", err))
+		return
+	}
+	if networks == nil {
+		networks = []models.Network{}
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"items":      networks,
+		"totalCount": len(networks),
+	})
+}
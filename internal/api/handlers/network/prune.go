@@ -0,0 +1,24 @@
+package network
+
+import (
+	"fmt"
+	"net/http"
+
+	"podium/internal/api/handlers"
+)
+
+// HandlePrune removes every network nothing is attached to.
+func (h *Handler) HandlePrune(w http.ResponseWriter, r *http.Request) {
+	removed, err := h.networks.Prune(r.Context(), false)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to prune networks: %v", err))
+		return
+	}
+	if removed == nil {
+		removed = []string{}
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"removed": removed,
+	})
+}
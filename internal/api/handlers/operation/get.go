@@ -0,0 +1,58 @@
+package operation
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+)
+
+// HandleGet returns the current state of an operation. With follow=true the
+// response is a stream of newline-delimited JSON snapshots that ends when the
+// operation finishes.
+func (h *Handler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if r.URL.Query().Get("follow") != "true" {
+		op, ok := h.puller.Get(id)
+		if !ok {
+			handlers.RespondWithError(w, http.StatusNotFound, "Operation not found")
+			return
+		}
+		handlers.RespondWithJSON(w, http.StatusOK, op)
+		return
+	}
+
+	updates, cancel, err := h.puller.Watch(id)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, "Operation not found")
+		return
+	}
+	defer cancel()
+
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case op, ok := <-updates:
+			if !ok {
+				return
+			}
+			if err := encoder.Encode(op); err != nil {
+				log.Printf("Error streaming operation %s: %v", id, err)
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package operation
+
+import (
+	"podium/internal/image"
+)
+
+type Handler struct {
+	puller *image.Puller
+}
+
+func NewHandler(puller *image.Puller) *Handler {
+	return &Handler{
+		puller: puller,
+	}
+}
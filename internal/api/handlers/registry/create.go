@@ -0,0 +1,38 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"podium/internal/api/handlers"
+	"podium/internal/image"
+	"podium/internal/models"
+)
+
+func (h *Handler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var credential models.RegistryCredential
+	if err := json.NewDecoder(r.Body).Decode(&credential); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if credential.Registry == "" || credential.Username == "" || credential.Password == "" {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Registry, username and password are required")
+		return
+	}
+
+	credential.Registry = image.NormalizeRegistry(credential.Registry)
+	credential.CreatedAt = time.Now()
+
+	if err := h.store.SaveRegistryCredential(credential); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to store registry credential: %v", err))
+		return
+	}
+
+	credential.Password = ""
+	handlers.RespondWithJSON(w, http.StatusCreated, credential)
+	log.Printf("Stored credentials for registry %s", credential.Registry)
+}
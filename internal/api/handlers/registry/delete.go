@@ -0,0 +1,32 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+	"podium/internal/image"
+	"podium/internal/store"
+)
+
+func (h *Handler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	registry := image.NormalizeRegistry(vars["registry"])
+
+	if _, err := h.store.GetRegistryCredential(registry); errors.Is(err, store.ErrNotFound) {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Registry credential not found: %v", err))
+		return
+	} else if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to look up registry credential: %v", err))
+		return
+	}
+
+	if err := h.store.DeleteRegistryCredential(registry); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to delete registry credential: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusNoContent, nil)
+}
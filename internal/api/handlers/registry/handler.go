@@ -0,0 +1,15 @@
+package registry
+
+import (
+	"podium/internal/store"
+)
+
+type Handler struct {
+	store store.Store
+}
+
+func NewHandler(store store.Store) *Handler {
+	return &Handler{
+		store: store,
+	}
+}
@@ -0,0 +1,28 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+)
+
+func (h *Handler) HandleList(w http.ResponseWriter, r *http.Request) {
+	credentials, err := h.store.ListRegistryCredentials()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list registry credentials: %v", err))
+		return
+	}
+
+	items := make([]models.RegistryCredential, 0, len(credentials))
+	for _, credential := range credentials {
+		credential.Password = ""
+		items = append(items, credential)
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"items":      items,
+		"totalCount": len(items),
+	})
+}
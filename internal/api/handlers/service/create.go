@@ -7,16 +7,13 @@ import (
 	"time"
 
 	"podium/internal/models"
-	"podium/internal/service"
-	"podium/internal/store"
+	"podium/internal/network"
+	"podium/internal/proxy"
+	podiumservice "podium/internal/service"
+	"podium/internal/volume"
 )
 
-type HandleCreate struct {
-	Store          *store.BoltStore
-	ServiceManager service.Manager
-}
-
-func (h *HandleCreate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) HandleCreate(w http.ResponseWriter, r *http.Request) {
 	var service models.Service
 	if err := json.NewDecoder(r.Body).Decode(&service); err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
@@ -31,16 +28,38 @@ func (h *HandleCreate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, "Service image is required")
 		return
 	}
+	if err := podiumservice.ValidateUpdateConfig(&service); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid update config: "+err.Error())
+		return
+	}
+	if err := proxy.ValidateLoadBalancer(service.LoadBalancer); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid load balancer: "+err.Error())
+		return
+	}
+	if err := network.ValidateAttachments(service.Networks); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid networks: "+err.Error())
+		return
+	}
+	if err := volume.ValidateMounts(service.Mounts); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid mounts: "+err.Error())
+		return
+	}
+	if err := proxy.ValidateIngress(service.Ingress, service.Ports); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid ingress rule: "+err.Error())
+		return
+	}
 
 	service.ID = generateID()
+	service.UpdateStatus = nil
+	service.Standby = nil
 
-	if err := h.Store.CreateService(service); err != nil {
+	if err := h.store.CreateService(service); err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to create service")
 		return
 	}
 
-	if err := h.ServiceManager.CreateService(r.Context(), &service); err != nil {
-		h.Store.DeleteService(service.ID)
+	if err := h.serviceManager.CreateService(r.Context(), &service); err != nil {
+		h.store.DeleteService(service.ID)
 		respondWithError(w, http.StatusInternalServerError, "Failed to create service containers: "+err.Error())
 		return
 	}
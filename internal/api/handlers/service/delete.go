@@ -2,7 +2,6 @@ package service
 
 import (
 	"fmt"
-	"log"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -17,27 +16,14 @@ func (h *Handler) HandleDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service, err := h.store.GetService(id)
-	if err != nil {
+	if _, err := h.store.GetService(id); err != nil {
 		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Service not found: %v", err))
 		return
 	}
 
-	for _, containerID := range service.ContainerIDs {
-		if err := h.runtime.StopContainer(r.Context(), containerID); err != nil {
-			log.Printf("Warning: Failed to stop container %s: %v", containerID, err)
-		}
-
-		if err := h.runtime.DeleteContainer(r.Context(), containerID); err != nil {
-			log.Printf("Warning: Failed to delete container %s: %v", containerID, err)
-		}
-
-		if err := h.store.DeleteContainer(containerID); err != nil {
-			log.Printf("Warning: Failed to delete container from database: %v", err)
-		}
-	}
-
-	if err := h.store.DeleteService(id); err != nil {
+	// The manager cancels any update in progress and removes every replica,
+	// standby ones included, before the service itself.
+	if err := h.serviceManager.DeleteService(r.Context(), id); err != nil {
 		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to delete service: %v", err))
 		return
 	}
@@ -8,12 +8,12 @@ import (
 )
 
 type Handler struct {
-	store          *store.BoltStore
+	store          store.Store
 	runtime        runtime.Runtime
 	serviceManager service.Manager
 }
 
-func NewHandler(store *store.BoltStore, runtime runtime.Runtime, serviceManager service.Manager) *Handler {
+func NewHandler(store store.Store, runtime runtime.Runtime, serviceManager service.Manager) *Handler {
 	return &Handler{
 		store:          store,
 		runtime:        runtime,
@@ -21,7 +21,7 @@ func NewHandler(store *store.BoltStore, runtime runtime.Runtime, serviceManager
 	}
 }
 
-func RegisterRoutes(router *mux.Router, store *store.BoltStore, runtime runtime.Runtime, serviceManager service.Manager) {
+func RegisterRoutes(router *mux.Router, store store.Store, runtime runtime.Runtime, serviceManager service.Manager) {
 	h := NewHandler(store, runtime, serviceManager)
 	
 	router.HandleFunc("/api/services", h.HandleList).Methods("GET")
@@ -31,4 +31,13 @@ func RegisterRoutes(router *mux.Router, store *store.BoltStore, runtime runtime.
 	router.HandleFunc("/api/services/{id}", h.HandleDelete).Methods("DELETE")
 	router.HandleFunc("/api/services/{id}/scale", h.HandlerScale).Methods("POST")
 	router.HandleFunc("/api/services/{id}/status", h.HandleStatus).Methods("GET")
+	router.HandleFunc("/api/services/{id}/logs", h.HandleLogs).Methods("GET")
+	router.HandleFunc("/api/services/{id}/update/pause", h.HandlePauseUpdate).Methods("POST")
+	router.HandleFunc("/api/services/{id}/update/resume", h.HandleResumeUpdate).Methods("POST")
+	router.HandleFunc("/api/services/{id}/update/abort", h.HandleAbortUpdate).Methods("POST")
+	router.HandleFunc("/api/services/{id}/canary/promote", h.HandlePromoteCanary).Methods("POST")
+	router.HandleFunc("/api/services/{id}/canary/abort", h.HandleAbortUpdate).Methods("POST")
+	router.HandleFunc("/api/services/{id}/revisions", h.HandleRevisions).Methods("GET")
+	router.HandleFunc("/api/services/{id}/revisions/{revision}", h.HandleGetRevision).Methods("GET")
+	router.HandleFunc("/api/services/{id}/rollback", h.HandleRollback).Methods("POST")
 }
\ No newline at end of file
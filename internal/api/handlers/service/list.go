@@ -4,85 +4,51 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
 
 	"podium/internal/api/handlers"
 	"podium/internal/models"
 )
 
+// HandleList returns a page of services. Query parameters: state, name,
+// sort (id, name, state, createdAt or updatedAt), order (asc or desc), limit
+// (default 100) and offset. Filtering, sorting and paging are left to the
+// store.
 func (h *Handler) HandleList(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request to list services")
-	
+
 	query := r.URL.Query()
-	stateFilter := query.Get("state")
-	
-	limitStr := query.Get("limit")
-	offsetStr := query.Get("offset")
-	
-	var limit, offset int
-	var err error
-	
-	if limitStr != "" {
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil || limit < 1 {
-			limit = 100
-		}
-	} else {
-		limit = 100
+	options, err := handlers.ParseListOptions(r, models.ServiceSortFields)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	
-	if offsetStr != "" {
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			offset = 0
-		}
+	filter := models.ServiceFilter{
+		State: models.ServiceState(query.Get("state")),
+		Name:  query.Get("name"),
+	}
+
+	services, totalCount, err := h.store.QueryServices(filter, options)
+	// An offset past the end starts over from the first page.
+	if err == nil && len(services) == 0 && options.Offset > 0 && options.Offset >= totalCount {
+		options.Offset = 0
+		services, totalCount, err = h.store.QueryServices(filter, options)
 	}
-	
-	services, err := h.store.ListServices()
 	if err != nil {
 		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list services: %v", err))
 		log.Printf("Error listing services from database: %v", err)
 		return
 	}
-	
-	var filteredServices []models.Service
-	
-	if stateFilter != "" {
-		log.Printf("Filtering services by state: %s", stateFilter)
-		for _, service := range services {
-			if string(service.State) == stateFilter {
-				filteredServices = append(filteredServices, service)
-			}
-		}
-	} else {
-		filteredServices = services
+	if services == nil {
+		services = []models.Service{}
 	}
-	
-	totalCount := len(filteredServices)
-	
-	if offset >= totalCount {
-		offset = 0
-	}
-	
-	endIndex := offset + limit
-	if endIndex > totalCount {
-		endIndex = totalCount
-	}
-	
-	var paginatedServices []models.Service
-	if offset < totalCount {
-		paginatedServices = filteredServices[offset:endIndex]
-	} else {
-		paginatedServices = []models.Service{}
-	}
-	
+
 	response := map[string]interface{}{
-		"items":      paginatedServices,
+		"items":      services,
 		"totalCount": totalCount,
-		"limit":      limit,
-		"offset":     offset,
+		"limit":      options.Limit,
+		"offset":     options.Offset,
 	}
-	
+
 	handlers.RespondWithJSON(w, http.StatusOK, response)
-	log.Printf("Listed %d services (filtered from %d total)", len(paginatedServices), totalCount)
-}
\ No newline at end of file
+	log.Printf("Listed %d services (filtered from %d total)", len(services), totalCount)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+	"podium/internal/service"
+)
+
+// HandleLogs returns the logs of every replica of a service merged in
+// timestamp order, each line prefixed with its replica index. It accepts the
+// same query parameters as the container logs endpoint.
+func (h *Handler) HandleLogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serviceID := vars["id"]
+
+	if _, err := h.store.GetService(serviceID); err != nil {
+		respondWithError(w, http.StatusNotFound, "Service not found")
+		return
+	}
+
+	query, err := handlers.ParseLogQuery(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entry := func(e service.ReplicaLogEntry) handlers.LogEntry {
+		logEntry := query.Entry(e.LogEntry)
+		replica := e.Replica
+		logEntry.Replica = &replica
+		logEntry.Line = fmt.Sprintf("[%d] %s", replica, logEntry.Line)
+		return logEntry
+	}
+
+	if !query.Options.Follow {
+		entries := []handlers.LogEntry{}
+		err := h.serviceManager.ServiceLogs(r.Context(), serviceID, query.Options, func(e service.ReplicaLogEntry) error {
+			entries = append(entries, entry(e))
+			return nil
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to get service logs: "+err.Error())
+			return
+		}
+		respondWithJSON(w, http.StatusOK, map[string]interface{}{"logs": entries})
+		return
+	}
+
+	stream := handlers.NewStream(w, r)
+	err = h.serviceManager.ServiceLogs(r.Context(), serviceID, query.Options, func(e service.ReplicaLogEntry) error {
+		logEntry := entry(e)
+		return stream.Send(logEntry.Stream, logEntry)
+	})
+	if err != nil && r.Context().Err() == nil {
+		log.Printf("Error streaming logs for service %s: %v", serviceID, err)
+		stream.SendError(err)
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"podium/internal/models"
+)
+
+type RollbackRequest struct {
+	// Revision is the revision to redeploy. When omitted the service goes
+	// back to the revision it ran before its latest update.
+	Revision int `json:"revision"`
+}
+
+// HandleRevisions lists the revisions of a service, oldest first.
+func (h *Handler) HandleRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serviceID := vars["id"]
+
+	service, err := h.store.GetService(serviceID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Service not found")
+		return
+	}
+
+	revisions, err := h.store.ListServiceRevisions(serviceID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list revisions: "+err.Error())
+		return
+	}
+	if revisions == nil {
+		revisions = []models.ServiceRevision{}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"items":           revisions,
+		"totalCount":      len(revisions),
+		"currentRevision": service.Revision,
+	})
+}
+
+func (h *Handler) HandleGetRevision(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serviceID := vars["id"]
+
+	revision, err := strconv.Atoi(vars["revision"])
+	if err != nil || revision < 1 {
+		respondWithError(w, http.StatusBadRequest, "Invalid revision: "+vars["revision"])
+		return
+	}
+
+	result, err := h.store.GetServiceRevision(serviceID, revision)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// HandleRollback redeploys an earlier revision of a service with a rolling
+// update.
+func (h *Handler) HandleRollback(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serviceID := vars["id"]
+
+	var req RollbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if req.Revision < 0 {
+		respondWithError(w, http.StatusBadRequest, "Revision must be positive")
+		return
+	}
+
+	if _, err := h.store.GetService(serviceID); err != nil {
+		respondWithError(w, http.StatusNotFound, "Service not found")
+		return
+	}
+	if req.Revision > 0 {
+		if _, err := h.store.GetServiceRevision(serviceID, req.Revision); err != nil {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+	}
+
+	if err := h.serviceManager.RollbackService(r.Context(), serviceID, req.Revision); err != nil {
+		respondWithError(w, http.StatusConflict, "Failed to roll back service: "+err.Error())
+		return
+	}
+
+	service, err := h.store.GetService(serviceID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get service")
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, service)
+}
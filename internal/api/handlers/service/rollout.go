@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// HandlePauseUpdate pauses a rolling update after its current batch.
+func (h *Handler) HandlePauseUpdate(w http.ResponseWriter, r *http.Request) {
+	h.controlUpdate(w, r, "pause", h.serviceManager.PauseUpdate)
+}
+
+// HandleResumeUpdate continues a paused rolling update.
+func (h *Handler) HandleResumeUpdate(w http.ResponseWriter, r *http.Request) {
+	h.controlUpdate(w, r, "resume", h.serviceManager.ResumeUpdate)
+}
+
+// HandleAbortUpdate stops a rolling update and rolls the service back to its
+// previous spec.
+func (h *Handler) HandleAbortUpdate(w http.ResponseWriter, r *http.Request) {
+	h.controlUpdate(w, r, "abort", h.serviceManager.AbortUpdate)
+}
+
+// HandlePromoteCanary rolls a canary's spec out to every replica.
+func (h *Handler) HandlePromoteCanary(w http.ResponseWriter, r *http.Request) {
+	h.controlUpdate(w, r, "promote", h.serviceManager.PromoteCanary)
+}
+
+func (h *Handler) controlUpdate(w http.ResponseWriter, r *http.Request, action string, fn func(context.Context, string) error) {
+	vars := mux.Vars(r)
+	serviceID := vars["id"]
+
+	if _, err := h.store.GetService(serviceID); err != nil {
+		respondWithError(w, http.StatusNotFound, "Service not found")
+		return
+	}
+
+	if err := fn(r.Context(), serviceID); err != nil {
+		respondWithError(w, http.StatusConflict, "Failed to "+action+" update: "+err.Error())
+		return
+	}
+
+	service, err := h.store.GetService(serviceID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get service")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, service)
+}
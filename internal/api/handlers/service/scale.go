@@ -5,20 +5,13 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
-	"podium/internal/service"
-	"podium/internal/store"
 )
 
 type ScaleRequest struct {
 	Replicas int `json:"replicas"`
 }
 
-type HandlerScale struct {
-	Store          *store.BoltStore
-	ServiceManager service.Manager
-}
-
-func (h *HandlerScale) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) HandlerScale(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	serviceID := vars["id"]
 
@@ -33,20 +26,20 @@ func (h *HandlerScale) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service, err := h.Store.GetService(serviceID)
-	if err != nil {
+	if _, err := h.store.GetService(serviceID); err != nil {
 		respondWithError(w, http.StatusNotFound, "Service not found")
 		return
 	}
 
-	if err := h.ServiceManager.ScaleService(r.Context(), serviceID, req.Replicas); err != nil {
+	if err := h.serviceManager.ScaleService(r.Context(), serviceID, req.Replicas); err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to scale service: "+err.Error())
 		return
 	}
 
-	service.Replicas = req.Replicas
-	if err := h.Store.UpdateService(service); err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to update service")
+	// The service manager persists the new replica count and container IDs.
+	service, err := h.store.GetService(serviceID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get service")
 		return
 	}
 
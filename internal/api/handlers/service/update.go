@@ -9,6 +9,10 @@ import (
 	"github.com/gorilla/mux"
 	"podium/internal/api/handlers"
 	"podium/internal/models"
+	"podium/internal/network"
+	"podium/internal/proxy"
+	podiumservice "podium/internal/service"
+	"podium/internal/volume"
 )
 
 func (h *Handler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
@@ -38,12 +42,48 @@ func (h *Handler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
 	service.Ports = req.Ports
 	service.Resources = req.Resources
 	service.RestartPolicy = req.RestartPolicy
+	service.HealthCheck = req.HealthCheck
+	if err := network.ValidateAttachments(req.Networks); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid networks: %v", err))
+		return
+	}
+	service.Networks = req.Networks
+	if err := volume.ValidateMounts(req.Mounts); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid mounts: %v", err))
+		return
+	}
+	service.Mounts = req.Mounts
+	if err := proxy.ValidateLoadBalancer(req.LoadBalancer); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid load balancer: %v", err))
+		return
+	}
+	service.LoadBalancer = req.LoadBalancer
+	if err := proxy.ValidateIngress(req.Ingress, req.Ports); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid ingress rule: %v", err))
+		return
+	}
+	service.Ingress = req.Ingress
+	if req.UpdateConfig != nil {
+		service.UpdateConfig = req.UpdateConfig
+	}
+	// The update config is checked against the new load balancer and
+	// ingress rules even when it is kept.
+	if err := podiumservice.ValidateUpdateConfig(&service); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid update config: %v", err))
+		return
+	}
 	service.UpdatedAt = time.Now()
 
-	if err := h.store.UpdateService(service); err != nil {
+	// The service manager stores the new spec and rolls it out to the
+	// replicas in the background.
+	if err := h.serviceManager.UpdateService(r.Context(), &service); err != nil {
 		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to update service: %v", err))
 		return
 	}
 
-	handlers.RespondWithJSON(w, http.StatusOK, service)
+	status := http.StatusOK
+	if service.UpdateStatus.InProgress() {
+		status = http.StatusAccepted
+	}
+	handlers.RespondWithJSON(w, status, service)
 }
\ No newline at end of file
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Stream writes JSON values to a response as they are produced: as
+// Server-Sent Events when the client accepts text/event-stream, and as
+// newline-delimited JSON otherwise. It is safe for concurrent use.
+type Stream struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+	sse     bool
+}
+
+// NewStream writes the response headers and returns a Stream.
+func NewStream(w http.ResponseWriter, r *http.Request) *Stream {
+	return newStream(w, strings.Contains(r.Header.Get("Accept"), "text/event-stream"))
+}
+
+// NewEventStream is like NewStream but always sends Server-Sent Events.
+func NewEventStream(w http.ResponseWriter) *Stream {
+	return newStream(w, true)
+}
+
+func newStream(w http.ResponseWriter, sse bool) *Stream {
+	s := &Stream{w: w, sse: sse}
+	s.flusher, _ = w.(http.Flusher)
+
+	if s.sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.WriteHeader(http.StatusOK)
+	s.flush()
+	return s
+}
+
+// Send writes one value. event names the Server-Sent Event and is not used
+// for newline-delimited JSON.
+func (s *Stream) Send(event string, v interface{}) error {
+	return s.SendWithID("", event, v)
+}
+
+// SendWithID is like Send but also sets the Server-Sent Event ID, which
+// clients send back in Last-Event-ID when they reconnect.
+func (s *Stream) SendWithID(id, event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sse {
+		if id != "" {
+			fmt.Fprintf(s.w, "id: %s\n", id)
+		}
+		_, err = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data)
+	} else {
+		_, err = s.w.Write(append(data, '\n'))
+	}
+	if err != nil {
+		return err
+	}
+	s.flush()
+	return nil
+}
+
+// SendError reports an error that ended the stream early.
+func (s *Stream) SendError(err error) {
+	s.Send("error", ErrorResponse{Error: err.Error()})
+}
+
+func (s *Stream) flush() {
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
@@ -0,0 +1,90 @@
+package volume
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+	"podium/internal/volume"
+)
+
+// writeTracker records whether anything has been written, after which an
+// error can no longer be sent as a response.
+type writeTracker struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (t *writeTracker) Write(p []byte) (int, error) {
+	t.written = true
+	return t.ResponseWriter.Write(p)
+}
+
+// HandleBackup streams a tar archive of a volume.
+func (h *Handler) HandleBackup(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	quiesce := r.URL.Query().Get("quiesce")
+
+	if _, err := h.store.GetVolume(name); err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Volume not found: %v", err))
+		return
+	}
+	if err := volume.ValidateQuiesce(quiesce); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".tar"))
+	tracker := &writeTracker{ResponseWriter: w}
+	if err := h.volumes.Backup(r.Context(), name, quiesce, tracker); err != nil {
+		log.Printf("Backup of volume %s failed: %v", name, err)
+		if !tracker.written {
+			w.Header().Del("Content-Disposition")
+			handlers.RespondWithError(w, errorStatus(err), fmt.Sprintf("Failed to back up volume: %v", err))
+		}
+	}
+}
+
+// HandleRestore replaces the data in a volume with the tar archive in the
+// request body, or with one of its backups if the backup parameter names
+// one.
+func (h *Handler) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	quiesce := r.URL.Query().Get("quiesce")
+
+	if _, err := h.store.GetVolume(name); err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Volume not found: %v", err))
+		return
+	}
+
+	var err error
+	if backup := r.URL.Query().Get("backup"); backup != "" {
+		err = h.volumes.RestoreBackup(r.Context(), name, backup, quiesce)
+	} else {
+		err = h.volumes.Restore(r.Context(), name, quiesce, r.Body)
+	}
+	if err != nil {
+		handlers.RespondWithError(w, errorStatus(err), fmt.Sprintf("Failed to restore volume: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, map[string]string{"volume": name, "status": "restored"})
+}
+
+// errorStatus returns the status code for an error from a backup or
+// restore.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, volume.ErrVolumeBusy), errors.Is(err, volume.ErrVolumeInUse):
+		return http.StatusConflict
+	case errors.Is(err, volume.ErrBackupNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, volume.ErrInvalidArchive):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
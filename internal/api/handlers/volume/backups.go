@@ -0,0 +1,75 @@
+package volume
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+	"podium/internal/volume"
+)
+
+// HandleListBackups lists the backups of a volume in the backup directory.
+func (h *Handler) HandleListBackups(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	backups, err := h.volumes.ListBackups(name)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list backups: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"items":      backups,
+		"totalCount": len(backups),
+	})
+}
+
+// HandleCreateBackup backs a volume up to the backup directory.
+func (h *Handler) HandleCreateBackup(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	quiesce := r.URL.Query().Get("quiesce")
+
+	if _, err := h.store.GetVolume(name); err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Volume not found: %v", err))
+		return
+	}
+	if err := volume.ValidateQuiesce(quiesce); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	backup, err := h.volumes.CreateBackup(r.Context(), name, quiesce)
+	if err != nil {
+		handlers.RespondWithError(w, errorStatus(err), fmt.Sprintf("Failed to back up volume: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusCreated, backup)
+}
+
+// HandleSetBackupSchedule sets the backup schedule of a volume. A null body
+// removes it.
+func (h *Handler) HandleSetBackupSchedule(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	var schedule *models.VolumeBackupSchedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if _, err := h.store.GetVolume(name); err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Volume not found: %v", err))
+		return
+	}
+
+	updated, err := h.volumes.SetBackupSchedule(name, schedule)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid backup schedule: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, updated)
+}
@@ -0,0 +1,32 @@
+package volume
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+)
+
+func (h *Handler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var volume models.Volume
+	if err := json.NewDecoder(r.Body).Decode(&volume); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	volume.Auto = false
+	volume.Mountpoint = ""
+	if _, err := h.store.GetVolume(volume.Name); err == nil {
+		handlers.RespondWithError(w, http.StatusConflict, fmt.Sprintf("Volume %s already exists", volume.Name))
+		return
+	}
+
+	if err := h.volumes.CreateVolume(r.Context(), &volume); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Failed to create volume: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusCreated, volume)
+}
@@ -0,0 +1,31 @@
+package volume
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+	"podium/internal/volume"
+)
+
+func (h *Handler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if _, err := h.store.GetVolume(name); err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Volume not found: %v", err))
+		return
+	}
+
+	if err := h.volumes.DeleteVolume(r.Context(), name); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, volume.ErrVolumeInUse) {
+			status = http.StatusConflict
+		}
+		handlers.RespondWithError(w, status, fmt.Sprintf("Failed to delete volume: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,34 @@
+package volume
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+	"podium/internal/volume"
+)
+
+// volumeResponse is a volume along with what mounts it.
+type volumeResponse struct {
+	models.Volume
+	volume.Usage
+}
+
+func (h *Handler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	stored, err := h.store.GetVolume(name)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Volume not found: %v", err))
+		return
+	}
+	usage, err := h.volumes.Usage(name)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get volume usage: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, volumeResponse{Volume: stored, Usage: usage})
+}
@@ -0,0 +1,18 @@
+package volume
+
+import (
+	"podium/internal/store"
+	"podium/internal/volume"
+)
+
+type Handler struct {
+	store   store.Store
+	volumes *volume.Manager
+}
+
+func NewHandler(store store.Store, volumes *volume.Manager) *Handler {
+	return &Handler{
+		store:   store,
+		volumes: volumes,
+	}
+}
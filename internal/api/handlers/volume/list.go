@@ -0,0 +1,25 @@
+package volume
+
+import (
+	"fmt"
+	"net/http"
+
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+)
+
+func (h *Handler) HandleList(w http.ResponseWriter, r *http.Request) {
+	volumes, err := h.store.ListVolumes()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list volumes: %v", err))
+		return
+	}
+	if volumes == nil {
+		volumes = []models.Volume{}
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"items":      volumes,
+		"totalCount": len(volumes),
+	})
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+)
+
+// HandleCreate registers a webhook. When no secret is given one is
+// generated; the secret is only ever returned in this response.
+func (h *Handler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.URL == nil || *req.URL == "" {
+		handlers.RespondWithError(w, http.StatusBadRequest, "URL is required")
+		return
+	}
+
+	now := time.Now()
+	webhook := models.Webhook{
+		ID:        uuid.New().String(),
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := req.apply(&webhook); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if webhook.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to generate secret: %v", err))
+			return
+		}
+		webhook.Secret = secret
+	}
+
+	if err := h.store.SaveWebhook(webhook); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to store webhook: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusCreated, webhook)
+	log.Printf("Registered webhook %s for %s", webhook.ID, webhook.URL)
+}
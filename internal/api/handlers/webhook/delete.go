@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+)
+
+func (h *Handler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if _, err := h.store.GetWebhook(id); err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Webhook not found: %v", err))
+		return
+	}
+
+	if err := h.store.DeleteWebhook(id); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to delete webhook: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusNoContent, nil)
+	log.Printf("Deleted webhook %s", id)
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+)
+
+// HandleDeliveries returns the webhook's recent deliveries, most recent
+// first.
+func (h *Handler) HandleDeliveries(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if _, err := h.store.GetWebhook(id); err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Webhook not found: %v", err))
+		return
+	}
+
+	deliveries, err := h.store.ListWebhookDeliveries(id)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list deliveries: %v", err))
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"items":      deliveries,
+		"totalCount": len(deliveries),
+	})
+}
+
+// HandleTest sends a webhook.test event to the webhook straight away, even
+// if it is disabled, and returns the outcome of that single attempt.
+func (h *Handler) HandleTest(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	webhook, err := h.store.GetWebhook(id)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Webhook not found: %v", err))
+		return
+	}
+
+	delivery, err := h.dispatcher.Test(r.Context(), webhook)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to test webhook: %v", err))
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, delivery)
+}
@@ -0,0 +1,23 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+)
+
+func (h *Handler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	webhook, err := h.store.GetWebhook(id)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Failed to get webhook: %v", err))
+		return
+	}
+
+	webhook.Secret = ""
+	handlers.RespondWithJSON(w, http.StatusOK, webhook)
+}
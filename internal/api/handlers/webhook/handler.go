@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+
+	"podium/internal/models"
+	"podium/internal/store"
+	"podium/internal/webhook"
+)
+
+type Handler struct {
+	store      store.Store
+	dispatcher *webhook.Dispatcher
+}
+
+func NewHandler(store store.Store, dispatcher *webhook.Dispatcher) *Handler {
+	return &Handler{
+		store:      store,
+		dispatcher: dispatcher,
+	}
+}
+
+// webhookRequest holds the fields a client may set on a webhook. Fields left
+// out of an update keep their current value.
+type webhookRequest struct {
+	URL         *string             `json:"url"`
+	Secret      *string             `json:"secret"`
+	Events      *[]models.EventType `json:"events"`
+	Enabled     *bool               `json:"enabled"`
+	Description *string             `json:"description"`
+}
+
+// apply validates req and copies the fields it sets onto webhook.
+func (req webhookRequest) apply(webhook *models.Webhook) error {
+	if req.URL != nil {
+		u, err := url.Parse(*req.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("url must be an absolute http or https URL")
+		}
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		for _, t := range *req.Events {
+			if !knownEventType(t) {
+				return fmt.Errorf("unknown event type: %s", t)
+			}
+		}
+		webhook.Events = *req.Events
+	}
+	if req.Secret != nil {
+		webhook.Secret = *req.Secret
+	}
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+	}
+	if req.Description != nil {
+		webhook.Description = *req.Description
+	}
+	return nil
+}
+
+func knownEventType(t models.EventType) bool {
+	for _, known := range models.EventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+
+	"podium/internal/api/handlers"
+	"podium/internal/models"
+)
+
+func (h *Handler) HandleList(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.store.ListWebhooks()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list webhooks: %v", err))
+		return
+	}
+
+	items := make([]models.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+		items = append(items, webhook)
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"items":      items,
+		"totalCount": len(items),
+	})
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"podium/internal/api/handlers"
+)
+
+// HandleUpdate changes the fields given in the request body, leaving the
+// others as they are.
+func (h *Handler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	webhook, err := h.store.GetWebhook(id)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Webhook not found: %v", err))
+		return
+	}
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+
+	if err := req.apply(&webhook); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if webhook.Secret == "" {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Secret cannot be empty")
+		return
+	}
+	webhook.UpdatedAt = time.Now()
+
+	if err := h.store.SaveWebhook(webhook); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to update webhook: %v", err))
+		return
+	}
+
+	webhook.Secret = ""
+	handlers.RespondWithJSON(w, http.StatusOK, webhook)
+}
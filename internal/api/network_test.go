@@ -0,0 +1,73 @@
+package api_test
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+
+	"podium/internal/models"
+)
+
+// networkDetails is a network as GET /api/networks/{name} returns it.
+type networkDetails struct {
+	models.Network
+	Containers []string `json:"containers"`
+	Services   []string `json:"services"`
+}
+
+func TestNetworkLifecycle(t *testing.T) {
+	server := newTestServer(t)
+	server.do(t, "POST", "/api/networks", `{"name": "backend"}`, http.StatusCreated, nil)
+	server.do(t, "POST", "/api/networks", `{"name": "backend"}`, http.StatusConflict, nil)
+	server.do(t, "POST", "/api/networks", `{"name": "bridge"}`, http.StatusBadRequest, nil)
+
+	svc := server.createService(t, `{"name": "web", "image": "nginx:latest", "replicas": 2, "networks": [{"network": "backend", "aliases": ["www"]}]}`)
+	var replicaIDs []string
+	for index, replica := range server.replicas(t, svc.ID) {
+		replicaIDs = append(replicaIDs, replica.ID)
+		attachments := replica.Networks
+		if len(attachments) != 1 || attachments[0].Network != "backend" ||
+			len(attachments[0].Aliases) != 2 || attachments[0].Aliases[0] != "web" || attachments[0].Aliases[1] != "www" {
+			t.Errorf("replica %s is attached as %+v, want to backend as web and www", index, attachments)
+		}
+	}
+	sort.Strings(replicaIDs)
+
+	var details networkDetails
+	server.do(t, "GET", "/api/networks/backend", "", http.StatusOK, &details)
+	if details.Auto || len(details.Services) != 1 || details.Services[0] != svc.ID || len(details.Containers) != 2 ||
+		details.Containers[0] != replicaIDs[0] || details.Containers[1] != replicaIDs[1] {
+		t.Errorf("got network %+v, want a manual network used by service %s and replicas %v", details, svc.ID, replicaIDs)
+	}
+
+	server.do(t, "DELETE", "/api/networks/backend", "", http.StatusConflict, nil)
+	server.do(t, "DELETE", "/api/services/"+svc.ID, "", http.StatusOK, nil)
+	server.do(t, "DELETE", "/api/networks/backend", "", http.StatusNoContent, nil)
+	server.do(t, "GET", "/api/networks/backend", "", http.StatusNotFound, nil)
+}
+
+func TestUnknownNetworkIsCreatedAndPruned(t *testing.T) {
+	server := newTestServer(t)
+	server.do(t, "POST", "/api/networks", `{"name": "backend"}`, http.StatusCreated, nil)
+	svc := server.createService(t, `{"name": "web", "image": "nginx:latest", "networks": [{"network": "cache"}]}`)
+
+	var details networkDetails
+	server.do(t, "GET", "/api/networks/cache", "", http.StatusOK, &details)
+	if !details.Auto {
+		t.Errorf("network cache was not marked as created automatically")
+	}
+
+	var pruned struct {
+		Removed []string `json:"removed"`
+	}
+	server.do(t, "POST", "/api/networks/prune", "", http.StatusOK, &pruned)
+	if len(pruned.Removed) != 1 || pruned.Removed[0] != "backend" {
+		t.Errorf("pruned %v, want only the unused network backend", pruned.Removed)
+	}
+
+	server.do(t, "DELETE", "/api/services/"+svc.ID, "", http.StatusOK, nil)
+	server.do(t, "POST", "/api/networks/prune", "", http.StatusOK, &pruned)
+	if len(pruned.Removed) != 1 || pruned.Removed[0] != "cache" {
+		t.Errorf("pruned %v, want cache once its service is gone", pruned.Removed)
+	}
+}
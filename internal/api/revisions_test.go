@@ -0,0 +1,73 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"podium/internal/models"
+)
+
+type revisionList struct {
+	Items           []models.ServiceRevision `json:"items"`
+	TotalCount      int                      `json:"totalCount"`
+	CurrentRevision int                      `json:"currentRevision"`
+}
+
+func TestServiceRevisions(t *testing.T) {
+	server := newTestServer(t)
+	svc := server.createService(t, fmt.Sprintf(rollingSpec, "web:1", time.Hour))
+	server.do(t, "PUT", "/api/services/"+svc.ID, fmt.Sprintf(rollingSpec, "web:2", time.Hour), http.StatusAccepted, nil)
+	server.waitForUpdate(t, svc.ID, models.UpdateStateCompleted)
+
+	var list revisionList
+	server.do(t, "GET", "/api/services/"+svc.ID+"/revisions", "", http.StatusOK, &list)
+	if list.TotalCount != 2 || len(list.Items) != 2 || list.CurrentRevision != 2 {
+		t.Fatalf("got %d of %d revisions with current %d, want 2 of 2 with current 2", len(list.Items), list.TotalCount, list.CurrentRevision)
+	}
+	for i, image := range []string{"web:1", "web:2"} {
+		if revision := list.Items[i]; revision.Revision != i+1 || revision.Spec.Image != image || revision.SpecHash != revision.Spec.Hash() {
+			t.Errorf("revision %d is %d with image %s and hash %s, want image %s", i+1, revision.Revision, revision.Spec.Image, revision.SpecHash, image)
+		}
+	}
+
+	var revision models.ServiceRevision
+	server.do(t, "GET", "/api/services/"+svc.ID+"/revisions/1", "", http.StatusOK, &revision)
+	if revision.Revision != 1 || revision.Spec.Image != "web:1" {
+		t.Errorf("got revision %d with image %s, want revision 1 with web:1", revision.Revision, revision.Spec.Image)
+	}
+	server.do(t, "GET", "/api/services/"+svc.ID+"/revisions/3", "", http.StatusNotFound, nil)
+	server.do(t, "GET", "/api/services/"+svc.ID+"/revisions/first", "", http.StatusBadRequest, nil)
+}
+
+func TestRollbackRestoresPreviousRevision(t *testing.T) {
+	server := newTestServer(t)
+	svc := server.createService(t, fmt.Sprintf(rollingSpec, "web:1", time.Hour))
+	server.do(t, "PUT", "/api/services/"+svc.ID, fmt.Sprintf(rollingSpec, "web:2", time.Hour), http.StatusAccepted, nil)
+	server.waitForUpdate(t, svc.ID, models.UpdateStateCompleted)
+
+	server.do(t, "POST", "/api/services/"+svc.ID+"/rollback", "", http.StatusAccepted, nil)
+	restored := server.waitForUpdate(t, svc.ID, models.UpdateStateCompleted)
+
+	if restored.Revision != 1 || restored.Image != "web:1" {
+		t.Errorf("got revision %d with image %s, want revision 1 with web:1", restored.Revision, restored.Image)
+	}
+	hash := restored.Spec().Hash()
+	for index, replica := range server.replicas(t, svc.ID) {
+		if replica.Image != "web:1" || replica.Labels["podium.service.spec"] != hash {
+			t.Errorf("replica %s runs %s with spec %s, want web:1 with spec %s", index, replica.Image, replica.Labels["podium.service.spec"], hash)
+		}
+	}
+
+	// A rollback redeploys an existing revision rather than recording a new
+	// one.
+	var list revisionList
+	server.do(t, "GET", "/api/services/"+svc.ID+"/revisions", "", http.StatusOK, &list)
+	if list.TotalCount != 2 || list.CurrentRevision != 1 {
+		t.Errorf("got %d revisions with current %d, want 2 with current 1", list.TotalCount, list.CurrentRevision)
+	}
+
+	server.do(t, "POST", "/api/services/"+svc.ID+"/rollback", `{"revision": 1}`, http.StatusConflict, nil)
+	server.do(t, "POST", "/api/services/"+svc.ID+"/rollback", `{"revision": 5}`, http.StatusNotFound, nil)
+}
@@ -0,0 +1,151 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"podium/internal/models"
+	"podium/internal/runtime"
+)
+
+// rollingSpec is a service updated one replica at a time. Its replicas pass
+// their health check unless their image is given a failing ExecExitCode.
+const rollingSpec = `{
+	"name": "web",
+	"image": "%s",
+	"replicas": 2,
+	"healthCheck": {"type": "command", "command": ["true"], "interval": 20000000},
+	"updateConfig": {"maxSurge": 1, "healthyTimeout": %d}
+}`
+
+// startStuckUpdate updates a service to an image whose replicas never become
+// healthy, and waits until the first of them is running.
+func startStuckUpdate(t *testing.T, server *testServer) models.Service {
+	t.Helper()
+
+	server.runtime.InjectFault("web:unhealthy", runtime.FakeFault{ExecExitCode: 1})
+	svc := server.createService(t, fmt.Sprintf(rollingSpec, "web:1", time.Hour))
+	server.do(t, "PUT", "/api/services/"+svc.ID, fmt.Sprintf(rollingSpec, "web:unhealthy", time.Hour), http.StatusAccepted, nil)
+	eventually(t, "a replica of the new spec to start", func() bool {
+		return len(server.runtime.ContainerIDs()) > 2
+	})
+	return svc
+}
+
+func TestRollingUpdateReplacesReplicas(t *testing.T) {
+	server := newTestServer(t)
+	svc := server.createService(t, fmt.Sprintf(rollingSpec, "web:1", time.Hour))
+	before := server.replicas(t, svc.ID)
+
+	server.do(t, "PUT", "/api/services/"+svc.ID, fmt.Sprintf(rollingSpec, "web:2", time.Hour), http.StatusAccepted, nil)
+	updated := server.waitForUpdate(t, svc.ID, models.UpdateStateCompleted)
+
+	after := server.replicas(t, svc.ID)
+	if len(after) != 2 {
+		t.Fatalf("got %d replicas, want 2", len(after))
+	}
+	hash := updated.Spec().Hash()
+	for index, replica := range after {
+		if replica.ID == before[index].ID {
+			t.Errorf("replica %s was not replaced", index)
+		}
+		if replica.Image != "web:2" || replica.Labels["podium.service.spec"] != hash {
+			t.Errorf("replica %s runs %s with spec %s, want web:2 with spec %s", index, replica.Image, replica.Labels["podium.service.spec"], hash)
+		}
+	}
+	if ids := server.runtime.ContainerIDs(); len(ids) != 2 {
+		t.Errorf("got %d containers in the runtime, want 2", len(ids))
+	}
+	if updated.UpdateStatus.UpdatedReplicas != 2 {
+		t.Errorf("got %d updated replicas, want 2", updated.UpdateStatus.UpdatedReplicas)
+	}
+}
+
+func TestUnhealthyReplacementPausesUpdate(t *testing.T) {
+	server := newTestServer(t)
+	server.runtime.InjectFault("web:unhealthy", runtime.FakeFault{ExecExitCode: 1})
+	svc := server.createService(t, fmt.Sprintf(rollingSpec, "web:1", 200*time.Millisecond))
+	before := server.replicas(t, svc.ID)
+
+	server.do(t, "PUT", "/api/services/"+svc.ID, fmt.Sprintf(rollingSpec, "web:unhealthy", 200*time.Millisecond), http.StatusAccepted, nil)
+	paused := server.waitForUpdate(t, svc.ID, models.UpdateStatePaused)
+
+	if paused.UpdateStatus.UpdatedReplicas != 0 {
+		t.Errorf("got %d updated replicas, want 0", paused.UpdateStatus.UpdatedReplicas)
+	}
+	after := server.replicas(t, svc.ID)
+	for index, replica := range before {
+		if after[index].ID != replica.ID {
+			t.Errorf("replica %s changed from %s to %s, want it kept", index, replica.ID, after[index].ID)
+		}
+	}
+	if ids := server.runtime.ContainerIDs(); len(ids) != 2 {
+		t.Errorf("got %d containers in the runtime, want the failed replacement removed", len(ids))
+	}
+}
+
+func TestProgressDeadlineRollsBack(t *testing.T) {
+	server := newTestServer(t)
+	server.runtime.InjectFault("web:unhealthy", runtime.FakeFault{ExecExitCode: 1})
+	spec := `{
+		"name": "web",
+		"image": "%s",
+		"replicas": 2,
+		"healthCheck": {"type": "command", "command": ["true"], "interval": 20000000},
+		"updateConfig": {"maxSurge": 1, "progressDeadline": %d, "failureAction": "rollback"}
+	}`
+	svc := server.createService(t, fmt.Sprintf(spec, "web:1", 300*time.Millisecond))
+
+	server.do(t, "PUT", "/api/services/"+svc.ID, fmt.Sprintf(spec, "web:unhealthy", 300*time.Millisecond), http.StatusAccepted, nil)
+	rolledBack := server.waitForUpdate(t, svc.ID, models.UpdateStateRolledBack)
+
+	if rolledBack.Revision != 1 || rolledBack.Image != "web:1" {
+		t.Errorf("got revision %d with image %s, want revision 1 with web:1", rolledBack.Revision, rolledBack.Image)
+	}
+	if reason := rolledBack.UpdateStatus.RollbackReason; !strings.Contains(reason, "progress deadline") {
+		t.Errorf("got rollback reason %q, want it to name the progress deadline", reason)
+	}
+	for index, replica := range server.replicas(t, svc.ID) {
+		if replica.Image != "web:1" {
+			t.Errorf("replica %s runs %s, want web:1", index, replica.Image)
+		}
+	}
+}
+
+func TestDeleteServiceDuringUpdate(t *testing.T) {
+	server := newTestServer(t)
+	svc := startStuckUpdate(t, server)
+
+	server.do(t, "DELETE", "/api/services/"+svc.ID, "", http.StatusOK, nil)
+
+	// Give the reconciler a few passes to restart the update.
+	time.Sleep(200 * time.Millisecond)
+	if ids := server.runtime.ContainerIDs(); len(ids) != 0 {
+		t.Errorf("containers %v are left in the runtime", ids)
+	}
+	if _, err := server.store.GetService(svc.ID); err == nil {
+		t.Errorf("service %s is still in the store", svc.ID)
+	}
+}
+
+func TestAbortUpdateRollsBack(t *testing.T) {
+	server := newTestServer(t)
+	svc := startStuckUpdate(t, server)
+
+	server.do(t, "POST", "/api/services/"+svc.ID+"/update/abort", "", http.StatusOK, nil)
+	aborted := server.waitForUpdate(t, svc.ID, models.UpdateStateRolledBack)
+	if aborted.Image != "web:1" {
+		t.Errorf("got image %s after the abort, want web:1", aborted.Image)
+	}
+	for index, replica := range server.replicas(t, svc.ID) {
+		if replica.Image != "web:1" {
+			t.Errorf("replica %s runs %s, want web:1", index, replica.Image)
+		}
+	}
+	if ids := server.runtime.ContainerIDs(); len(ids) != 2 {
+		t.Errorf("got %d containers in the runtime, want 2", len(ids))
+	}
+}
@@ -4,36 +4,68 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"podium/internal/admin"
 	"podium/internal/api/handlers"
+	adminhandler "podium/internal/api/handlers/admin"
 	"podium/internal/api/handlers/container"
+	eventhandler "podium/internal/api/handlers/event"
+	networkhandler "podium/internal/api/handlers/network"
+	"podium/internal/api/handlers/operation"
+	"podium/internal/api/handlers/registry"
+	volumehandler "podium/internal/api/handlers/volume"
+	webhookhandler "podium/internal/api/handlers/webhook"
+	"podium/internal/events"
+	"podium/internal/image"
+	"podium/internal/metrics"
+	"podium/internal/network"
 	"podium/internal/runtime"
+	"podium/internal/stats"
 	"podium/internal/store"
 	"podium/internal/service"
+	"podium/internal/volume"
+	"podium/internal/webhook"
 	servicehandler "podium/internal/api/handlers/service"
 )
 
 type Server struct {
 	router *mux.Router
-	store  *store.BoltStore
+	store          store.Store
 	runtime runtime.Runtime
 	serviceManager service.Manager
+	puller         *image.Puller
+	stats          *stats.Collector
+	events         *events.Bus
+	webhooks       *webhook.Dispatcher
+	networks       *network.Manager
+	volumes        *volume.Manager
+	restorer       *admin.Restorer
 }
 
-func NewServer(store *store.BoltStore, runtime runtime.Runtime, serviceManager service.Manager) *Server {
+func NewServer(store store.Store, runtime runtime.Runtime, serviceManager service.Manager, puller *image.Puller, stats *stats.Collector, bus *events.Bus, webhooks *webhook.Dispatcher, networks *network.Manager, volumes *volume.Manager, restorer *admin.Restorer) *Server {
 	s := &Server{
 		router: mux.NewRouter(),
 		store:  store,
 		runtime: runtime,
 		serviceManager: serviceManager,
+		puller:         puller,
+		stats:          stats,
+		events:         bus,
+		webhooks:       webhooks,
+		networks:       networks,
+		volumes:        volumes,
+		restorer:       restorer,
 	}
 	s.setupRoutes()
 	return s
 }
 
 func (s *Server) setupRoutes() {
+	s.router.Use(metrics.Middleware)
+
 	s.router.HandleFunc("/health", handlers.NewHealthHandler().HandleHealth).Methods("GET")
+	s.router.Handle("/metrics", metrics.Handler()).Methods("GET")
 	
-	containerHandler := container.NewHandler(s.store, s.runtime)
+	containerHandler := container.NewHandler(s.store, s.runtime, s.puller, s.networks, s.volumes, s.stats, s.events)
 	
 	s.router.HandleFunc("/api/containers", containerHandler.HandleList).Methods("GET")
 	s.router.HandleFunc("/api/containers", containerHandler.HandleCreate).Methods("POST")
@@ -44,8 +76,60 @@ func (s *Server) setupRoutes() {
 	s.router.HandleFunc("/api/containers/{id}/stop", containerHandler.HandleStop).Methods("POST")
 	s.router.HandleFunc("/api/containers/{id}/logs", containerHandler.HandleLogs).Methods("GET")
 	s.router.HandleFunc("/api/containers/{id}/health", containerHandler.HandleHealth).Methods("GET")
+	s.router.HandleFunc("/api/containers/{id}/exec", containerHandler.HandleExec).Methods("GET")
+	s.router.HandleFunc("/api/containers/{id}/stats", containerHandler.HandleStats).Methods("GET")
+	s.router.HandleFunc("/api/containers/{id}/stats/history", containerHandler.HandleStatsHistory).Methods("GET")
 
 	servicehandler.RegisterRoutes(s.router, s.store, s.runtime, s.serviceManager)
+
+	registryHandler := registry.NewHandler(s.store)
+
+	s.router.HandleFunc("/api/registries", registryHandler.HandleList).Methods("GET")
+	s.router.HandleFunc("/api/registries", registryHandler.HandleCreate).Methods("POST")
+	s.router.HandleFunc("/api/registries/{registry}", registryHandler.HandleDelete).Methods("DELETE")
+
+	operationHandler := operation.NewHandler(s.puller)
+
+	s.router.HandleFunc("/api/operations/{id}", operationHandler.HandleGet).Methods("GET")
+
+	eventHandler := eventhandler.NewHandler(s.events)
+
+	s.router.HandleFunc("/api/events", eventHandler.HandleList).Methods("GET")
+
+	webhookHandler := webhookhandler.NewHandler(s.store, s.webhooks)
+
+	s.router.HandleFunc("/api/webhooks", webhookHandler.HandleList).Methods("GET")
+	s.router.HandleFunc("/api/webhooks", webhookHandler.HandleCreate).Methods("POST")
+	s.router.HandleFunc("/api/webhooks/{id}", webhookHandler.HandleGet).Methods("GET")
+	s.router.HandleFunc("/api/webhooks/{id}", webhookHandler.HandleUpdate).Methods("PUT")
+	s.router.HandleFunc("/api/webhooks/{id}", webhookHandler.HandleDelete).Methods("DELETE")
+	s.router.HandleFunc("/api/webhooks/{id}/deliveries", webhookHandler.HandleDeliveries).Methods("GET")
+	s.router.HandleFunc("/api/webhooks/{id}/test", webhookHandler.HandleTest).Methods("POST")
+
+	networkHandler := networkhandler.NewHandler(s.store, s.networks)
+
+	s.router.HandleFunc("/api/networks", networkHandler.HandleList).Methods("GET")
+	s.router.HandleFunc("/api/networks", networkHandler.HandleCreate).Methods("POST")
+	s.router.HandleFunc("/api/networks/prune", networkHandler.HandlePrune).Methods("POST")
+	s.router.HandleFunc("/api/networks/{name}", networkHandler.HandleGet).Methods("GET")
+	s.router.HandleFunc("/api/networks/{name}", networkHandler.HandleDelete).Methods("DELETE")
+
+	volumeHandler := volumehandler.NewHandler(s.store, s.volumes)
+
+	s.router.HandleFunc("/api/volumes", volumeHandler.HandleList).Methods("GET")
+	s.router.HandleFunc("/api/volumes", volumeHandler.HandleCreate).Methods("POST")
+	s.router.HandleFunc("/api/volumes/{name}", volumeHandler.HandleGet).Methods("GET")
+	s.router.HandleFunc("/api/volumes/{name}", volumeHandler.HandleDelete).Methods("DELETE")
+	s.router.HandleFunc("/api/volumes/{name}/backup", volumeHandler.HandleBackup).Methods("GET")
+	s.router.HandleFunc("/api/volumes/{name}/restore", volumeHandler.HandleRestore).Methods("POST")
+	s.router.HandleFunc("/api/volumes/{name}/backups", volumeHandler.HandleListBackups).Methods("GET")
+	s.router.HandleFunc("/api/volumes/{name}/backups", volumeHandler.HandleCreateBackup).Methods("POST")
+	s.router.HandleFunc("/api/volumes/{name}/backup-schedule", volumeHandler.HandleSetBackupSchedule).Methods("PUT")
+
+	adminHandler := adminhandler.NewHandler(s.store, s.restorer)
+
+	s.router.HandleFunc("/api/admin/backup", adminHandler.HandleBackup).Methods("GET")
+	s.router.HandleFunc("/api/admin/restore", adminHandler.HandleRestore).Methods("POST")
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,212 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"podium/internal/admin"
+	"podium/internal/api"
+	"podium/internal/events"
+	"podium/internal/health"
+	"podium/internal/image"
+	"podium/internal/models"
+	"podium/internal/network"
+	"podium/internal/runtime"
+	"podium/internal/service"
+	"podium/internal/stats"
+	"podium/internal/store"
+	"podium/internal/volume"
+	"podium/internal/webhook"
+)
+
+// testServer is a Podium server on the fake runtime and the memory store,
+// with the health worker and the reconciler running.
+type testServer struct {
+	*httptest.Server
+	store   store.Store
+	runtime *runtime.FakeRuntime
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	stateStore := store.NewMemoryStore()
+	rt := runtime.NewFakeRuntime()
+	bus := events.NewBus(stateStore, 1000)
+	dispatcher := webhook.NewDispatcher(stateStore, bus)
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("starting webhook dispatcher: %v", err)
+	}
+	puller := image.NewPuller(rt, stateStore)
+	networks := network.NewManager(stateStore, rt, time.Minute)
+	volumes := volume.NewManager(stateStore, rt, t.TempDir(), time.Minute)
+	manager := service.NewManager(rt, stateStore, puller, networks, volumes, bus)
+	collector := stats.NewCollector(stateStore, rt, time.Minute, time.Hour)
+	restorer := admin.NewRestorer(stateStore, rt, puller, manager)
+
+	worker := health.NewWorker(stateStore, rt, bus, 50*time.Millisecond, 3)
+	worker.Start()
+	reconciler := service.NewReconciler(manager, 50*time.Millisecond)
+	reconciler.Start()
+
+	server := httptest.NewServer(api.NewServer(stateStore, rt, manager, puller, collector, bus, dispatcher, networks, volumes, restorer))
+	t.Cleanup(func() {
+		server.Close()
+		reconciler.Stop()
+		worker.Stop()
+		dispatcher.Stop()
+	})
+	return &testServer{Server: server, store: stateStore, runtime: rt}
+}
+
+func (s *testServer) createService(t *testing.T, spec string) models.Service {
+	t.Helper()
+
+	resp, err := http.Post(s.URL+"/api/services", "application/json", strings.NewReader(spec))
+	if err != nil {
+		t.Fatalf("creating service: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		var body bytes.Buffer
+		body.ReadFrom(resp.Body)
+		t.Fatalf("creating service: status %d: %s", resp.StatusCode, body.String())
+	}
+
+	var created models.Service
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("decoding service: %v", err)
+	}
+	return created
+}
+
+// do sends a request to the API and fails the test unless it answers with
+// the wanted status. The response is decoded into out if it is not nil.
+func (s *testServer) do(t *testing.T, method, path, body string, want int, out any) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, s.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != want {
+		var body bytes.Buffer
+		body.ReadFrom(resp.Body)
+		t.Fatalf("%s %s: status %d, want %d: %s", method, path, resp.StatusCode, want, body.String())
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decoding response to %s %s: %v", method, path, err)
+		}
+	}
+}
+
+// service returns the stored service.
+func (s *testServer) service(t *testing.T, serviceID string) models.Service {
+	t.Helper()
+
+	svc, err := s.store.GetService(serviceID)
+	if err != nil {
+		t.Fatalf("getting service: %v", err)
+	}
+	return svc
+}
+
+// waitForUpdate waits until the service's update reaches the given state.
+func (s *testServer) waitForUpdate(t *testing.T, serviceID string, state models.UpdateState) models.Service {
+	t.Helper()
+
+	var svc models.Service
+	eventually(t, "update to be "+string(state), func() bool {
+		svc = s.service(t, serviceID)
+		return svc.UpdateStatus != nil && svc.UpdateStatus.State == state
+	})
+	return svc
+}
+
+// replicas returns the container of each replica of a service, by replica
+// index.
+func (s *testServer) replicas(t *testing.T, serviceID string) map[string]models.Container {
+	t.Helper()
+
+	svc, err := s.store.GetService(serviceID)
+	if err != nil {
+		t.Fatalf("getting service: %v", err)
+	}
+	replicas := make(map[string]models.Container)
+	for _, id := range svc.ContainerIDs {
+		container, err := s.store.GetContainer(id)
+		if err != nil {
+			continue
+		}
+		replicas[container.Labels["podium.replica.index"]] = container
+	}
+	return replicas
+}
+
+// eventually polls condition until it holds, failing the test after a few
+// seconds.
+func eventually(t *testing.T, message string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting: %s", message)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestCrashedReplicaIsRecreated(t *testing.T) {
+	server := newTestServer(t)
+	svc := server.createService(t, `{"name": "web", "image": "nginx:latest", "replicas": 2}`)
+
+	before := server.replicas(t, svc.ID)
+	if len(before) != 2 {
+		t.Fatalf("got %d replicas, want 2", len(before))
+	}
+	crashed := before["1"]
+	if err := server.runtime.Crash(crashed.ID, 1); err != nil {
+		t.Fatalf("crashing replica: %v", err)
+	}
+
+	eventually(t, "replica 1 to be recreated", func() bool {
+		replacement, ok := server.replicas(t, svc.ID)["1"]
+		if !ok || replacement.ID == crashed.ID {
+			return false
+		}
+		state, err := server.runtime.GetContainerStatus(t.Context(), replacement.ID)
+		return err == nil && state == models.ContainerStateRunning
+	})
+
+	after := server.replicas(t, svc.ID)
+	if len(after) != 2 {
+		t.Errorf("got %d replicas after recreation, want 2", len(after))
+	}
+	if after["0"].ID != before["0"].ID {
+		t.Errorf("replica 0 changed from %s to %s, want it left alone", before["0"].ID, after["0"].ID)
+	}
+	if _, err := server.runtime.GetContainerStatus(t.Context(), crashed.ID); err == nil {
+		t.Errorf("crashed container %s is still in the runtime", crashed.ID)
+	}
+
+	recreated, err := server.store.ListEvents(models.EventFilter{Types: []models.EventType{models.EventServiceReplicaRecreated}})
+	if err != nil {
+		t.Fatalf("listing events: %v", err)
+	}
+	if len(recreated) != 1 || recreated[0].Attributes["previousContainerId"] != crashed.ID {
+		t.Errorf("got replica recreated events %+v, want one for %s", recreated, crashed.ID)
+	}
+}
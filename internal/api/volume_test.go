@@ -0,0 +1,72 @@
+package api_test
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+
+	"podium/internal/models"
+)
+
+// volumeDetails is a volume as GET /api/volumes/{name} returns it.
+type volumeDetails struct {
+	models.Volume
+	Containers []string `json:"containers"`
+	Services   []string `json:"services"`
+}
+
+func TestVolumeLifecycle(t *testing.T) {
+	server := newTestServer(t)
+	server.do(t, "POST", "/api/volumes", `{"name": "data"}`, http.StatusCreated, nil)
+	server.do(t, "POST", "/api/volumes", `{"name": "data"}`, http.StatusConflict, nil)
+	server.do(t, "POST", "/api/volumes", `{"name": "-data"}`, http.StatusBadRequest, nil)
+
+	svc := server.createService(t, `{"name": "db", "image": "postgres:16", "replicas": 2, "mounts": [
+		{"source": "data", "target": "/var/lib/postgresql/data"},
+		{"type": "tmpfs", "target": "/tmp"}]}`)
+	var replicaIDs []string
+	for index, replica := range server.replicas(t, svc.ID) {
+		replicaIDs = append(replicaIDs, replica.ID)
+		if len(replica.Mounts) != 2 || replica.Mounts[0].Source != "data" || replica.Mounts[1].Type != models.MountTypeTmpfs {
+			t.Errorf("replica %s mounts %+v, want volume data and a tmpfs", index, replica.Mounts)
+		}
+	}
+	sort.Strings(replicaIDs)
+
+	var details volumeDetails
+	server.do(t, "GET", "/api/volumes/data", "", http.StatusOK, &details)
+	if details.Auto || details.Driver != models.VolumeDriverLocal || len(details.Services) != 1 || details.Services[0] != svc.ID ||
+		len(details.Containers) != 2 || details.Containers[0] != replicaIDs[0] || details.Containers[1] != replicaIDs[1] {
+		t.Errorf("got volume %+v, want a manual local volume used by service %s and replicas %v", details, svc.ID, replicaIDs)
+	}
+
+	server.do(t, "DELETE", "/api/volumes/data", "", http.StatusConflict, nil)
+	server.do(t, "DELETE", "/api/services/"+svc.ID, "", http.StatusOK, nil)
+	server.do(t, "DELETE", "/api/volumes/data", "", http.StatusNoContent, nil)
+	server.do(t, "GET", "/api/volumes/data", "", http.StatusNotFound, nil)
+}
+
+func TestUnknownVolumeIsCreatedAndKept(t *testing.T) {
+	server := newTestServer(t)
+	svc := server.createService(t, `{"name": "db", "image": "postgres:16", "mounts": [{"source": "cache", "target": "/cache"}]}`)
+
+	var details volumeDetails
+	server.do(t, "GET", "/api/volumes/cache", "", http.StatusOK, &details)
+	if !details.Auto {
+		t.Errorf("volume cache was not marked as created automatically")
+	}
+
+	// Unlike networks, volumes hold data and outlive the services that
+	// created them.
+	server.do(t, "DELETE", "/api/services/"+svc.ID, "", http.StatusOK, nil)
+	server.do(t, "GET", "/api/volumes/cache", "", http.StatusOK, &details)
+	if len(details.Services) != 0 || len(details.Containers) != 0 {
+		t.Errorf("volume cache is still used by %v and %v after its service was deleted", details.Services, details.Containers)
+	}
+}
+
+func TestInvalidMountsAreRejected(t *testing.T) {
+	server := newTestServer(t)
+	server.do(t, "POST", "/api/services", `{"name": "db", "image": "postgres:16", "mounts": [{"source": "data", "target": "data"}]}`, http.StatusBadRequest, nil)
+	server.do(t, "GET", "/api/volumes/data", "", http.StatusNotFound, nil)
+}
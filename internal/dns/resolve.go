@@ -0,0 +1,152 @@
+package dns
+
+import (
+	"log"
+	"math/rand/v2"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// maxUDPSize is the largest response sent over UDP. Larger responses are
+// truncated so the client retries over TCP.
+const maxUDPSize = 512
+
+// handle answers a query, or returns nil if it cannot be parsed.
+func (s *Server) handle(query []byte, udp bool) []byte {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(query)
+	if err != nil || header.Response {
+		return nil
+	}
+	question, err := parser.Question()
+	if err != nil {
+		return s.reply(header, nil, dnsmessage.RCodeFormatError, nil, nil, udp)
+	}
+
+	name := strings.ToLower(question.Name.String())
+	if name != Domain+"." && !strings.HasSuffix(name, "."+Domain+".") {
+		resp, err := s.forward(query, udp)
+		if err != nil {
+			log.Printf("Error forwarding DNS query for %s: %v", name, err)
+			return s.reply(header, &question, dnsmessage.RCodeServerFailure, nil, nil, udp)
+		}
+		return resp
+	}
+
+	answers, additionals, rcode := s.lookup(question)
+	return s.reply(header, &question, rcode, answers, additionals, udp)
+}
+
+// lookup answers a question about the Podium zone. "<service>.podium" has
+// an A record for every healthy replica and an SRV record for every port of
+// every healthy replica; "<container-id>.<service>.podium" has the A record
+// of one replica.
+func (s *Server) lookup(question dnsmessage.Question) ([]dnsmessage.Resource, []dnsmessage.Resource, dnsmessage.RCode) {
+	name := strings.ToLower(question.Name.String())
+	if name == Domain+"." {
+		return nil, nil, dnsmessage.RCodeSuccess
+	}
+	labels := strings.Split(strings.TrimSuffix(name, "."+Domain+"."), ".")
+	if len(labels) > 2 {
+		return nil, nil, dnsmessage.RCodeNameError
+	}
+	serviceName := labels[len(labels)-1]
+
+	s.mu.RLock()
+	rec, ok := s.records[serviceName]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, nil, dnsmessage.RCodeNameError
+	}
+
+	replicas := rec.replicas
+	if len(labels) == 2 {
+		replicas = nil
+		for _, r := range rec.replicas {
+			if r.containerID == labels[0] {
+				replicas = append(replicas, r)
+			}
+		}
+		if len(replicas) == 0 {
+			return nil, nil, dnsmessage.RCodeNameError
+		}
+	} else {
+		// Shuffle the replicas so clients that take the first address
+		// spread across them.
+		replicas = append([]replica(nil), replicas...)
+		rand.Shuffle(len(replicas), func(i, j int) {
+			replicas[i], replicas[j] = replicas[j], replicas[i]
+		})
+	}
+
+	ttl := uint32(s.interval.Seconds())
+	var answers, additionals []dnsmessage.Resource
+	switch question.Type {
+	case dnsmessage.TypeA:
+		for _, r := range replicas {
+			answers = append(answers, aRecord(question.Name, r, ttl))
+		}
+	case dnsmessage.TypeSRV:
+		if len(labels) == 2 {
+			break
+		}
+		for _, r := range replicas {
+			target, err := dnsmessage.NewName(r.containerID + "." + serviceName + "." + Domain + ".")
+			if err != nil {
+				continue
+			}
+			for _, port := range rec.ports {
+				answers = append(answers, dnsmessage.Resource{
+					Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeSRV, Class: dnsmessage.ClassINET, TTL: ttl},
+					Body:   &dnsmessage.SRVResource{Priority: 0, Weight: 10, Port: uint16(port.ContainerPort), Target: target},
+				})
+			}
+			additionals = append(additionals, aRecord(target, r, ttl))
+		}
+	}
+	return answers, additionals, dnsmessage.RCodeSuccess
+}
+
+func aRecord(name dnsmessage.Name, r replica, ttl uint32) dnsmessage.Resource {
+	var ip [4]byte
+	copy(ip[:], r.ip)
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.AResource{A: ip},
+	}
+}
+
+// reply builds the response to a query. Responses too large for UDP are
+// sent without their records and marked as truncated.
+func (s *Server) reply(query dnsmessage.Header, question *dnsmessage.Question, rcode dnsmessage.RCode, answers, additionals []dnsmessage.Resource, udp bool) []byte {
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 query.ID,
+			Response:           true,
+			Authoritative:      question != nil && rcode != dnsmessage.RCodeServerFailure,
+			RecursionDesired:   query.RecursionDesired,
+			RecursionAvailable: len(s.upstreams) > 0,
+			RCode:              rcode,
+		},
+		Answers:     answers,
+		Additionals: additionals,
+	}
+	if question != nil {
+		msg.Questions = []dnsmessage.Question{*question}
+	}
+
+	resp, err := msg.Pack()
+	if err != nil {
+		log.Printf("Error packing DNS response: %v", err)
+		return nil
+	}
+	if udp && len(resp) > maxUDPSize {
+		msg.Truncated = true
+		msg.Answers, msg.Additionals = nil, nil
+		if resp, err = msg.Pack(); err != nil {
+			return nil
+		}
+	}
+	return resp
+}
@@ -0,0 +1,392 @@
+package dns
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"podium/internal/models"
+	"podium/internal/runtime"
+	"podium/internal/service"
+	"podium/internal/store"
+)
+
+// Domain is the zone the server answers for: a service named "web" is
+// "web.podium".
+const Domain = "podium"
+
+// Server is Podium's embedded DNS server. It resolves service names to the
+// addresses of their healthy replicas, refreshed at a fixed interval, and
+// forwards every other query to the upstream name servers.
+type Server struct {
+	store     store.Store
+	runtime   runtime.Runtime
+	services  service.Manager
+	interval  time.Duration
+	upstreams []string
+	// resolver is the address containers send queries to, empty if they
+	// cannot use the server.
+	resolver string
+	udp      net.PacketConn
+	tcp      net.Listener
+	stopCh   chan struct{}
+
+	mu      sync.RWMutex
+	records map[string]*serviceRecords
+	// ips caches the IP address of each replica, keyed by container ID and
+	// start time, as a restart can change it. It is only used by refresh.
+	ips map[string]string
+}
+
+// serviceRecords is what the server knows about one service.
+type serviceRecords struct {
+	ports    []models.PortMapping
+	replicas []replica
+}
+
+type replica struct {
+	containerID string
+	ip          net.IP
+}
+
+// NewServer listens on addr for DNS queries over UDP and TCP. An empty addr
+// means port 53 of the address containers reach the host at. Without
+// upstreams, the name servers in /etc/resolv.conf are used.
+func NewServer(store store.Store, rt runtime.Runtime, addr string, upstreams []string, interval time.Duration) (*Server, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if addr == "" {
+		host, err := rt.HostAddress(ctx)
+		if err != nil {
+			return nil, err
+		}
+		addr = net.JoinHostPort(host, "53")
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %v", addr, err)
+	}
+
+	s := &Server{
+		store:    store,
+		runtime:  rt,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+		records:  make(map[string]*serviceRecords),
+		ips:      make(map[string]string),
+	}
+
+	// Containers can only be given the IP address of a name server, which
+	// they query on port 53.
+	if port == "53" {
+		s.resolver = host
+		if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+			if s.resolver, err = rt.HostAddress(ctx); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		log.Printf("Warning: DNS server on port %s cannot be used as the resolver of containers", port)
+	}
+
+	if len(upstreams) == 0 {
+		upstreams = systemNameServers()
+	}
+	for _, upstream := range upstreams {
+		if _, _, err := net.SplitHostPort(upstream); err != nil {
+			upstream = net.JoinHostPort(upstream, "53")
+		}
+		if upstream == net.JoinHostPort(s.resolver, "53") || upstream == addr {
+			continue
+		}
+		s.upstreams = append(s.upstreams, upstream)
+	}
+
+	s.udp, err = net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s.tcp, err = net.Listen("tcp", addr)
+	if err != nil {
+		s.udp.Close()
+		return nil, err
+	}
+	log.Printf("DNS server listening on %s, forwarding to %v", addr, s.upstreams)
+	return s, nil
+}
+
+// Resolver returns the name servers containers should use, or nil if they
+// cannot use this server.
+func (s *Server) Resolver() []string {
+	if s.resolver == "" {
+		return nil
+	}
+	return []string{s.resolver}
+}
+
+// Start answers queries with the replicas reported by services.
+func (s *Server) Start(services service.Manager) {
+	s.services = services
+	go s.serveUDP()
+	go s.serveTCP()
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.refresh()
+		for {
+			select {
+			case <-s.stopCh:
+				log.Println("DNS server stopped")
+				return
+			case <-ticker.C:
+				s.refresh()
+			}
+		}
+	}()
+	log.Println("DNS server started")
+}
+
+func (s *Server) Stop() {
+	close(s.stopCh)
+	s.udp.Close()
+	s.tcp.Close()
+}
+
+// refresh rebuilds the records of every service from its healthy replicas.
+func (s *Server) refresh() {
+	services, err := s.store.ListServices()
+	if err != nil {
+		log.Printf("Error listing services for DNS: %v", err)
+		return
+	}
+
+	records := make(map[string]*serviceRecords, len(services))
+	seen := map[string]bool{}
+	for _, svc := range services {
+		rec, err := s.serviceRecords(svc, seen)
+		if err != nil {
+			log.Printf("Error getting status of service %s for DNS: %v", svc.ID, err)
+			continue
+		}
+		records[strings.ToLower(svc.Name)] = rec
+	}
+	for key := range s.ips {
+		if !seen[key] {
+			delete(s.ips, key)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, rec := range records {
+		if previous, ok := s.records[name]; !ok || len(previous.replicas) != len(rec.replicas) {
+			log.Printf("DNS name %s.%s now resolves to %d replicas", name, Domain, len(rec.replicas))
+		}
+	}
+	s.records = records
+}
+
+func (s *Server) serviceRecords(svc models.Service, seen map[string]bool) (*serviceRecords, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+	defer cancel()
+
+	status, err := s.services.GetServiceStatus(ctx, svc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A blue-green update's new replicas are not resolved until it cuts
+	// over to them.
+	update := svc.UpdateStatus
+	holdBack := update.InProgress() && !update.Rollback && svc.UpdateConfig != nil &&
+		svc.UpdateConfig.Strategy == models.UpdateStrategyBlueGreen
+
+	rec := &serviceRecords{ports: svc.Ports}
+	for _, container := range status.Containers {
+		if container.Status != string(models.ContainerStateRunning) || container.HealthState != string(models.HealthStatusHealthy) {
+			continue
+		}
+		if holdBack && container.SpecHash == status.SpecHash {
+			continue
+		}
+
+		key := container.ID + "/" + container.StartedAt
+		address, ok := s.ips[key]
+		if !ok {
+			address, err = s.runtime.ContainerIP(ctx, container.ID)
+			if errors.Is(err, runtime.ErrNoContainerIP) {
+				continue
+			}
+			if err != nil {
+				log.Printf("Error getting IP address of container %s for DNS: %v", container.ID, err)
+				continue
+			}
+			s.ips[key] = address
+		}
+		seen[key] = true
+
+		ip := net.ParseIP(address).To4()
+		if ip == nil {
+			continue
+		}
+		rec.replicas = append(rec.replicas, replica{containerID: container.ID, ip: ip})
+	}
+	return rec, nil
+}
+
+func (s *Server) serveUDP() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("DNS server failed: %v", err)
+			}
+			return
+		}
+		query := make([]byte, n)
+		copy(query, buf[:n])
+		go func() {
+			if resp := s.handle(query, true); resp != nil {
+				s.udp.WriteTo(resp, addr)
+			}
+		}()
+	}
+}
+
+func (s *Server) serveTCP() {
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("DNS server failed: %v", err)
+			}
+			return
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// serveConn answers the queries sent over a TCP connection, each preceded
+// by its length.
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	for {
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+		query, err := readTCPMessage(conn)
+		if err != nil {
+			return
+		}
+		resp := s.handle(query, false)
+		if resp == nil {
+			return
+		}
+		if err := writeTCPMessage(conn, resp); err != nil {
+			return
+		}
+	}
+}
+
+// forward sends a query to each upstream name server in turn and returns
+// the first response.
+func (s *Server) forward(query []byte, udp bool) ([]byte, error) {
+	if len(s.upstreams) == 0 {
+		return nil, errors.New("no upstream name servers")
+	}
+	var lastErr error
+	for _, upstream := range s.upstreams {
+		resp, err := exchange(upstream, query, udp)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func exchange(upstream string, query []byte, udp bool) ([]byte, error) {
+	network := "tcp"
+	if udp {
+		network = "udp"
+	}
+	conn, err := net.DialTimeout(network, upstream, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if !udp {
+		if err := writeTCPMessage(conn, query); err != nil {
+			return nil, err
+		}
+		return readTCPMessage(conn)
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// Skip stray responses to other queries.
+		if n >= 2 && buf[0] == query[0] && buf[1] == query[1] {
+			return buf[:n], nil
+		}
+	}
+}
+
+func readTCPMessage(r io.Reader) ([]byte, error) {
+	var length uint16
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func writeTCPMessage(w io.Writer, msg []byte) error {
+	buf := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+	copy(buf[2:], msg)
+	_, err := w.Write(buf)
+	return err
+}
+
+// systemNameServers returns the name servers in /etc/resolv.conf.
+func systemNameServers() []string {
+	f, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		log.Printf("Error reading /etc/resolv.conf: %v", err)
+		return nil
+	}
+	defer f.Close()
+
+	var servers []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, fields[1])
+		}
+	}
+	return servers
+}
@@ -0,0 +1,181 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"podium/internal/events"
+	"podium/internal/image"
+	"podium/internal/models"
+	"podium/internal/network"
+	"podium/internal/runtime"
+	"podium/internal/service"
+	"podium/internal/store"
+	"podium/internal/volume"
+)
+
+// newTestServer returns a server with records for a service "web" of two
+// replicas, exposing ports 80 and 443. It does not listen.
+func newTestServer() *Server {
+	return &Server{
+		interval: 5 * time.Second,
+		records: map[string]*serviceRecords{
+			"web": {
+				ports: []models.PortMapping{{ContainerPort: 80}, {ContainerPort: 443}},
+				replicas: []replica{
+					{containerID: "c1", ip: net.IPv4(10, 0, 0, 1).To4()},
+					{containerID: "c2", ip: net.IPv4(10, 0, 0, 2).To4()},
+				},
+			},
+		},
+	}
+}
+
+func question(name string, qtype dnsmessage.Type) dnsmessage.Question {
+	return dnsmessage.Question{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET}
+}
+
+// addresses returns the addresses of the A records among resources, sorted.
+func addresses(resources []dnsmessage.Resource) []string {
+	var result []string
+	for _, r := range resources {
+		if a, ok := r.Body.(*dnsmessage.AResource); ok {
+			result = append(result, net.IP(a.A[:]).String())
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestLookupA(t *testing.T) {
+	s := newTestServer()
+
+	answers, _, rcode := s.lookup(question("Web.podium.", dnsmessage.TypeA))
+	if rcode != dnsmessage.RCodeSuccess {
+		t.Fatalf("rcode = %v, want success", rcode)
+	}
+	if got := addresses(answers); len(got) != 2 || got[0] != "10.0.0.1" || got[1] != "10.0.0.2" {
+		t.Errorf("got addresses %v, want 10.0.0.1 and 10.0.0.2", got)
+	}
+	if ttl := answers[0].Header.TTL; ttl != 5 {
+		t.Errorf("TTL = %d, want the 5 second refresh interval", ttl)
+	}
+
+	answers, _, rcode = s.lookup(question("c2.web.podium.", dnsmessage.TypeA))
+	if got := addresses(answers); rcode != dnsmessage.RCodeSuccess || len(got) != 1 || got[0] != "10.0.0.2" {
+		t.Errorf("replica c2: got %v with rcode %v, want 10.0.0.2", got, rcode)
+	}
+}
+
+func TestLookupSRV(t *testing.T) {
+	s := newTestServer()
+
+	answers, additionals, rcode := s.lookup(question("web.podium.", dnsmessage.TypeSRV))
+	if rcode != dnsmessage.RCodeSuccess {
+		t.Fatalf("rcode = %v, want success", rcode)
+	}
+
+	var got []string
+	for _, r := range answers {
+		srv := r.Body.(*dnsmessage.SRVResource)
+		got = append(got, fmt.Sprintf("%s:%d", srv.Target, srv.Port))
+	}
+	sort.Strings(got)
+	want := []string{"c1.web.podium.:443", "c1.web.podium.:80", "c2.web.podium.:443", "c2.web.podium.:80"}
+	if len(got) != len(want) {
+		t.Fatalf("got SRV records %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got SRV records %v, want %v", got, want)
+			break
+		}
+	}
+	if got := addresses(additionals); len(got) != 2 {
+		t.Errorf("got additional addresses %v, want one for each target", got)
+	}
+}
+
+func TestLookupNameError(t *testing.T) {
+	s := newTestServer()
+
+	for _, name := range []string{"db.podium.", "c3.web.podium.", "a.c1.web.podium."} {
+		if _, _, rcode := s.lookup(question(name, dnsmessage.TypeA)); rcode != dnsmessage.RCodeNameError {
+			t.Errorf("%s: rcode = %v, want NXDOMAIN", name, rcode)
+		}
+	}
+	if answers, _, rcode := s.lookup(question("podium.", dnsmessage.TypeA)); rcode != dnsmessage.RCodeSuccess || len(answers) != 0 {
+		t.Errorf("podium.: got %d answers with rcode %v, want none with success", len(answers), rcode)
+	}
+}
+
+func TestHandleTruncatesLargeUDPResponses(t *testing.T) {
+	s := newTestServer()
+	var replicas []replica
+	for i := 0; i < 40; i++ {
+		replicas = append(replicas, replica{containerID: fmt.Sprintf("c%d", i), ip: net.IPv4(10, 0, 1, byte(i)).To4()})
+	}
+	s.records["web"].replicas = replicas
+
+	query, err := (&dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 7, RecursionDesired: true},
+		Questions: []dnsmessage.Question{question("web.podium.", dnsmessage.TypeA)},
+	}).Pack()
+	if err != nil {
+		t.Fatalf("packing query: %v", err)
+	}
+
+	var udp dnsmessage.Message
+	if err := udp.Unpack(s.handle(query, true)); err != nil {
+		t.Fatalf("unpacking UDP response: %v", err)
+	}
+	if udp.ID != 7 || !udp.Truncated || len(udp.Answers) != 0 {
+		t.Errorf("UDP response has ID %d, truncated %v and %d answers, want ID 7, truncated and none", udp.ID, udp.Truncated, len(udp.Answers))
+	}
+
+	var tcp dnsmessage.Message
+	if err := tcp.Unpack(s.handle(query, false)); err != nil {
+		t.Fatalf("unpacking TCP response: %v", err)
+	}
+	if tcp.Truncated || len(tcp.Answers) != 40 {
+		t.Errorf("TCP response is truncated %v with %d answers, want all 40", tcp.Truncated, len(tcp.Answers))
+	}
+}
+
+func TestRefreshResolvesRunningReplicas(t *testing.T) {
+	stateStore := store.NewMemoryStore()
+	rt := runtime.NewFakeRuntime()
+	bus := events.NewBus(stateStore, 100)
+	puller := image.NewPuller(rt, stateStore)
+	networks := network.NewManager(stateStore, rt, time.Minute)
+	volumes := volume.NewManager(stateStore, rt, t.TempDir(), time.Minute)
+	manager := service.NewManager(rt, stateStore, puller, networks, volumes, bus)
+
+	svc := models.Service{ID: "svc-web", Name: "Web", Image: "nginx:latest", Replicas: 2}
+	if err := stateStore.CreateService(svc); err != nil {
+		t.Fatalf("storing service: %v", err)
+	}
+	if err := manager.CreateService(context.Background(), &svc); err != nil {
+		t.Fatalf("creating service: %v", err)
+	}
+	if err := rt.Crash(svc.ContainerIDs[0], 1); err != nil {
+		t.Fatalf("crashing replica: %v", err)
+	}
+
+	s := &Server{store: stateStore, runtime: rt, services: manager, interval: time.Second, ips: map[string]string{}}
+	s.refresh()
+
+	want, err := rt.ContainerIP(context.Background(), svc.ContainerIDs[1])
+	if err != nil {
+		t.Fatalf("getting replica address: %v", err)
+	}
+	answers, _, rcode := s.lookup(question("web.podium.", dnsmessage.TypeA))
+	if got := addresses(answers); rcode != dnsmessage.RCodeSuccess || len(got) != 1 || got[0] != want {
+		t.Errorf("got %v with rcode %v, want only the running replica at %s", got, rcode, want)
+	}
+}
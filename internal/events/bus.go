@@ -0,0 +1,125 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"podium/internal/models"
+	"podium/internal/store"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind by. A
+// subscriber that falls further behind is dropped and its channel closed.
+const subscriberBuffer = 64
+
+type subscriber struct {
+	filter models.EventFilter
+	ch     chan models.Event
+}
+
+// Bus records lifecycle events in the store and delivers them to
+// subscribers as they are published. Only the most recent events are kept.
+type Bus struct {
+	store     store.Store
+	maxEvents int
+
+	// publishMu serializes storing and delivering events, so subscribers get
+	// them in ID order. Subscribe holds it too, so that no event falls
+	// between a backlog and the live events that follow it.
+	publishMu sync.Mutex
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+func NewBus(store store.Store, maxEvents int) *Bus {
+	return &Bus{
+		store:       store,
+		maxEvents:   maxEvents,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Publish stores event and sends it to every subscriber whose filter it
+// matches. Failing to store an event is logged rather than returned, so
+// publishing never gets in the way of the change being reported.
+func (b *Bus) Publish(event models.Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.publishMu.Lock()
+	err := b.store.SaveEvent(&event)
+	if err != nil {
+		log.Printf("Failed to store %s event: %v", event.Type, err)
+	}
+	b.deliver(event)
+	b.publishMu.Unlock()
+
+	if err == nil && event.ID%100 == 0 {
+		if err := b.store.PruneEvents(b.maxEvents); err != nil {
+			log.Printf("Failed to prune events: %v", err)
+		}
+	}
+}
+
+// deliver sends event to the subscribers whose filter it matches, dropping
+// those that have fallen too far behind to take it.
+func (b *Bus) deliver(event models.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			log.Printf("Dropping a subscriber that fell %d events behind, at %s event %d", subscriberBuffer, event.Type, event.ID)
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// List returns the stored events matching filter, oldest first.
+func (b *Bus) List(filter models.EventFilter) ([]models.Event, error) {
+	return b.store.ListEvents(filter)
+}
+
+// Subscribe returns the stored events matching filter followed by a channel
+// of newly published ones, with nothing missed or repeated in between. Call
+// the returned function to unsubscribe.
+//
+// A subscriber that falls more than subscriberBuffer events behind is
+// dropped: its channel is closed without the events it missed. It can catch
+// up from the store by subscribing again with AfterID set to the ID of the
+// last event it received.
+func (b *Bus) Subscribe(filter models.EventFilter) ([]models.Event, <-chan models.Event, func(), error) {
+	b.publishMu.Lock()
+	defer b.publishMu.Unlock()
+
+	backlog, err := b.store.ListEvents(filter)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	live := filter
+	live.Limit = 0
+	sub := &subscriber{filter: live, ch: make(chan models.Event, subscriberBuffer)}
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[sub]; ok {
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+	return backlog, sub.ch, cancel, nil
+}
@@ -0,0 +1,45 @@
+package events
+
+import (
+	"testing"
+
+	"podium/internal/models"
+	"podium/internal/store"
+)
+
+func TestSlowSubscriberIsDroppedAndCatchesUp(t *testing.T) {
+	bus := NewBus(store.NewMemoryStore(), 1000)
+	_, live, cancel, err := bus.Subscribe(models.EventFilter{})
+	if err != nil {
+		t.Fatalf("subscribing: %v", err)
+	}
+	defer cancel()
+
+	const published = subscriberBuffer + 10
+	for i := 0; i < published; i++ {
+		bus.Publish(models.Event{Type: models.EventContainerStarted})
+	}
+
+	var received []models.Event
+	for event := range live {
+		received = append(received, event)
+	}
+	if len(received) != subscriberBuffer {
+		t.Fatalf("got %d events before the channel closed, want %d", len(received), subscriberBuffer)
+	}
+
+	last := received[len(received)-1].ID
+	backlog, _, cancelAgain, err := bus.Subscribe(models.EventFilter{AfterID: last})
+	if err != nil {
+		t.Fatalf("subscribing again: %v", err)
+	}
+	defer cancelAgain()
+	if len(backlog) != published-subscriberBuffer {
+		t.Fatalf("got %d missed events, want %d", len(backlog), published-subscriberBuffer)
+	}
+	for i, event := range append(received, backlog...) {
+		if event.ID != uint64(i+1) {
+			t.Fatalf("event %d has ID %d, want events in ID order without gaps", i, event.ID)
+		}
+	}
+}
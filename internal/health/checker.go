@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"net/http"
 	"net/url"
@@ -12,28 +11,40 @@ import (
 	"time"
 
 	"podium/internal/models"
+	"podium/internal/runtime"
 )
 
-type Checker struct{}
+// maxCommandOutput caps how much command output is kept with a health result.
+const maxCommandOutput = 4096
 
-func NewChecker() *Checker {
-	return &Checker{}
+type Checker struct {
+	runtime runtime.Runtime
 }
 
-func (c *Checker) Check(ctx context.Context, container models.Container) (models.HealthStatus, error) {
+func NewChecker(runtime runtime.Runtime) *Checker {
+	return &Checker{
+		runtime: runtime,
+	}
+}
+
+// Check runs the container's health check. For command checks it also
+// returns the command's output.
+func (c *Checker) Check(ctx context.Context, container models.Container) (models.HealthStatus, string, error) {
 	if container.HealthCheck == nil {
-		return models.HealthStatusHealthy, nil
+		return models.HealthStatusHealthy, "", nil
 	}
 
 	switch container.HealthCheck.Type {
 	case models.HealthCheckTypeHTTP:
-		return c.checkHTTP(ctx, container)
+		status, err := c.checkHTTP(ctx, container)
+		return status, "", err
 	case models.HealthCheckTypeTCP:
-		return c.checkTCP(ctx, container)
+		status, err := c.checkTCP(ctx, container)
+		return status, "", err
 	case models.HealthCheckTypeCommand:
 		return c.checkCommand(ctx, container)
 	default:
-		return models.HealthStatusUnknown, fmt.Errorf("unsupported health check type: %s", container.HealthCheck.Type)
+		return models.HealthStatusUnknown, "", fmt.Errorf("unsupported health check type: %s", container.HealthCheck.Type)
 	}
 }
 
@@ -112,11 +123,30 @@ func (c *Checker) checkTCP(ctx context.Context, container models.Container) (mod
 	return models.HealthStatusHealthy, nil
 }
 
-func (c *Checker) checkCommand(ctx context.Context, container models.Container) (models.HealthStatus, error) {
-	// For command health checks, we would need to execute a command inside the container
-	// This is more complex and would require using the Docker exec API
-	// For simplicity, we'll just log that this is not implemented yet
-	//Hope you understand
-	log.Println("Command health check not implemented yet")
-	return models.HealthStatusUnknown, fmt.Errorf("command health check not implemented")
-}
\ No newline at end of file
+func (c *Checker) checkCommand(ctx context.Context, container models.Container) (models.HealthStatus, string, error) {
+	if len(container.HealthCheck.Command) == 0 {
+		return models.HealthStatusUnknown, "", fmt.Errorf("command health check requires a command")
+	}
+
+	if container.HealthCheck.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, container.HealthCheck.Timeout)
+		defer cancel()
+	}
+
+	result, err := c.runtime.Exec(ctx, container.ID, container.HealthCheck.Command)
+	if err != nil {
+		return models.HealthStatusUnhealthy, "", fmt.Errorf("command health check failed: %w", err)
+	}
+
+	output := result.Output
+	if len(output) > maxCommandOutput {
+		output = output[len(output)-maxCommandOutput:]
+	}
+
+	if result.ExitCode != 0 {
+		return models.HealthStatusUnhealthy, output, fmt.Errorf("command health check exited with code %d", result.ExitCode)
+	}
+
+	return models.HealthStatusHealthy, output, nil
+}
@@ -2,29 +2,39 @@ package health
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strconv"
 	"time"
 
+	"podium/internal/events"
+	"podium/internal/metrics"
 	"podium/internal/models"
 	"podium/internal/runtime"
 	"podium/internal/store"
 )
 
 type Worker struct {
-	store       *store.BoltStore
+	store       store.Store
 	runtime     runtime.Runtime
 	interval    time.Duration
 	maxRestarts int
+	events      *events.Bus
 	stopCh      chan struct{}
+	// restartLimited holds the containers already reported as having
+	// exhausted their restarts, so the event is published only once.
+	restartLimited map[string]bool
 }
 
-func NewWorker(store *store.BoltStore, runtime runtime.Runtime, interval time.Duration, maxRestarts int) *Worker {
+func NewWorker(store store.Store, runtime runtime.Runtime, bus *events.Bus, interval time.Duration, maxRestarts int) *Worker {
 	return &Worker{
 		store:       store,
 		runtime:     runtime,
 		interval:    interval,
 		maxRestarts: maxRestarts,
+		events:         bus,
 		stopCh:      make(chan struct{}),
+		restartLimited: make(map[string]bool),
 	}
 }
 
@@ -59,7 +69,18 @@ func (w *Worker) checkContainers() {
 		return
 	}
 	
-	checker := NewChecker()
+	// Forget the restart limits of containers that have been deleted.
+	current := make(map[string]bool, len(containers))
+	for _, container := range containers {
+		current[container.ID] = true
+	}
+	for id := range w.restartLimited {
+		if !current[id] {
+			delete(w.restartLimited, id)
+		}
+	}
+
+	checker := NewChecker(w.runtime)
 	
 	for _, container := range containers {
 		if container.State != models.ContainerStateRunning {
@@ -79,25 +100,53 @@ func (w *Worker) checkContainers() {
 		
 		if state != models.ContainerStateRunning {
 			log.Printf("Container %s is not running (state: %s)", container.ID, state)
+			w.events.Publish(models.Event{
+				Type:        models.EventContainerStopped,
+				ContainerID: container.ID,
+				ServiceID:   container.Labels["podium.service.id"],
+				Message:     fmt.Sprintf("Container is no longer running (state: %s)", state),
+				Attributes:  map[string]string{"state": string(state)},
+			})
 			
 			if container.RestartPolicy == "Always" || container.RestartPolicy == "OnFailure" {
-				w.restartContainer(container)
+				if w.restartContainer(&container) {
+					continue
+				}
 			} else {
 				log.Printf("Not restarting container %s due to restart policy: %s", container.ID, container.RestartPolicy)
 			}
+
+			// Record the exit so the container is not reported as stopped again
+			// on every pass.
+			container.State = state
+			now := time.Now()
+			container.FinishedAt = &now
+			if err := w.store.UpdateContainer(container); err != nil {
+				log.Printf("Failed to update container state: %v", err)
+			}
 			continue
 		}
 		
 		if container.HealthCheck != nil {
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			status, err := checker.Check(ctx, container)
+			timeout := 10 * time.Second
+			if container.HealthCheck.Timeout > timeout {
+				timeout = container.HealthCheck.Timeout
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			checkStart := time.Now()
+			status, output, err := checker.Check(ctx, container)
 			cancel()
+			metrics.ObserveHealthCheck(container.HealthCheck.Type, status, err, time.Since(checkStart))
 			
+			previousStatus := container.Health.Status
 			now := time.Now()
 			container.Health.LastChecked = now
+			container.Health.Output = output
+			container.Health.Error = ""
 			
 			if err != nil {
 				log.Printf("Health check failed for container %s: %v", container.ID, err)
+				container.Health.Error = err.Error()
 				container.Health.Status = models.HealthStatusUnhealthy
 				container.Health.LastFailure = now
 				container.Health.FailureCount++
@@ -108,7 +157,7 @@ func (w *Worker) checkContainers() {
 						container.ID, container.Health.ConsecutiveFail, container.HealthCheck.FailureThreshold)
 					
 					if container.RestartPolicy == "Always" || container.RestartPolicy == "OnFailure" {
-						w.restartContainer(container)
+						w.restartContainer(&container)
 					}
 				}
 			} else {
@@ -125,19 +174,48 @@ func (w *Worker) checkContainers() {
 			if err := w.store.UpdateContainer(container); err != nil {
 				log.Printf("Failed to update container health state: %v", err)
 			}
+
+			if container.Health.Status != previousStatus {
+				w.events.Publish(models.Event{
+					Type:        models.EventContainerHealthChanged,
+					ContainerID: container.ID,
+					ServiceID:   container.Labels["podium.service.id"],
+					Message:     fmt.Sprintf("Health changed from %s to %s", previousStatus, container.Health.Status),
+					Attributes: map[string]string{
+						"from": string(previousStatus),
+						"to":   string(container.Health.Status),
+					},
+				})
+			}
 		}
 	}
 	
 	log.Println("Container health checks completed")
 }
 
-func (w *Worker) restartContainer(container models.Container) {
+// restartContainer restarts the container and reports whether it is running
+// again. The restart is recorded in container as well as in the store, so
+// callers that store container afterwards keep the new restart count.
+func (w *Worker) restartContainer(container *models.Container) bool {
 	log.Printf("Restarting container: %s", container.ID)
 	
 	if w.maxRestarts > 0 && container.RestartCount >= w.maxRestarts {
 		log.Printf("Container %s has exceeded maximum restart count (%d/%d), not restarting", 
 			container.ID, container.RestartCount, w.maxRestarts)
-		return
+		if !w.restartLimited[container.ID] {
+			w.restartLimited[container.ID] = true
+			w.events.Publish(models.Event{
+				Type:        models.EventContainerRestartLimit,
+				ContainerID: container.ID,
+				ServiceID:   container.Labels["podium.service.id"],
+				Message:     fmt.Sprintf("Container reached the maximum of %d restarts and will not be restarted again", w.maxRestarts),
+				Attributes: map[string]string{
+					"restartCount": strconv.Itoa(container.RestartCount),
+					"maxRestarts":  strconv.Itoa(w.maxRestarts),
+				},
+			})
+		}
+		return false
 	}
 		
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -154,17 +232,27 @@ func (w *Worker) restartContainer(container models.Container) {
 	
 	if err != nil {
 		log.Printf("Error starting container %s: %v", container.ID, err)
-		return
+		return false
 	}
 	
 	container.State = models.ContainerStateRunning
 	container.RestartCount++
+	metrics.IncContainerRestarts()
 	now := time.Now()
 	container.StartedAt = &now
 	
-	if err := w.store.UpdateContainer(container); err != nil {
+	if err := w.store.UpdateContainer(*container); err != nil {
 		log.Printf("Failed to update container state after restart: %v", err)
 	}
 	
+	w.events.Publish(models.Event{
+		Type:        models.EventContainerRestarted,
+		ContainerID: container.ID,
+		ServiceID:   container.Labels["podium.service.id"],
+		Message:     fmt.Sprintf("Container restarted by the health check worker (restart count: %d)", container.RestartCount),
+		Attributes:  map[string]string{"restartCount": strconv.Itoa(container.RestartCount)},
+	})
+
 	log.Printf("Container %s restarted successfully (restart count: %d)", container.ID, container.RestartCount)
+	return true
 }
\ No newline at end of file
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"podium/internal/events"
+	"podium/internal/models"
+	"podium/internal/runtime"
+	"podium/internal/store"
+)
+
+func TestHealthCheckRestartsCountTowardsLimit(t *testing.T) {
+	ctx := context.Background()
+	stateStore := store.NewMemoryStore()
+	rt := runtime.NewFakeRuntime()
+	bus := events.NewBus(stateStore, 1000)
+
+	const image = "unhealthy:latest"
+	rt.InjectFault(image, runtime.FakeFault{ExecExitCode: 1})
+	if err := rt.PullImage(ctx, image, nil, nil); err != nil {
+		t.Fatalf("pulling image: %v", err)
+	}
+	container := models.Container{
+		ID:            "unhealthy",
+		Name:          "unhealthy",
+		Image:         image,
+		State:         models.ContainerStateRunning,
+		RestartPolicy: "Always",
+		HealthCheck: &models.HealthCheck{
+			Type:             models.HealthCheckTypeCommand,
+			Command:          []string{"check"},
+			FailureThreshold: 1,
+		},
+	}
+	if err := rt.CreateContainer(ctx, container); err != nil {
+		t.Fatalf("creating container: %v", err)
+	}
+	if err := rt.StartContainer(ctx, container.ID); err != nil {
+		t.Fatalf("starting container: %v", err)
+	}
+	if err := stateStore.CreateContainer(container); err != nil {
+		t.Fatalf("storing container: %v", err)
+	}
+
+	const maxRestarts = 2
+	worker := NewWorker(stateStore, rt, bus, time.Hour, maxRestarts)
+	for i := 0; i < maxRestarts+2; i++ {
+		worker.checkContainers()
+	}
+
+	stored, err := stateStore.GetContainer(container.ID)
+	if err != nil {
+		t.Fatalf("getting container: %v", err)
+	}
+	if stored.RestartCount != maxRestarts {
+		t.Errorf("restart count = %d, want %d", stored.RestartCount, maxRestarts)
+	}
+	limits, err := bus.List(models.EventFilter{Types: []models.EventType{models.EventContainerRestartLimit}})
+	if err != nil {
+		t.Fatalf("listing events: %v", err)
+	}
+	if len(limits) != 1 {
+		t.Errorf("got %d restart limit events, want 1", len(limits))
+	}
+
+	if err := stateStore.DeleteContainer(container.ID); err != nil {
+		t.Fatalf("deleting container: %v", err)
+	}
+	worker.checkContainers()
+	if worker.restartLimited[container.ID] {
+		t.Errorf("restart limit of deleted container %s is still tracked", container.ID)
+	}
+}
@@ -0,0 +1,331 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/google/uuid"
+	"podium/internal/models"
+	"podium/internal/runtime"
+	"podium/internal/store"
+)
+
+const (
+	pullTimeout        = 30 * time.Minute
+	operationRetention = time.Hour
+)
+
+type pullOperation struct {
+	op       models.Operation
+	done     chan struct{}
+	watchers map[chan models.Operation]struct{}
+}
+
+// Puller pulls images through the runtime according to a pull policy, using
+// the registry credentials kept in the store. Each pull runs in the
+// background as an operation that clients can poll or follow.
+type Puller struct {
+	runtime runtime.Runtime
+	store   store.Store
+
+	mu         sync.Mutex
+	operations map[string]*pullOperation
+	inFlight   map[string]string
+}
+
+func NewPuller(runtime runtime.Runtime, store store.Store) *Puller {
+	return &Puller{
+		runtime:    runtime,
+		store:      store,
+		operations: make(map[string]*pullOperation),
+		inFlight:   make(map[string]string),
+	}
+}
+
+// Pull starts pulling image if the policy requires it. It returns nil when no
+// pull is needed. Concurrent pulls of the same image share one operation.
+func (p *Puller) Pull(ctx context.Context, image, policy string) (*models.Operation, error) {
+	switch policy {
+	case "", models.PullPolicyIfNotPresent, models.PullPolicyAlways, models.PullPolicyNever:
+	default:
+		return nil, fmt.Errorf("invalid pull policy: %s", policy)
+	}
+
+	if policy != models.PullPolicyAlways {
+		exists, err := p.runtime.ImageExists(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, nil
+		}
+		if policy == models.PullPolicyNever {
+			return nil, fmt.Errorf("image %s is not present and pull policy is %s", image, models.PullPolicyNever)
+		}
+	}
+
+	auth, err := p.credentialFor(image)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pruneLocked()
+
+	if id, ok := p.inFlight[image]; ok {
+		op := copyOperation(p.operations[id].op)
+		return &op, nil
+	}
+
+	po := &pullOperation{
+		op: models.Operation{
+			ID:        uuid.New().String(),
+			Type:      "pull",
+			Target:    image,
+			State:     models.OperationStateRunning,
+			Layers:    map[string]models.LayerProgress{},
+			StartedAt: time.Now(),
+		},
+		done:     make(chan struct{}),
+		watchers: make(map[chan models.Operation]struct{}),
+	}
+	p.operations[po.op.ID] = po
+	p.inFlight[image] = po.op.ID
+
+	go p.run(po, auth)
+
+	op := copyOperation(po.op)
+	return &op, nil
+}
+
+// EnsureImage pulls image according to policy and waits for the pull to finish.
+func (p *Puller) EnsureImage(ctx context.Context, image, policy string) error {
+	op, err := p.Pull(ctx, image, policy)
+	if err != nil {
+		return err
+	}
+	if op == nil {
+		return nil
+	}
+
+	result, err := p.Wait(ctx, op.ID)
+	if err != nil {
+		return err
+	}
+	if result.State == models.OperationStateFailed {
+		return fmt.Errorf("failed to pull image %s: %s", image, result.Error)
+	}
+	return nil
+}
+
+// Get returns a snapshot of the operation with the given ID.
+func (p *Puller) Get(id string) (models.Operation, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	po, ok := p.operations[id]
+	if !ok {
+		return models.Operation{}, false
+	}
+	return copyOperation(po.op), true
+}
+
+// Wait blocks until the operation finishes or ctx is done.
+func (p *Puller) Wait(ctx context.Context, id string) (models.Operation, error) {
+	p.mu.Lock()
+	po, ok := p.operations[id]
+	p.mu.Unlock()
+	if !ok {
+		return models.Operation{}, fmt.Errorf("operation not found: %s", id)
+	}
+
+	select {
+	case <-po.done:
+	case <-ctx.Done():
+		return models.Operation{}, ctx.Err()
+	}
+
+	op, _ := p.Get(id)
+	return op, nil
+}
+
+// Watch returns a channel that receives a snapshot of the operation on every
+// update. The channel is closed once the operation has finished; the final
+// snapshot is always delivered. Call the returned function to stop watching.
+func (p *Puller) Watch(id string) (<-chan models.Operation, func(), error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	po, ok := p.operations[id]
+	if !ok {
+		return nil, nil, fmt.Errorf("operation not found: %s", id)
+	}
+
+	ch := make(chan models.Operation, 16)
+	ch <- copyOperation(po.op)
+
+	select {
+	case <-po.done:
+		close(ch)
+		return ch, func() {}, nil
+	default:
+	}
+
+	po.watchers[ch] = struct{}{}
+	cancel := func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if _, ok := po.watchers[ch]; ok {
+			delete(po.watchers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel, nil
+}
+
+func (p *Puller) run(po *pullOperation, auth *models.RegistryCredential) {
+	ctx, cancel := context.WithTimeout(context.Background(), pullTimeout)
+	defer cancel()
+
+	err := p.runtime.PullImage(ctx, po.op.Target, auth, func(progress runtime.PullProgress) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		po.op.Message = progress.Status
+		if progress.Layer != "" {
+			po.op.Layers[progress.Layer] = models.LayerProgress{
+				Status:  progress.Status,
+				Current: progress.Current,
+				Total:   progress.Total,
+			}
+		}
+		p.notifyLocked(po)
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	po.op.FinishedAt = &now
+	if err != nil {
+		log.Printf("Pull of image %s failed: %v", po.op.Target, err)
+		po.op.State = models.OperationStateFailed
+		po.op.Error = err.Error()
+	} else {
+		po.op.State = models.OperationStateSucceeded
+		po.op.Message = "Pull complete"
+	}
+
+	delete(p.inFlight, po.op.Target)
+	snapshot := copyOperation(po.op)
+	for ch := range po.watchers {
+		// Make room for the final state if the watcher has fallen behind.
+		select {
+		case ch <- snapshot:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- snapshot:
+			default:
+			}
+		}
+		close(ch)
+	}
+	po.watchers = map[chan models.Operation]struct{}{}
+	close(po.done)
+}
+
+// notifyLocked sends the current state of po to its watchers, dropping the
+// update for watchers that are not keeping up. The caller must hold p.mu.
+func (p *Puller) notifyLocked(po *pullOperation) {
+	snapshot := copyOperation(po.op)
+	for ch := range po.watchers {
+		select {
+		case ch <- snapshot:
+		default:
+		}
+	}
+}
+
+// pruneLocked forgets finished operations older than operationRetention.
+// The caller must hold p.mu.
+func (p *Puller) pruneLocked() {
+	for id, po := range p.operations {
+		if po.op.FinishedAt != nil && time.Since(*po.op.FinishedAt) > operationRetention {
+			delete(p.operations, id)
+		}
+	}
+}
+
+// credentialFor returns the credential stored for the registry image is
+// pulled from, or nil to pull it anonymously.
+func (p *Puller) credentialFor(image string) (*models.RegistryCredential, error) {
+	registry, err := RegistryHost(image)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := p.store.GetRegistryCredential(registry)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up credentials for registry %s: %w", registry, err)
+	}
+	return &credential, nil
+}
+
+// RegistryHost returns the registry an image reference is pulled from, for
+// example "docker.io" for "nginx:latest", normalized with NormalizeRegistry.
+func RegistryHost(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %s: %w", image, err)
+	}
+	return NormalizeRegistry(reference.Domain(named)), nil
+}
+
+// dockerHubHosts are the other names Docker Hub goes by.
+var dockerHubHosts = map[string]bool{
+	"index.docker.io":         true,
+	"registry-1.docker.io":    true,
+	"registry.hub.docker.com": true,
+}
+
+// NormalizeRegistry returns the name registry credentials are stored under:
+// the lower-cased host and port of the registry without a scheme or path,
+// and "docker.io" for any of Docker Hub's names. It accepts the forms people
+// copy from docker login, such as "https://index.docker.io/v1/".
+func NormalizeRegistry(registry string) string {
+	host := strings.ToLower(strings.TrimSpace(registry))
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+len("://"):]
+	}
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	if dockerHubHosts[host] {
+		return "docker.io"
+	}
+	return host
+}
+
+func copyOperation(op models.Operation) models.Operation {
+	layers := make(map[string]models.LayerProgress, len(op.Layers))
+	for k, v := range op.Layers {
+		layers[k] = v
+	}
+	op.Layers = layers
+	return op
+}
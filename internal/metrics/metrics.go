@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"podium/internal/models"
+)
+
+var (
+	healthChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "podium_health_checks_total",
+		Help: "Health checks run, by check type and result.",
+	}, []string{"type", "result"})
+
+	healthCheckDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "podium_health_check_duration_seconds",
+		Help:    "Time taken by health checks, by check type.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"type"})
+
+	containerRestarts = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "podium_container_restarts_total",
+		Help: "Containers restarted by the health check worker.",
+	})
+
+	reconcileDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "podium_reconcile_duration_seconds",
+		Help:    "Time taken by each service reconciliation loop.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 14),
+	})
+
+	reconcileErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "podium_reconcile_errors_total",
+		Help: "Service reconciliation loops that ended with an error.",
+	})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "podium_http_request_duration_seconds",
+		Help:    "API request latency, by route, method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+
+	storeOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "podium_store_operation_duration_seconds",
+		Help:    "Time taken by state store operations, by operation.",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 2, 14),
+	}, []string{"operation"})
+)
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveHealthCheck records the result and duration of one health check.
+func ObserveHealthCheck(checkType models.HealthCheckType, status models.HealthStatus, err error, duration time.Duration) {
+	result := string(status)
+	if err != nil {
+		result = "error"
+	}
+	healthChecks.WithLabelValues(string(checkType), result).Inc()
+	healthCheckDuration.WithLabelValues(string(checkType)).Observe(duration.Seconds())
+}
+
+// IncContainerRestarts counts a container restart.
+func IncContainerRestarts() {
+	containerRestarts.Inc()
+}
+
+// ObserveReconcile records the duration and outcome of a reconciliation loop.
+func ObserveReconcile(duration time.Duration, err error) {
+	reconcileDuration.Observe(duration.Seconds())
+	if err != nil {
+		reconcileErrors.Inc()
+	}
+}
+
+// ObserveStoreOperation records the time a store operation took since start.
+func ObserveStoreOperation(operation string, start time.Time) {
+	storeOperationDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+// Middleware records the latency of every request handled by the router,
+// labelled with the route's path template rather than the raw path.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		httpRequestDuration.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).
+			Observe(time.Since(start).Seconds())
+	})
+}
+
+// statusRecorder captures the status code written by a handler. It passes
+// Flush and Hijack through so streaming and WebSocket handlers keep working.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+var (
+	containersDesc = prometheus.NewDesc(
+		"podium_containers",
+		"Containers known to Podium, by state.",
+		[]string{"state"}, nil)
+
+	serviceRestartCountDesc = prometheus.NewDesc(
+		"podium_service_restart_count",
+		"Times the current containers of each service have been restarted, as recorded in the store. Containers that belong to no service are counted under an empty service.",
+		[]string{"service"}, nil)
+)
+
+var containerStates = []models.ContainerState{
+	models.ContainerStatePending,
+	models.ContainerStateRunning,
+	models.ContainerStateSucceeded,
+	models.ContainerStateFailed,
+}
+
+// containerCollector reads container counts from the store at scrape time.
+type containerCollector struct {
+	list func() ([]models.Container, error)
+}
+
+// RegisterContainerCollector exports container counts and restart counts
+// taken from list on every scrape. Restarts are summed per service rather
+// than exported per container, whose IDs change every time a replica is
+// recreated.
+func RegisterContainerCollector(list func() ([]models.Container, error)) {
+	prometheus.MustRegister(&containerCollector{list: list})
+}
+
+func (c *containerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- containersDesc
+	ch <- serviceRestartCountDesc
+}
+
+func (c *containerCollector) Collect(ch chan<- prometheus.Metric) {
+	containers, err := c.list()
+	if err != nil {
+		log.Printf("Error listing containers for metrics: %v", err)
+		return
+	}
+
+	counts := make(map[models.ContainerState]int, len(containerStates))
+	for _, state := range containerStates {
+		counts[state] = 0
+	}
+	restarts := make(map[string]int)
+	for _, container := range containers {
+		counts[container.State]++
+		restarts[container.Labels["podium.service.name"]] += container.RestartCount
+	}
+	for state, count := range counts {
+		ch <- prometheus.MustNewConstMetric(containersDesc, prometheus.GaugeValue, float64(count), string(state))
+	}
+	for service, count := range restarts {
+		ch <- prometheus.MustNewConstMetric(serviceRestartCountDesc, prometheus.GaugeValue, float64(count), service)
+	}
+}
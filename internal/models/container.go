@@ -27,6 +27,7 @@ type Container struct {
 	ID            string               `json:"id"`
 	Name          string               `json:"name"`
 	Image         string               `json:"image"`
+	PullPolicy    string               `json:"pullPolicy,omitempty"`
 	Command       []string             `json:"command,omitempty"`
 	Env           map[string]string    `json:"env,omitempty"`
 	Ports         []PortMapping        `json:"ports,omitempty"`
@@ -37,18 +38,30 @@ type Container struct {
 	StartedAt     *time.Time           `json:"startedAt,omitempty"`
 	FinishedAt    *time.Time           `json:"finishedAt,omitempty"`
 	RestartPolicy string               `json:"restartPolicy"`
+	Labels        map[string]string    `json:"labels,omitempty"`
 	HealthCheck *HealthCheck `json:"healthCheck,omitempty"`
 	Health      HealthState  `json:"health,omitempty"`
 	RestartCount int         `json:"restartCount"`
+	// DNS and DNSSearch replace the name servers and search domains the
+	// container resolves names with.
+	DNS       []string `json:"dns,omitempty"`
+	DNSSearch []string `json:"dnsSearch,omitempty"`
+	// Networks are the networks the container is attached to. Without any,
+	// it uses the runtime's default network.
+	Networks []NetworkAttachment `json:"networks,omitempty"`
+	Mounts   []Mount             `json:"mounts,omitempty"`
 }
 
 type ContainerCreateRequest struct {
 	Name          string               `json:"name"`
 	Image         string               `json:"image"`
+	PullPolicy    string               `json:"pullPolicy,omitempty"`
 	Command       []string             `json:"command,omitempty"`
 	Env           map[string]string    `json:"env,omitempty"`
 	Ports         []PortMapping        `json:"ports,omitempty"`
 	Resources     ResourceRequirements `json:"resources"`
 	RestartPolicy string               `json:"restartPolicy"`
 	HealthCheck *HealthCheck `json:"healthCheck,omitempty"`
+	Networks      []NetworkAttachment  `json:"networks,omitempty"`
+	Mounts        []Mount              `json:"mounts,omitempty"`
 }
\ No newline at end of file
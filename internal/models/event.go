@@ -0,0 +1,105 @@
+package models
+
+import "time"
+
+type EventType string
+
+const (
+	EventContainerCreated         EventType = "container.created"
+	EventContainerStarted         EventType = "container.started"
+	EventContainerStopped         EventType = "container.stopped"
+	EventContainerRestarted       EventType = "container.restarted"
+	EventContainerHealthChanged   EventType = "container.health_changed"
+	EventContainerRestartLimit    EventType = "container.restart_limit_reached"
+	EventServiceScaled            EventType = "service.scaled"
+	EventServiceReplicaRecreated  EventType = "service.replica_recreated"
+	EventServiceReconcileFailed   EventType = "service.reconcile_failed"
+	EventServiceUpdateStarted     EventType = "service.update_started"
+	EventServiceUpdateCompleted   EventType = "service.update_completed"
+	EventServiceUpdatePaused      EventType = "service.update_paused"
+	EventServiceUpdateRollingBack EventType = "service.update_rolling_back"
+	EventServiceUpdateRolledBack  EventType = "service.update_rolled_back"
+	EventServiceCanaryStarted     EventType = "service.canary_started"
+	EventServiceCanaryPromoted    EventType = "service.canary_promoted"
+
+	// EventWebhookTest is only sent by the webhook test endpoint and is
+	// never stored.
+	EventWebhookTest EventType = "webhook.test"
+)
+
+// EventTypes lists the event types published by Podium.
+var EventTypes = []EventType{
+	EventContainerCreated,
+	EventContainerStarted,
+	EventContainerStopped,
+	EventContainerRestarted,
+	EventContainerHealthChanged,
+	EventContainerRestartLimit,
+	EventServiceScaled,
+	EventServiceReplicaRecreated,
+	EventServiceReconcileFailed,
+	EventServiceUpdateStarted,
+	EventServiceUpdateCompleted,
+	EventServiceUpdatePaused,
+	EventServiceUpdateRollingBack,
+	EventServiceUpdateRolledBack,
+	EventServiceCanaryStarted,
+	EventServiceCanaryPromoted,
+}
+
+// Event records a lifecycle change of a container or service. IDs increase
+// monotonically, so they can be used to resume a stream.
+type Event struct {
+	ID          uint64            `json:"id"`
+	Type        EventType         `json:"type"`
+	Timestamp   time.Time         `json:"timestamp"`
+	ContainerID string            `json:"containerId,omitempty"`
+	ServiceID   string            `json:"serviceId,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+}
+
+// EventFilter selects events. Zero-valued fields match every event.
+type EventFilter struct {
+	Types       []EventType
+	ContainerID string
+	ServiceID   string
+	Since       time.Time
+	Until       time.Time
+	// AfterID matches only events with a greater ID.
+	AfterID uint64
+	// Limit keeps only the most recent matching events when positive.
+	Limit int
+}
+
+// Matches reports whether event is selected by the filter, ignoring Limit.
+func (f EventFilter) Matches(event Event) bool {
+	if event.ID <= f.AfterID {
+		return false
+	}
+	if len(f.Types) > 0 {
+		found := false
+		for _, t := range f.Types {
+			if t == event.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.ContainerID != "" && f.ContainerID != event.ContainerID {
+		return false
+	}
+	if f.ServiceID != "" && f.ServiceID != event.ServiceID {
+		return false
+	}
+	if !f.Since.IsZero() && event.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && event.Timestamp.After(f.Until) {
+		return false
+	}
+	return true
+}
@@ -38,4 +38,6 @@ type HealthState struct {
 	SuccessCount    int          `json:"successCount"`
 	FailureCount    int          `json:"failureCount"`
 	ConsecutiveFail int          `json:"consecutiveFail"`
+	Output          string       `json:"output,omitempty"`
+	Error           string       `json:"error,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const (
+	PullPolicyAlways       = "Always"
+	PullPolicyIfNotPresent = "IfNotPresent"
+	PullPolicyNever        = "Never"
+)
+
+type RegistryCredential struct {
+	Registry  string    `json:"registry"`
+	Username  string    `json:"username"`
+	Password  string    `json:"password,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type OperationState string
+
+const (
+	OperationStateRunning   OperationState = "running"
+	OperationStateSucceeded OperationState = "succeeded"
+	OperationStateFailed    OperationState = "failed"
+)
+
+type LayerProgress struct {
+	Status  string `json:"status"`
+	Current int64  `json:"current,omitempty"`
+	Total   int64  `json:"total,omitempty"`
+}
+
+// Operation tracks a long-running task, such as an image pull, that clients
+// can poll or follow while it runs.
+type Operation struct {
+	ID         string                   `json:"id"`
+	Type       string                   `json:"type"`
+	Target     string                   `json:"target"`
+	State      OperationState           `json:"state"`
+	Message    string                   `json:"message,omitempty"`
+	Layers     map[string]LayerProgress `json:"layers,omitempty"`
+	Error      string                   `json:"error,omitempty"`
+	StartedAt  time.Time                `json:"startedAt"`
+	FinishedAt *time.Time               `json:"finishedAt,omitempty"`
+}
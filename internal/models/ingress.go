@@ -0,0 +1,20 @@
+package models
+
+// IngressRule routes HTTP requests for a host name and path prefix to the
+// service through Podium's ingress. Unlike a load balancer, ingress rules
+// are not part of the service's spec: changing them takes effect without
+// replacing any replicas.
+type IngressRule struct {
+	// Host is the host name the rule matches, such as "app.example.com". A
+	// leading "*." matches any single label, as in "*.example.com".
+	Host string `json:"host"`
+	// PathPrefix limits the rule to request paths under it. It defaults to
+	// "/"; when several rules match, the longest prefix wins.
+	PathPrefix string `json:"pathPrefix,omitempty"`
+	// Port is the container port requests are sent to. It defaults to the
+	// service's first port.
+	Port int `json:"port,omitempty"`
+	// TLS serves the host over HTTPS and redirects plain HTTP requests to
+	// it.
+	TLS bool `json:"tls,omitempty"`
+}
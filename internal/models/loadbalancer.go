@@ -0,0 +1,20 @@
+package models
+
+const (
+	LoadBalancerRoundRobin       = "round-robin"
+	LoadBalancerLeastConnections = "least-connections"
+
+	LoadBalancerProtocolTCP  = "tcp"
+	LoadBalancerProtocolHTTP = "http"
+)
+
+// LoadBalancer has Podium's built-in proxy listen on the service's host
+// ports and spread traffic across its healthy replicas. Without one, only
+// replica 0 publishes the host ports.
+type LoadBalancer struct {
+	// Algorithm is "round-robin" (the default) or "least-connections".
+	Algorithm string `json:"algorithm,omitempty"`
+	// Protocol is "tcp" (the default), which balances connections, or
+	// "http", which balances requests.
+	Protocol string `json:"protocol,omitempty"`
+}
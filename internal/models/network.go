@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+const NetworkDriverBridge = "bridge"
+
+// Network is an isolated bridge network that containers and service replicas
+// can attach to. Containers on the same network reach each other by name and
+// alias; containers on different networks cannot reach each other at all.
+type Network struct {
+	Name   string `json:"name"`
+	Driver string `json:"driver"`
+	// Subnet is the network's address range in CIDR notation. The runtime
+	// picks one if it is empty.
+	Subnet string `json:"subnet,omitempty"`
+	// Internal networks have no route to the outside world.
+	Internal bool              `json:"internal,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	// Auto is set on networks Podium created because a container or service
+	// was attached to a network that did not exist. They are removed once
+	// nothing is attached to them any more.
+	Auto      bool      `json:"auto,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NetworkAttachment attaches a container or the replicas of a service to a
+// network.
+type NetworkAttachment struct {
+	Network string `json:"network"`
+	// Aliases are extra names the container is reachable by on the network.
+	// Service replicas are also reachable by the service's name.
+	Aliases []string `json:"aliases,omitempty"`
+}
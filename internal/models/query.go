@@ -0,0 +1,82 @@
+package models
+
+import "fmt"
+
+// ContainerSortFields are the fields containers can be sorted by.
+var ContainerSortFields = []string{"id", "name", "state", "createdAt"}
+
+// ServiceSortFields are the fields services can be sorted by.
+var ServiceSortFields = []string{"id", "name", "state", "createdAt", "updatedAt"}
+
+// ListOptions sorts and pages a list. Records are sorted by Sort, or by ID
+// when it is empty, and records that sort equally are kept in ID order.
+type ListOptions struct {
+	Sort       string
+	Descending bool
+	// Limit caps the number of records returned when positive.
+	Limit  int
+	Offset int
+}
+
+// Validate checks that the list can be sorted by Sort, one of fields, and
+// that Limit and Offset are not negative.
+func (o ListOptions) Validate(fields []string) error {
+	if o.Limit < 0 || o.Offset < 0 {
+		return fmt.Errorf("limit and offset cannot be negative")
+	}
+	if o.Sort == "" {
+		return nil
+	}
+	for _, field := range fields {
+		if field == o.Sort {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot sort by %s", o.Sort)
+}
+
+// ContainerFilter selects containers. Zero-valued fields match every
+// container.
+type ContainerFilter struct {
+	State     ContainerState
+	Name      string
+	ServiceID string
+	// Labels matches containers that have every one of the labels.
+	Labels map[string]string
+}
+
+// Matches reports whether container is selected by the filter.
+func (f ContainerFilter) Matches(container Container) bool {
+	if f.State != "" && f.State != container.State {
+		return false
+	}
+	if f.Name != "" && f.Name != container.Name {
+		return false
+	}
+	if f.ServiceID != "" && f.ServiceID != container.Labels["podium.service.id"] {
+		return false
+	}
+	for key, value := range f.Labels {
+		if actual, ok := container.Labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ServiceFilter selects services. Zero-valued fields match every service.
+type ServiceFilter struct {
+	State ServiceState
+	Name  string
+}
+
+// Matches reports whether service is selected by the filter.
+func (f ServiceFilter) Matches(service Service) bool {
+	if f.State != "" && f.State != service.State {
+		return false
+	}
+	if f.Name != "" && f.Name != service.Name {
+		return false
+	}
+	return true
+}
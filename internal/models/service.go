@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
 
 
 type ServiceState string
@@ -16,6 +21,7 @@ const (
 	ServiceStateFailed ServiceState = "failed"
 )
 
+
 type Service struct {
 	ID            string               `json:"id"`
 	Name          string               `json:"name"`
@@ -29,7 +35,33 @@ type Service struct {
 	CreatedAt     time.Time            `json:"createdAt"`
 	UpdatedAt     time.Time            `json:"updatedAt"`
 	RestartPolicy string               `json:"restartPolicy"`
-	ContainerIDs  []string             `json:"containerIds,omitempty"`
+	HealthCheck   *HealthCheck         `json:"healthCheck,omitempty"`
+	LoadBalancer  *LoadBalancer        `json:"loadBalancer,omitempty"`
+	Networks      []NetworkAttachment  `json:"networks,omitempty"`
+	Mounts        []Mount              `json:"mounts,omitempty"`
+	UpdateConfig  *UpdateConfig        `json:"updateConfig,omitempty"`
+	// Revision is the number of the stored revision whose spec the service
+	// runs.
+	Revision int `json:"revision,omitempty"`
+	// LastHealthyRevision is the latest revision a rolling update finished
+	// deploying with every replica healthy.
+	LastHealthyRevision int           `json:"lastHealthyRevision,omitempty"`
+	UpdateStatus        *UpdateStatus `json:"updateStatus,omitempty"`
+	ContainerIDs        []string      `json:"containerIds,omitempty"`
+	// Standby holds the replicas a blue-green update cut over from, kept
+	// for a while so the service can switch back to them.
+	Standby *StandbyReplicas `json:"standby,omitempty"`
+	// Ingress routes requests for host names and paths to the service.
+	Ingress []IngressRule `json:"ingress,omitempty"`
+}
+
+// StandbyReplicas is the previous ("blue") set of replicas left behind by a
+// blue-green update. The replica publishing the service's host ports is
+// stopped; the others keep running.
+type StandbyReplicas struct {
+	Revision     int       `json:"revision"`
+	ContainerIDs []string  `json:"containerIds"`
+	ExpiresAt    time.Time `json:"expiresAt"`
 }
 
 type ServiceCreateRequest struct {
@@ -41,8 +73,185 @@ type ServiceCreateRequest struct {
 	Resources     ResourceRequirements `json:"resources"`
 	Replicas      int                  `json:"replicas"`
 	RestartPolicy string               `json:"restartPolicy"`
+	HealthCheck   *HealthCheck         `json:"healthCheck,omitempty"`
+	LoadBalancer  *LoadBalancer        `json:"loadBalancer,omitempty"`
+	Networks      []NetworkAttachment  `json:"networks,omitempty"`
+	Mounts        []Mount              `json:"mounts,omitempty"`
+	UpdateConfig  *UpdateConfig        `json:"updateConfig,omitempty"`
+	Ingress       []IngressRule        `json:"ingress,omitempty"`
 }
 
 type ServiceScaleRequest struct {
 	Replicas int `json:"replicas"`
-}
\ No newline at end of file
+}
+
+// ServiceSpec is the part of a service that its replicas are created from.
+// Changing it requires replacing the replicas.
+type ServiceSpec struct {
+	Image         string               `json:"image"`
+	Command       []string             `json:"command,omitempty"`
+	Env           map[string]string    `json:"env,omitempty"`
+	Ports         []PortMapping        `json:"ports,omitempty"`
+	Resources     ResourceRequirements `json:"resources"`
+	RestartPolicy string               `json:"restartPolicy"`
+	HealthCheck   *HealthCheck         `json:"healthCheck,omitempty"`
+	LoadBalancer  *LoadBalancer        `json:"loadBalancer,omitempty"`
+	Networks      []NetworkAttachment  `json:"networks,omitempty"`
+	Mounts        []Mount              `json:"mounts,omitempty"`
+}
+
+// Hash identifies the spec. Replicas are labelled with the hash of the spec
+// they were created from.
+func (s ServiceSpec) Hash() string {
+	data, _ := json.Marshal(s)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func (s *Service) Spec() ServiceSpec {
+	return ServiceSpec{
+		Image:         s.Image,
+		Command:       s.Command,
+		Env:           s.Env,
+		Ports:         s.Ports,
+		Resources:     s.Resources,
+		RestartPolicy: s.RestartPolicy,
+		HealthCheck:   s.HealthCheck,
+		LoadBalancer:  s.LoadBalancer,
+		Networks:      s.Networks,
+		Mounts:        s.Mounts,
+	}
+}
+
+func (s *Service) SetSpec(spec ServiceSpec) {
+	s.Image = spec.Image
+	s.Command = spec.Command
+	s.Env = spec.Env
+	s.Ports = spec.Ports
+	s.Resources = spec.Resources
+	s.RestartPolicy = spec.RestartPolicy
+	s.HealthCheck = spec.HealthCheck
+	s.LoadBalancer = spec.LoadBalancer
+	s.Networks = spec.Networks
+	s.Mounts = spec.Mounts
+}
+
+// ServiceRevision is an immutable record of a spec a service has been
+// deployed with. Revisions are numbered from 1 for each service.
+type ServiceRevision struct {
+	ServiceID string      `json:"serviceId"`
+	Revision  int         `json:"revision"`
+	Spec      ServiceSpec `json:"spec"`
+	SpecHash  string      `json:"specHash"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+const (
+	UpdateFailureActionPause    = "pause"
+	UpdateFailureActionRollback = "rollback"
+)
+
+const (
+	// UpdateStrategyRolling replaces replicas a batch at a time.
+	UpdateStrategyRolling = "rolling"
+	// UpdateStrategyBlueGreen starts a complete new set of replicas next to
+	// the old one and cuts over to it once every new replica is healthy.
+	UpdateStrategyBlueGreen = "blue-green"
+	// UpdateStrategyCanary replaces a few replicas and waits for the canary
+	// to be promoted before replacing the rest.
+	UpdateStrategyCanary = "canary"
+)
+
+// UpdateConfig controls how a service's replicas are replaced when its spec
+// changes.
+type UpdateConfig struct {
+	// Strategy is "rolling" (the default), "blue-green" or "canary".
+	// MaxSurge and MaxUnavailable apply to rolling and canary updates.
+	Strategy string `json:"strategy,omitempty"`
+	// MaxSurge is how many replicas may run above the desired count while
+	// updating.
+	MaxSurge int `json:"maxSurge"`
+	// MaxUnavailable is how many replicas may be taken down before their
+	// replacements are healthy.
+	MaxUnavailable int `json:"maxUnavailable"`
+	// HealthyTimeout is how long a new replica has to pass its health check.
+	HealthyTimeout time.Duration `json:"healthyTimeout,omitempty"`
+	// ProgressDeadline is how long the update may go without a batch of
+	// replicas becoming healthy before it is treated as failed.
+	ProgressDeadline time.Duration `json:"progressDeadline,omitempty"`
+	// FailureAction is what happens when the update fails: "pause" (the
+	// default) or "rollback", which reverts the service to its last healthy
+	// revision.
+	FailureAction string `json:"failureAction,omitempty"`
+	// StandbyRetention is how long a blue-green update keeps the previous
+	// replicas after cutting over.
+	StandbyRetention time.Duration `json:"standbyRetention,omitempty"`
+	// CanaryWeight is the percentage of traffic sent to the canary replicas.
+	CanaryWeight int `json:"canaryWeight,omitempty"`
+	// CanaryReplicas is how many replicas a canary update replaces before
+	// waiting to be promoted. By default it is CanaryWeight percent of the
+	// replicas, and at least one.
+	CanaryReplicas int `json:"canaryReplicas,omitempty"`
+}
+
+// DefaultUpdateConfig replaces one replica at a time, starting each
+// replacement before its predecessor is removed.
+func DefaultUpdateConfig() UpdateConfig {
+	return UpdateConfig{
+		Strategy:         UpdateStrategyRolling,
+		MaxSurge:         1,
+		MaxUnavailable:   0,
+		HealthyTimeout:   2 * time.Minute,
+		ProgressDeadline: 10 * time.Minute,
+		FailureAction:    UpdateFailureActionPause,
+		StandbyRetention: 15 * time.Minute,
+		CanaryWeight:     10,
+	}
+}
+
+type UpdateState string
+
+const (
+	UpdateStateUpdating    UpdateState = "updating"
+	UpdateStatePaused      UpdateState = "paused"
+	UpdateStateCompleted   UpdateState = "completed"
+	UpdateStateRollingBack UpdateState = "rolling_back"
+	UpdateStateRolledBack  UpdateState = "rolled_back"
+
+	// UpdateStateCanary means the canary replicas are running and the update
+	// is waiting to be promoted or aborted.
+	UpdateStateCanary UpdateState = "canary"
+)
+
+// UpdateStatus tracks the progress of the latest rolling update of a service.
+type UpdateStatus struct {
+	State   UpdateState `json:"state"`
+	Message string      `json:"message,omitempty"`
+	// PreviousSpec is the spec the service ran before the update. It is
+	// rolled back to when there is no healthy revision to return to.
+	PreviousSpec     *ServiceSpec `json:"previousSpec,omitempty"`
+	PreviousRevision int          `json:"previousRevision,omitempty"`
+	// Rollback is set once the update is rolling back, and RollbackReason
+	// says why.
+	Rollback        bool      `json:"rollback,omitempty"`
+	RollbackReason  string    `json:"rollbackReason,omitempty"`
+	UpdatedReplicas int       `json:"updatedReplicas"`
+	StartedAt       time.Time `json:"startedAt"`
+	// LastProgressAt is when the update started or last had a batch of
+	// replicas become healthy.
+	LastProgressAt time.Time  `json:"lastProgressAt"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+	// Promoted is set once a canary update has been promoted to every
+	// replica.
+	Promoted bool `json:"promoted,omitempty"`
+}
+
+// InProgress reports whether the update has yet to finish, including when it
+// is paused.
+func (u *UpdateStatus) InProgress() bool {
+	if u == nil {
+		return false
+	}
+	return u.State == UpdateStateUpdating || u.State == UpdateStateRollingBack || u.State == UpdateStatePaused ||
+		u.State == UpdateStateCanary
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ContainerStats is a sample of a container's resource usage. Counters are
+// cumulative since the container started; CPUPercent is derived from the
+// previous sample and may exceed 100 when more than one CPU is in use.
+type ContainerStats struct {
+	ContainerID     string    `json:"containerId"`
+	Timestamp       time.Time `json:"timestamp"`
+	CPUPercent      float64   `json:"cpuPercent"`
+	CPUUsageNanos   uint64    `json:"cpuUsageNanos"`
+	OnlineCPUs      int       `json:"onlineCpus,omitempty"`
+	MemoryUsage     uint64    `json:"memoryUsage"`
+	MemoryLimit     uint64    `json:"memoryLimit,omitempty"`
+	NetworkRxBytes  uint64    `json:"networkRxBytes"`
+	NetworkTxBytes  uint64    `json:"networkTxBytes"`
+	BlockReadBytes  uint64    `json:"blockReadBytes"`
+	BlockWriteBytes uint64    `json:"blockWriteBytes"`
+}
+
+// CalculateCPUPercent sets CPUPercent from the CPU time used since prev.
+func (s *ContainerStats) CalculateCPUPercent(prev ContainerStats) {
+	elapsed := s.Timestamp.Sub(prev.Timestamp)
+	if elapsed <= 0 || s.CPUUsageNanos < prev.CPUUsageNanos {
+		s.CPUPercent = 0
+		return
+	}
+	s.CPUPercent = float64(s.CPUUsageNanos-prev.CPUUsageNanos) / float64(elapsed.Nanoseconds()) * 100
+}
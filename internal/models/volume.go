@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+const (
+	VolumeDriverLocal = "local"
+
+	MountTypeVolume = "volume"
+	MountTypeBind   = "bind"
+	MountTypeTmpfs  = "tmpfs"
+
+	// QuiescePause and QuiesceStop are how the containers mounting a volume
+	// are kept from writing to it while it is backed up or restored.
+	QuiescePause = "pause"
+	QuiesceStop  = "stop"
+)
+
+// Volume is a named store of data that outlives the containers mounting it.
+type Volume struct {
+	Name   string            `json:"name"`
+	Driver string            `json:"driver"`
+	Labels map[string]string `json:"labels,omitempty"`
+	// Mountpoint is where the runtime keeps the volume's data on the host.
+	Mountpoint string `json:"mountpoint,omitempty"`
+	// Auto is set on volumes Podium created because a container or service
+	// mounted a volume that did not exist.
+	Auto bool `json:"auto,omitempty"`
+	// BackupSchedule, if set, makes Podium back the volume up on its own.
+	BackupSchedule *VolumeBackupSchedule `json:"backupSchedule,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+}
+
+// VolumeBackupSchedule backs a volume up to the backup directory at a fixed
+// interval.
+type VolumeBackupSchedule struct {
+	Interval time.Duration `json:"interval"`
+	// Retain is how many of the volume's backups are kept. Older ones are
+	// removed after each scheduled backup.
+	Retain int `json:"retain"`
+	// Quiesce is "pause", "stop" or empty to leave the containers mounting
+	// the volume running.
+	Quiesce string `json:"quiesce,omitempty"`
+}
+
+// VolumeBackup is a backup archive of a volume in the backup directory.
+type VolumeBackup struct {
+	Name      string    `json:"name"`
+	Volume    string    `json:"volume"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Mount makes a volume, a host directory or a tmpfs available inside a
+// container.
+type Mount struct {
+	// Type is "volume" (the default), "bind" or "tmpfs".
+	Type string `json:"type,omitempty"`
+	// Source is the volume name for volume mounts and the absolute host path
+	// for bind mounts. tmpfs mounts have none.
+	Source   string `json:"source,omitempty"`
+	Target   string `json:"target"`
+	ReadOnly bool   `json:"readOnly,omitempty"`
+	// TmpfsSize limits the size of a tmpfs mount in bytes. Zero means the
+	// runtime's default.
+	TmpfsSize int64 `json:"tmpfsSize,omitempty"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Webhook subscribes a URL to lifecycle events. Each delivery is a JSON POST
+// signed with Secret.
+type Webhook struct {
+	ID     string `json:"id"`
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+	// Events lists the event types delivered to the webhook. An empty list
+	// subscribes to every event.
+	Events      []EventType `json:"events,omitempty"`
+	Enabled     bool        `json:"enabled"`
+	Description string      `json:"description,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
+
+// Subscribes reports whether events of type t are delivered to the webhook.
+func (w Webhook) Subscribes(t EventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, event := range w.Events {
+		if event == t {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryState string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryState = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryState = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryState = "failed"
+)
+
+// WebhookDelivery records the attempts made to deliver one event to a
+// webhook. IDs increase monotonically across all webhooks.
+type WebhookDelivery struct {
+	ID          uint64               `json:"id"`
+	WebhookID   string               `json:"webhookId"`
+	EventID     uint64               `json:"eventId,omitempty"`
+	EventType   EventType            `json:"eventType"`
+	State       WebhookDeliveryState `json:"state"`
+	Attempts    int                  `json:"attempts"`
+	StatusCode  int                  `json:"statusCode,omitempty"`
+	Error       string               `json:"error,omitempty"`
+	Test        bool                 `json:"test,omitempty"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	CompletedAt *time.Time           `json:"completedAt,omitempty"`
+}
@@ -0,0 +1,303 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"podium/internal/models"
+	"podium/internal/runtime"
+	"podium/internal/store"
+)
+
+// ErrNetworkInUse is returned when deleting a network that containers or
+// services are still attached to.
+var ErrNetworkInUse = errors.New("network is in use")
+
+// reservedNames are the runtime's own networks, which cannot be managed.
+var reservedNames = map[string]bool{"bridge": true, "host": true, "none": true, "default": true}
+
+// Manager keeps the networks in the store and the runtime in step, and
+// removes networks it created automatically once nothing is attached to
+// them.
+type Manager struct {
+	store    store.Store
+	runtime  runtime.Runtime
+	interval time.Duration
+	stopCh   chan struct{}
+
+	// mu serialises creating and removing networks.
+	mu sync.Mutex
+}
+
+// Usage lists what is attached to a network.
+type Usage struct {
+	Containers []string `json:"containers"`
+	Services   []string `json:"services"`
+}
+
+func (u Usage) InUse() bool {
+	return len(u.Containers) > 0 || len(u.Services) > 0
+}
+
+func NewManager(store store.Store, runtime runtime.Runtime, interval time.Duration) *Manager {
+	return &Manager{
+		store:    store,
+		runtime:  runtime,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+func (m *Manager) Start() {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stopCh:
+				log.Println("Network manager stopped")
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+				if _, err := m.Prune(ctx, true); err != nil {
+					log.Printf("Error pruning networks: %v", err)
+				}
+				cancel()
+			}
+		}
+	}()
+	log.Println("Network manager started")
+}
+
+func (m *Manager) Stop() {
+	close(m.stopCh)
+}
+
+// CreateNetwork creates a network in the runtime and stores it.
+func (m *Manager) CreateNetwork(ctx context.Context, network *models.Network) error {
+	if network.Driver == "" {
+		network.Driver = models.NetworkDriverBridge
+	}
+	if err := ValidateNetwork(*network); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.store.GetNetwork(network.Name); err == nil {
+		return fmt.Errorf("network %s already exists", network.Name)
+	}
+	return m.createLocked(ctx, network)
+}
+
+func (m *Manager) createLocked(ctx context.Context, network *models.Network) error {
+	network.CreatedAt = time.Now()
+	if err := m.runtime.CreateNetwork(ctx, *network); err != nil {
+		return err
+	}
+	if err := m.store.SaveNetwork(*network); err != nil {
+		if deleteErr := m.runtime.DeleteNetwork(ctx, network.Name); deleteErr != nil {
+			log.Printf("Cleanup failed: could not remove network %s: %v", network.Name, deleteErr)
+		}
+		return err
+	}
+	log.Printf("Network %s created", network.Name)
+	return nil
+}
+
+// EnsureNetworks creates the networks of the attachments that do not exist
+// yet. They are marked as automatic and removed again once unused.
+func (m *Manager) EnsureNetworks(ctx context.Context, attachments []models.NetworkAttachment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, attachment := range attachments {
+		if _, err := m.store.GetNetwork(attachment.Network); err == nil {
+			continue
+		}
+		network := &models.Network{Name: attachment.Network, Driver: models.NetworkDriverBridge, Auto: true}
+		if err := m.createLocked(ctx, network); err != nil {
+			return fmt.Errorf("failed to create network %s: %w", attachment.Network, err)
+		}
+	}
+	return nil
+}
+
+// DeleteNetwork removes a network nothing is attached to.
+func (m *Manager) DeleteNetwork(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.store.GetNetwork(name); err != nil {
+		return err
+	}
+	usage, err := m.Usage(name)
+	if err != nil {
+		return err
+	}
+	if usage.InUse() {
+		return fmt.Errorf("%w: %d containers and %d services are attached to network %s",
+			ErrNetworkInUse, len(usage.Containers), len(usage.Services), name)
+	}
+	return m.deleteLocked(ctx, name)
+}
+
+func (m *Manager) deleteLocked(ctx context.Context, name string) error {
+	if err := m.runtime.DeleteNetwork(ctx, name); err != nil {
+		return err
+	}
+	if err := m.store.DeleteNetwork(name); err != nil {
+		return err
+	}
+	log.Printf("Network %s removed", name)
+	return nil
+}
+
+// Prune removes the networks nothing is attached to and returns their
+// names. With autoOnly, only networks created automatically are removed,
+// and only once they are older than the prune interval, so a network is not
+// removed between being created for a container and the container being
+// stored.
+func (m *Manager) Prune(ctx context.Context, autoOnly bool) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	networks, err := m.store.ListNetworks()
+	if err != nil {
+		return nil, err
+	}
+	usages, err := m.usages()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, network := range networks {
+		if usages[network.Name].InUse() {
+			continue
+		}
+		if autoOnly && (!network.Auto || time.Since(network.CreatedAt) < m.interval) {
+			continue
+		}
+		if err := m.deleteLocked(ctx, network.Name); err != nil {
+			log.Printf("Error removing unused network %s: %v", network.Name, err)
+			continue
+		}
+		removed = append(removed, network.Name)
+	}
+	return removed, nil
+}
+
+// Usage returns what is attached to a network.
+func (m *Manager) Usage(name string) (Usage, error) {
+	usages, err := m.usages()
+	if err != nil {
+		return Usage{}, err
+	}
+	usage := usages[name]
+	if usage.Containers == nil {
+		usage.Containers = []string{}
+	}
+	if usage.Services == nil {
+		usage.Services = []string{}
+	}
+	return usage, nil
+}
+
+// usages maps each network name to what is attached to it. Services count
+// even with no replicas, since replicas they create later attach to their
+// networks.
+func (m *Manager) usages() (map[string]Usage, error) {
+	containers, err := m.store.ListContainers()
+	if err != nil {
+		return nil, err
+	}
+	services, err := m.store.ListServices()
+	if err != nil {
+		return nil, err
+	}
+
+	usages := map[string]Usage{}
+	for _, container := range containers {
+		for _, attachment := range container.Networks {
+			usage := usages[attachment.Network]
+			usage.Containers = append(usage.Containers, container.ID)
+			usages[attachment.Network] = usage
+		}
+	}
+	for _, service := range services {
+		for _, attachment := range service.Networks {
+			usage := usages[attachment.Network]
+			usage.Services = append(usage.Services, service.ID)
+			usages[attachment.Network] = usage
+		}
+	}
+	for name, usage := range usages {
+		sort.Strings(usage.Containers)
+		sort.Strings(usage.Services)
+		usages[name] = usage
+	}
+	return usages, nil
+}
+
+// ValidateNetwork checks the name, driver and subnet of a network.
+func ValidateNetwork(network models.Network) error {
+	if err := validateName(network.Name); err != nil {
+		return err
+	}
+	if network.Driver != "" && network.Driver != models.NetworkDriverBridge {
+		return fmt.Errorf("unsupported driver: %s", network.Driver)
+	}
+	if network.Subnet != "" {
+		if _, _, err := net.ParseCIDR(network.Subnet); err != nil {
+			return fmt.Errorf("invalid subnet: %s", network.Subnet)
+		}
+	}
+	return nil
+}
+
+// ValidateAttachments checks that each network is named properly and
+// attached to only once.
+func ValidateAttachments(attachments []models.NetworkAttachment) error {
+	seen := map[string]bool{}
+	for _, attachment := range attachments {
+		if err := validateName(attachment.Network); err != nil {
+			return err
+		}
+		if seen[attachment.Network] {
+			return fmt.Errorf("network %s is listed more than once", attachment.Network)
+		}
+		seen[attachment.Network] = true
+		for _, alias := range attachment.Aliases {
+			if alias == "" {
+				return fmt.Errorf("empty alias on network %s", attachment.Network)
+			}
+		}
+	}
+	return nil
+}
+
+func validateName(name string) error {
+	if name == "" {
+		return errors.New("network name is required")
+	}
+	if reservedNames[name] {
+		return fmt.Errorf("network name %s is reserved", name)
+	}
+	for i, ch := range name {
+		valid := ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' ||
+			i > 0 && (ch == '-' || ch == '_' || ch == '.')
+		if !valid {
+			return fmt.Errorf("invalid network name: %s", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"podium/internal/models"
+	"podium/internal/runtime"
+	"podium/internal/store"
+)
+
+func TestPruneAutoOnly(t *testing.T) {
+	ctx := context.Background()
+	stateStore := store.NewMemoryStore()
+	m := NewManager(stateStore, runtime.NewFakeRuntime(), 50*time.Millisecond)
+
+	if err := m.CreateNetwork(ctx, &models.Network{Name: "manual"}); err != nil {
+		t.Fatalf("creating network: %v", err)
+	}
+	if err := m.EnsureNetworks(ctx, []models.NetworkAttachment{{Network: "auto"}, {Network: "manual"}}); err != nil {
+		t.Fatalf("ensuring networks: %v", err)
+	}
+
+	// A new automatic network may be about to get its first container.
+	if removed, err := m.Prune(ctx, true); err != nil || len(removed) != 0 {
+		t.Fatalf("Prune right after creation = %v, %v, want nothing removed", removed, err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if removed, err := m.Prune(ctx, true); err != nil || len(removed) != 1 || removed[0] != "auto" {
+		t.Fatalf("Prune after the interval = %v, %v, want only auto removed", removed, err)
+	}
+	if _, err := stateStore.GetNetwork("manual"); err != nil {
+		t.Errorf("manual network was removed: %v", err)
+	}
+}
+
+func TestValidateAttachments(t *testing.T) {
+	tests := []struct {
+		name        string
+		attachments []models.NetworkAttachment
+		wantErr     bool
+	}{
+		{"none", nil, false},
+		{"aliases", []models.NetworkAttachment{{Network: "backend", Aliases: []string{"db"}}, {Network: "front.end"}}, false},
+		{"reserved", []models.NetworkAttachment{{Network: "host"}}, true},
+		{"invalid name", []models.NetworkAttachment{{Network: "-backend"}}, true},
+		{"twice", []models.NetworkAttachment{{Network: "backend"}, {Network: "backend"}}, true},
+		{"empty alias", []models.NetworkAttachment{{Network: "backend", Aliases: []string{""}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAttachments(tt.attachments)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAttachments() = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
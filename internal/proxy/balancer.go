@@ -0,0 +1,117 @@
+package proxy
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+
+	"podium/internal/models"
+)
+
+// backend is a replica the proxy forwards traffic to.
+type backend struct {
+	containerID string
+	address     string
+	// canary is set for replicas running the spec of a canary update.
+	canary bool
+	active atomic.Int64
+	// unreachable is set when the last attempt to connect to the backend
+	// failed.
+	unreachable atomic.Bool
+}
+
+// pool picks backends for a listener.
+type pool struct {
+	mu        sync.Mutex
+	algorithm string
+	// weight is the percentage of traffic sent to canary backends.
+	weight   int
+	backends []*backend
+	next     int
+}
+
+// update replaces the pool's backends, keeping the connection counts of
+// those it already had.
+func (p *pool) update(algorithm string, weight int, backends []*backend) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing := make(map[string]*backend, len(p.backends))
+	for _, b := range p.backends {
+		existing[b.containerID+"/"+b.address] = b
+	}
+	for i, b := range backends {
+		if old, ok := existing[b.containerID+"/"+b.address]; ok {
+			old.canary = b.canary
+			backends[i] = old
+		}
+	}
+
+	p.algorithm = algorithm
+	p.weight = weight
+	p.backends = backends
+}
+
+// pick chooses the backend for a new connection or request, or returns nil
+// if there are none. A canary gets its weight's share of picks; within the
+// canary or stable backends the pool's algorithm decides.
+func (p *pool) pick(exclude map[*backend]bool) *backend {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var stable, canary []*backend
+	for _, b := range p.backends {
+		if exclude[b] {
+			continue
+		}
+		if b.canary {
+			canary = append(canary, b)
+		} else {
+			stable = append(stable, b)
+		}
+	}
+
+	candidates := stable
+	if len(stable) == 0 || (len(canary) > 0 && rand.IntN(100) < p.weight) {
+		candidates = canary
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	p.next++
+	start := p.next % len(candidates)
+	if p.algorithm != models.LoadBalancerLeastConnections {
+		return candidates[start]
+	}
+
+	// Ties go to the backend round-robin would have picked.
+	best := candidates[start]
+	for i := 1; i < len(candidates); i++ {
+		b := candidates[(start+i)%len(candidates)]
+		if b.active.Load() < best.active.Load() {
+			best = b
+		}
+	}
+	return best
+}
+
+func (p *pool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.backends)
+}
+
+// unreachable reports whether the pool's backend for a container at address
+// failed the last attempt to connect to it.
+func (p *pool) unreachable(containerID, address string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, b := range p.backends {
+		if b.containerID == containerID && b.address == address {
+			return b.unreachable.Load()
+		}
+	}
+	return false
+}
@@ -0,0 +1,279 @@
+package proxy
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	caCertFile = "podium-ca.crt"
+	caKeyFile  = "podium-ca.key"
+
+	caValidity   = 10 * 365 * 24 * time.Hour
+	leafValidity = 90 * 24 * time.Hour
+	// leafRenewal is how long before expiry an issued certificate is
+	// replaced.
+	leafRenewal = 30 * 24 * time.Hour
+)
+
+// certificates serves the ingress's TLS certificates. A host uses the
+// certificate in the directory named after it (<host>.crt and <host>.key,
+// with "_" standing in for a wildcard's "*") if there is one; otherwise the
+// ingress issues one from Podium's internal CA, which is kept in the same
+// directory.
+type certificates struct {
+	dir    string
+	ca     *x509.Certificate
+	caKey  crypto.Signer
+	caPath string
+
+	mu    sync.Mutex
+	files map[string]*certificateFile
+	// issued caches the certificates issued by the internal CA, by host.
+	issued map[string]*tls.Certificate
+	// allowed reports whether a host name has an ingress rule. The CA only
+	// issues certificates for those.
+	allowed func(host string) bool
+}
+
+// certificateFile is a certificate loaded from the directory, reloaded when
+// either of its files changes.
+type certificateFile struct {
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func newCertificates(dir string, allowed func(string) bool) (*certificates, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	c := &certificates{
+		dir:     dir,
+		caPath:  filepath.Join(dir, caCertFile),
+		files:   make(map[string]*certificateFile),
+		issued:  make(map[string]*tls.Certificate),
+		allowed: allowed,
+	}
+	if err := c.loadCA(); err != nil {
+		return nil, err
+	}
+	c.reload()
+	return c, nil
+}
+
+// loadCA loads the internal CA from the directory, creating it on first use.
+func (c *certificates) loadCA() error {
+	keyPath := filepath.Join(c.dir, caKeyFile)
+	pair, err := tls.LoadX509KeyPair(c.caPath, keyPath)
+	if err == nil {
+		ca, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %v", c.caPath, err)
+		}
+		signer, ok := pair.PrivateKey.(crypto.Signer)
+		if !ok {
+			return fmt.Errorf("unsupported key in %s", keyPath)
+		}
+		c.ca, c.caKey = ca, signer
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to load the internal CA: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber(),
+		Subject:               pkix.Name{CommonName: "Podium Ingress CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := writePEM(keyPath, "EC PRIVATE KEY", keyDER, 0600); err != nil {
+		return err
+	}
+	if err := writePEM(c.caPath, "CERTIFICATE", der, 0644); err != nil {
+		return err
+	}
+
+	c.ca, err = x509.ParseCertificate(der)
+	if err != nil {
+		return err
+	}
+	c.caKey = key
+	log.Printf("Created the ingress's internal CA in %s", c.caPath)
+	return nil
+}
+
+// reload picks up certificates added to, changed in or removed from the
+// directory.
+func (c *certificates) reload() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		log.Printf("Error reading certificate directory %s: %v", c.dir, err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	found := map[string]bool{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".crt") || name == caCertFile {
+			continue
+		}
+		host := strings.ToLower(strings.TrimSuffix(name, ".crt"))
+		certPath := filepath.Join(c.dir, name)
+		keyPath := strings.TrimSuffix(certPath, ".crt") + ".key"
+
+		modTime, err := latestModTime(certPath, keyPath)
+		if err != nil {
+			continue
+		}
+		host = strings.Replace(host, "_", "*", 1)
+		found[host] = true
+		if existing, ok := c.files[host]; ok && existing.modTime.Equal(modTime) {
+			continue
+		}
+
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			log.Printf("Error loading certificate for %s: %v", host, err)
+			delete(c.files, host)
+			continue
+		}
+		log.Printf("Loaded certificate for %s from %s", host, certPath)
+		c.files[host] = &certificateFile{cert: &cert, modTime: modTime}
+	}
+	for host := range c.files {
+		if !found[host] {
+			log.Printf("Certificate for %s removed", host)
+			delete(c.files, host)
+		}
+	}
+}
+
+// get returns the certificate for a TLS handshake.
+func (c *certificates) get(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	host := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if host == "" {
+		return nil, errors.New("client did not send a server name")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if file, ok := c.files[host]; ok {
+		return file.cert, nil
+	}
+	if file, ok := c.files[wildcard(host)]; ok {
+		return file.cert, nil
+	}
+
+	if !c.allowed(host) {
+		return nil, fmt.Errorf("no ingress rule for %s", host)
+	}
+	if cert, ok := c.issued[host]; ok && time.Until(cert.Leaf.NotAfter) > leafRenewal {
+		return cert, nil
+	}
+	cert, err := c.issue(host)
+	if err != nil {
+		log.Printf("Error issuing certificate for %s: %v", host, err)
+		return nil, err
+	}
+	log.Printf("Issued certificate for %s from the internal CA", host)
+	c.issued[host] = cert
+	return cert, nil
+}
+
+// issue creates a certificate for the host signed by the internal CA.
+func (c *certificates) issue(host string) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serialNumber(),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(leafValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, c.ca, &key.PublicKey, c.caKey)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Certificate{
+		Certificate: [][]byte{der, c.ca.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// wildcard returns the wildcard name covering a host, such as
+// "*.example.com" for "app.example.com".
+func wildcard(host string) string {
+	if i := strings.IndexByte(host, '.'); i > 0 {
+		return "*" + host[i:]
+	}
+	return ""
+}
+
+func latestModTime(paths ...string) (time.Time, error) {
+	var latest time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func serialNumber() *big.Int {
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	return serial
+}
+
+func writePEM(path, blockType string, der []byte, perm os.FileMode) error {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	return os.WriteFile(path, data, perm)
+}
@@ -0,0 +1,346 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"podium/internal/models"
+)
+
+// IngressConfig sets up the ingress, which routes HTTP and HTTPS requests to
+// services by host name and path through a single entry point.
+type IngressConfig struct {
+	// HTTPAddr and HTTPSAddr are the addresses to listen on. An empty
+	// address disables that entry point.
+	HTTPAddr  string
+	HTTPSAddr string
+	// CertDir holds the certificates the ingress serves and its internal CA.
+	CertDir string
+}
+
+// ingress serves the services' ingress rules.
+type ingress struct {
+	config IngressConfig
+	certs  *certificates
+	http   *http.Server
+	https  *http.Server
+
+	mu     sync.RWMutex
+	routes []*ingressRoute
+	// pools holds the backends of each service port that rules route to,
+	// keyed by service ID and port. backends counts them after each sync.
+	pools    map[string]*pool
+	backends map[string]int
+}
+
+// ingressRoute is an ingress rule resolved to the pool it sends requests to.
+type ingressRoute struct {
+	host      string
+	prefix    string
+	tls       bool
+	serviceID string
+	port      int
+	pool      *pool
+}
+
+// EnableIngress starts the ingress's entry points. Call it before Start; the
+// proxy keeps the ingress's routes in step with the services from then on.
+func (p *Proxy) EnableIngress(config IngressConfig) error {
+	in := &ingress{
+		config:   config,
+		pools:    make(map[string]*pool),
+		backends: make(map[string]int),
+	}
+	certs, err := newCertificates(config.CertDir, in.allows)
+	if err != nil {
+		return err
+	}
+	in.certs = certs
+
+	if config.HTTPAddr != "" {
+		ln, err := net.Listen("tcp", config.HTTPAddr)
+		if err != nil {
+			return err
+		}
+		in.http = &http.Server{Handler: in, ReadHeaderTimeout: 30 * time.Second}
+		go in.serve(in.http, ln, false)
+	}
+	if config.HTTPSAddr != "" {
+		ln, err := net.Listen("tcp", config.HTTPSAddr)
+		if err != nil {
+			in.close()
+			return err
+		}
+		in.https = &http.Server{
+			Handler:           in,
+			ReadHeaderTimeout: 30 * time.Second,
+			TLSConfig: &tls.Config{
+				GetCertificate: certs.get,
+				MinVersion:     tls.VersionTLS12,
+			},
+		}
+		go in.serve(in.https, ln, true)
+	}
+
+	p.mu.Lock()
+	p.ingress = in
+	p.mu.Unlock()
+
+	log.Printf("Ingress listening on %q (HTTP) and %q (HTTPS), internal CA in %s", config.HTTPAddr, config.HTTPSAddr, certs.caPath)
+	return nil
+}
+
+func (in *ingress) serve(server *http.Server, ln net.Listener, secure bool) {
+	var err error
+	if secure {
+		err = server.ServeTLS(ln, "", "")
+	} else {
+		err = server.Serve(ln)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Ingress listener on %s failed: %v", ln.Addr(), err)
+	}
+}
+
+func (in *ingress) close() {
+	if in.http != nil {
+		in.http.Close()
+	}
+	if in.https != nil {
+		in.https.Close()
+	}
+}
+
+// syncIngress rebuilds the ingress's routes from the services' rules and
+// refreshes the replicas they forward to. Certificates changed on disk are
+// reloaded at the same time. The caller must hold p.mu.
+func (p *Proxy) syncIngress(services []models.Service, seen map[string]bool) {
+	in := p.ingress
+	in.certs.reload()
+
+	var routes []*ingressRoute
+	pools := map[string]*pool{}
+	backends := map[string]int{}
+	owners := map[string]string{}
+	for _, svc := range services {
+		for _, rule := range svc.Ingress {
+			port := rule.Port
+			if port == 0 {
+				if len(svc.Ports) == 0 {
+					continue
+				}
+				port = svc.Ports[0].ContainerPort
+			}
+			host := strings.ToLower(rule.Host)
+			prefix := pathPrefix(rule.PathPrefix)
+			if owner, ok := owners[host+prefix]; ok && owner != svc.ID {
+				log.Printf("Ingress rule for %s%s of service %s is already used by service %s", host, prefix, svc.ID, owner)
+				continue
+			}
+			owners[host+prefix] = svc.ID
+
+			key := fmt.Sprintf("%s/%d", svc.ID, port)
+			pl, ok := pools[key]
+			if !ok {
+				if pl, ok = in.pools[key]; !ok {
+					pl = &pool{}
+				}
+				pools[key] = pl
+				backends[key] = p.refresh(pl, svc.ID, port, svc.LoadBalancer, seen)
+				if backends[key] != in.backends[key] {
+					log.Printf("Ingress now forwards to %d replicas of service %s on port %d", backends[key], svc.ID, port)
+				}
+			}
+
+			routes = append(routes, &ingressRoute{
+				host:      host,
+				prefix:    prefix,
+				tls:       rule.TLS,
+				serviceID: svc.ID,
+				port:      port,
+				pool:      pl,
+			})
+		}
+	}
+
+	// Exact host names win over wildcards, then longer prefixes over
+	// shorter ones.
+	sort.SliceStable(routes, func(i, j int) bool {
+		wi, wj := strings.HasPrefix(routes[i].host, "*."), strings.HasPrefix(routes[j].host, "*.")
+		if wi != wj {
+			return wj
+		}
+		return len(routes[i].prefix) > len(routes[j].prefix)
+	})
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	previous := map[string]bool{}
+	for _, r := range in.routes {
+		previous[r.describe()] = true
+	}
+	current := map[string]bool{}
+	for _, r := range routes {
+		current[r.describe()] = true
+		if !previous[r.describe()] {
+			log.Printf("Ingress routing %s", r.describe())
+		}
+	}
+	for description := range previous {
+		if !current[description] {
+			log.Printf("Ingress no longer routing %s", description)
+		}
+	}
+
+	in.routes = routes
+	in.pools = pools
+	in.backends = backends
+}
+
+func (r *ingressRoute) describe() string {
+	scheme := "http"
+	if r.tls {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s to service %s port %d", scheme, r.host, r.prefix, r.serviceID, r.port)
+}
+
+// ServeHTTP forwards a request to the service of the rule it matches.
+func (in *ingress) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := requestHost(r.Host)
+	route := in.match(host, r.URL.Path)
+	if route == nil {
+		http.Error(w, "no ingress rule matches the request", http.StatusNotFound)
+		return
+	}
+
+	if route.tls && r.TLS == nil && in.https != nil {
+		target := "https://" + host
+		if _, port, err := net.SplitHostPort(in.config.HTTPSAddr); err == nil && port != "443" {
+			target = "https://" + net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, target+r.URL.RequestURI(), http.StatusPermanentRedirect)
+		return
+	}
+
+	forwardHTTP(w, r, route.pool, "Ingress for "+host)
+}
+
+// match returns the route for a request, or nil if no rule matches it.
+func (in *ingress) match(host, path string) *ingressRoute {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+
+	for _, r := range in.routes {
+		if hostMatches(r.host, host) && pathMatches(r.prefix, path) {
+			return r
+		}
+	}
+	return nil
+}
+
+// allows reports whether any rule matches the host, which the internal CA
+// then issues a certificate for.
+func (in *ingress) allows(host string) bool {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+
+	for _, r := range in.routes {
+		if hostMatches(r.host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestHost returns the host name of a request's Host header, without
+// the port.
+func requestHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+func hostMatches(pattern, host string) bool {
+	if strings.HasPrefix(pattern, "*.") {
+		return wildcard(host) == pattern
+	}
+	return pattern == host
+}
+
+// pathMatches matches whole path segments, so "/api" matches "/api" and
+// "/api/users" but not "/apis".
+func pathMatches(prefix, path string) bool {
+	return prefix == "/" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+func pathPrefix(prefix string) string {
+	if prefix == "" || prefix == "/" {
+		return "/"
+	}
+	return strings.TrimSuffix(prefix, "/")
+}
+
+// ValidateIngress checks a service's ingress rules against its ports.
+func ValidateIngress(rules []models.IngressRule, ports []models.PortMapping) error {
+	seen := map[string]bool{}
+	for _, rule := range rules {
+		if err := validateHost(rule.Host); err != nil {
+			return err
+		}
+		if rule.PathPrefix != "" && !strings.HasPrefix(rule.PathPrefix, "/") {
+			return fmt.Errorf("path prefix %q must start with /", rule.PathPrefix)
+		}
+
+		if rule.Port == 0 && len(ports) == 0 {
+			return fmt.Errorf("rule for %s needs a port, the service has none", rule.Host)
+		}
+		if rule.Port != 0 {
+			found := false
+			for _, port := range ports {
+				if port.ContainerPort == rule.Port {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("rule for %s routes to port %d, which the service does not expose", rule.Host, rule.Port)
+			}
+		}
+
+		key := strings.ToLower(rule.Host) + pathPrefix(rule.PathPrefix)
+		if seen[key] {
+			return fmt.Errorf("more than one rule for %s", key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func validateHost(host string) error {
+	if host == "" {
+		return errors.New("host is required")
+	}
+	name := strings.TrimPrefix(host, "*.")
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("invalid host: %s", host)
+		}
+		for _, ch := range label {
+			if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '-') {
+				return fmt.Errorf("invalid host: %s", host)
+			}
+		}
+	}
+	return nil
+}
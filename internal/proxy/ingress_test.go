@@ -0,0 +1,104 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"podium/internal/models"
+)
+
+func TestIngressRouting(t *testing.T) {
+	p := newTestProxy(t)
+	if err := p.EnableIngress(IngressConfig{CertDir: t.TempDir()}); err != nil {
+		t.Fatalf("enabling ingress: %v", err)
+	}
+	p.createService(t, models.Service{
+		Name:    "web",
+		Image:   "nginx:latest",
+		Ports:   []models.PortMapping{{ContainerPort: backendServer(t, "web")}},
+		Ingress: []models.IngressRule{{Host: "example.com"}, {Host: "*.example.com"}},
+	})
+	p.createService(t, models.Service{
+		Name:    "api",
+		Image:   "nginx:latest",
+		Ports:   []models.PortMapping{{ContainerPort: backendServer(t, "api")}},
+		Ingress: []models.IngressRule{{Host: "Example.com", PathPrefix: "/api/"}},
+	})
+	p.sync()
+
+	tests := []struct {
+		host, path string
+		want       string
+	}{
+		{"example.com", "/", "web"},
+		{"example.com", "/api", "api"},
+		{"example.com", "/api/users", "api"},
+		{"example.com", "/apis", "web"},
+		{"EXAMPLE.com:8080", "/api/users", "api"},
+		{"example.com.", "/api", "api"},
+		// The wildcard only matches one label, and the /api rule only its
+		// exact host.
+		{"shop.example.com", "/api", "web"},
+		{"a.shop.example.com", "/", ""},
+		{"example.org", "/", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "http://"+tt.host+tt.path, nil)
+		rec := httptest.NewRecorder()
+		p.ingress.ServeHTTP(rec, req)
+
+		if tt.want == "" {
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("%s%s: got status %d, want 404", tt.host, tt.path, rec.Code)
+			}
+			continue
+		}
+		if rec.Code != http.StatusOK || rec.Body.String() != tt.want {
+			t.Errorf("%s%s: got status %d from %q, want 200 from %s", tt.host, tt.path, rec.Code, rec.Body.String(), tt.want)
+		}
+	}
+}
+
+func TestPathMatches(t *testing.T) {
+	tests := []struct {
+		prefix, path string
+		want         bool
+	}{
+		{"/", "/anything", true},
+		{"/api", "/api", true},
+		{"/api", "/api/users", true},
+		{"/api", "/apis", false},
+		{"/api", "/", false},
+	}
+	for _, tt := range tests {
+		if got := pathMatches(tt.prefix, tt.path); got != tt.want {
+			t.Errorf("pathMatches(%q, %q) = %v, want %v", tt.prefix, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestValidateIngress(t *testing.T) {
+	ports := []models.PortMapping{{ContainerPort: 80}}
+	tests := []struct {
+		name    string
+		rules   []models.IngressRule
+		ports   []models.PortMapping
+		wantErr bool
+	}{
+		{"host", []models.IngressRule{{Host: "example.com"}}, ports, false},
+		{"wildcard", []models.IngressRule{{Host: "*.example.com", PathPrefix: "/api"}}, ports, false},
+		{"no port", []models.IngressRule{{Host: "example.com"}}, nil, true},
+		{"unexposed port", []models.IngressRule{{Host: "example.com", Port: 8080}}, ports, true},
+		{"relative prefix", []models.IngressRule{{Host: "example.com", PathPrefix: "api"}}, ports, true},
+		{"duplicate", []models.IngressRule{{Host: "example.com", PathPrefix: "/api/"}, {Host: "Example.com", PathPrefix: "/api"}}, ports, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIngress(tt.rules, tt.ports)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateIngress() = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
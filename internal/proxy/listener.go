@@ -0,0 +1,166 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"podium/internal/models"
+)
+
+const dialTimeout = 5 * time.Second
+
+// route is what a listener serves: one port of a service.
+type route struct {
+	serviceID     string
+	containerPort int
+	protocol      string
+}
+
+// listener accepts traffic on a service's host port and forwards it to the
+// service's replicas.
+type listener struct {
+	route    route
+	hostPort int
+	ln       net.Listener
+	server   *http.Server
+	pool     *pool
+	// backends is how many replicas the pool had after the last sync.
+	backends int
+}
+
+func newListener(r route, hostPort int) (*listener, error) {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", hostPort))
+	if err != nil {
+		return nil, err
+	}
+
+	l := &listener{route: r, hostPort: hostPort, ln: ln, pool: &pool{}}
+	if r.protocol == models.LoadBalancerProtocolHTTP {
+		l.server = &http.Server{Handler: l, ReadHeaderTimeout: 30 * time.Second}
+		go func() {
+			if err := l.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("Proxy listener on port %d failed: %v", hostPort, err)
+			}
+		}()
+	} else {
+		go l.serveTCP()
+	}
+	return l, nil
+}
+
+func (l *listener) close() {
+	if l.server != nil {
+		l.server.Close()
+		return
+	}
+	l.ln.Close()
+}
+
+func (l *listener) serveTCP() {
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("Proxy listener on port %d failed: %v", l.hostPort, err)
+			}
+			return
+		}
+		go l.forward(conn)
+	}
+}
+
+// forward copies a connection to and from a backend, trying each backend in
+// turn until one accepts it.
+func (l *listener) forward(client net.Conn) {
+	defer client.Close()
+
+	tried := map[*backend]bool{}
+	for {
+		b := l.pool.pick(tried)
+		if b == nil {
+			log.Printf("Proxy on port %d has no replica to forward a connection to", l.hostPort)
+			return
+		}
+		tried[b] = true
+
+		upstream, err := net.DialTimeout("tcp", b.address, dialTimeout)
+		b.unreachable.Store(err != nil)
+		if err != nil {
+			log.Printf("Proxy on port %d could not reach container %s: %v", l.hostPort, b.containerID, err)
+			continue
+		}
+
+		b.active.Add(1)
+		pipe(client, upstream)
+		b.active.Add(-1)
+		upstream.Close()
+		return
+	}
+}
+
+// pipe copies data both ways until both directions are finished.
+func pipe(client, upstream net.Conn) {
+	done := make(chan struct{})
+	go func() {
+		io.Copy(upstream, client)
+		closeWrite(upstream)
+		close(done)
+	}()
+	io.Copy(client, upstream)
+	closeWrite(client)
+	<-done
+}
+
+func closeWrite(conn net.Conn) {
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.CloseWrite()
+		return
+	}
+	conn.Close()
+}
+
+// ServeHTTP forwards a request to a backend.
+func (l *listener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	forwardHTTP(w, r, l.pool, fmt.Sprintf("Proxy on port %d", l.hostPort))
+}
+
+// forwardHTTP forwards a request to a backend picked from the pool. name
+// describes the caller in log messages.
+func forwardHTTP(w http.ResponseWriter, r *http.Request, pl *pool, name string) {
+	b := pl.pick(nil)
+	if b == nil {
+		http.Error(w, "no healthy replicas", http.StatusServiceUnavailable)
+		return
+	}
+
+	b.active.Add(1)
+	defer b.active.Add(-1)
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(&url.URL{Scheme: "http", Host: b.address})
+			pr.Out.Host = pr.In.Host
+			pr.SetXForwarded()
+		},
+		ModifyResponse: func(*http.Response) error {
+			b.unreachable.Store(false)
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			var opErr *net.OpError
+			if errors.As(err, &opErr) && opErr.Op == "dial" {
+				b.unreachable.Store(true)
+			}
+			log.Printf("%s could not reach container %s: %v", name, b.containerID, err)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
@@ -0,0 +1,252 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"podium/internal/models"
+	"podium/internal/runtime"
+	"podium/internal/service"
+	"podium/internal/store"
+)
+
+// Proxy is Podium's built-in load balancer. For every service with a load
+// balancer it listens on the service's host ports and forwards traffic to
+// the service's healthy replicas, refreshing them at a fixed interval.
+type Proxy struct {
+	store    store.Store
+	runtime  runtime.Runtime
+	services service.Manager
+	interval time.Duration
+	stopCh   chan struct{}
+
+	mu        sync.Mutex
+	listeners map[int]*listener
+	ingress   *ingress
+	// addresses caches the address of each replica port, keyed by container
+	// ID, start time and port, as a restart can move a published port.
+	addresses map[string]string
+}
+
+func NewProxy(store store.Store, runtime runtime.Runtime, services service.Manager, interval time.Duration) *Proxy {
+	return &Proxy{
+		store:     store,
+		runtime:   runtime,
+		services:  services,
+		interval:  interval,
+		stopCh:    make(chan struct{}),
+		listeners: make(map[int]*listener),
+		addresses: make(map[string]string),
+	}
+}
+
+func (p *Proxy) Start() {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		p.sync()
+		for {
+			select {
+			case <-p.stopCh:
+				log.Println("Proxy stopped")
+				return
+			case <-ticker.C:
+				p.sync()
+			}
+		}
+	}()
+	log.Println("Proxy started")
+}
+
+func (p *Proxy) Stop() {
+	close(p.stopCh)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for port, l := range p.listeners {
+		l.close()
+		delete(p.listeners, port)
+	}
+	if p.ingress != nil {
+		p.ingress.close()
+	}
+}
+
+// sync opens and closes listeners to match the services' load balancers and
+// refreshes the replicas each listener forwards to.
+func (p *Proxy) sync() {
+	services, err := p.store.ListServices()
+	if err != nil {
+		log.Printf("Error listing services for the proxy: %v", err)
+		return
+	}
+
+	routes := map[int]route{}
+	balancers := map[string]*models.LoadBalancer{}
+	for _, svc := range services {
+		if svc.LoadBalancer == nil {
+			continue
+		}
+		balancers[svc.ID] = svc.LoadBalancer
+		for _, port := range svc.Ports {
+			if port.HostPort == 0 {
+				continue
+			}
+			if existing, ok := routes[port.HostPort]; ok && existing.serviceID != svc.ID {
+				log.Printf("Host port %d of service %s is already used by service %s", port.HostPort, svc.ID, existing.serviceID)
+				continue
+			}
+			routes[port.HostPort] = route{
+				serviceID:     svc.ID,
+				containerPort: port.ContainerPort,
+				protocol:      protocol(svc.LoadBalancer),
+			}
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.stopCh:
+		return
+	default:
+	}
+
+	for port, l := range p.listeners {
+		if r, ok := routes[port]; !ok || r != l.route {
+			log.Printf("Proxy closing port %d of service %s", port, l.route.serviceID)
+			l.close()
+			delete(p.listeners, port)
+		}
+	}
+
+	seen := map[string]bool{}
+	for port, r := range routes {
+		l, ok := p.listeners[port]
+		if !ok {
+			l, err = newListener(r, port)
+			if err != nil {
+				// The port may still be held by a replica that is yet to be
+				// replaced; the next sync tries again.
+				log.Printf("Proxy could not listen on port %d for service %s: %v", port, r.serviceID, err)
+				continue
+			}
+			log.Printf("Proxy listening on port %d for service %s (%s)", port, r.serviceID, r.protocol)
+			p.listeners[port] = l
+		}
+		count := p.refresh(l.pool, r.serviceID, r.containerPort, balancers[r.serviceID], seen)
+		if count != l.backends {
+			log.Printf("Proxy on port %d now forwards to %d replicas of service %s", port, count, r.serviceID)
+			l.backends = count
+		}
+	}
+
+	if p.ingress != nil {
+		p.syncIngress(services, seen)
+	}
+
+	for key := range p.addresses {
+		if !seen[key] {
+			delete(p.addresses, key)
+		}
+	}
+}
+
+// refresh points a pool at the given port of the service's healthy replicas
+// and returns how many there are. The caller must hold p.mu.
+func (p *Proxy) refresh(pl *pool, serviceID string, containerPort int, balancer *models.LoadBalancer, seen map[string]bool) int {
+	ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+	defer cancel()
+
+	status, err := p.services.GetServiceStatus(ctx, serviceID)
+	if err != nil {
+		log.Printf("Error getting status of service %s for the proxy: %v", serviceID, err)
+		return pl.size()
+	}
+	svc, err := p.store.GetService(serviceID)
+	if err != nil {
+		log.Printf("Error getting service %s for the proxy: %v", serviceID, err)
+		return pl.size()
+	}
+
+	// A blue-green update's new replicas get no traffic until it cuts over.
+	update := svc.UpdateStatus
+	holdBack := update.InProgress() && !update.Rollback && svc.UpdateConfig != nil &&
+		svc.UpdateConfig.Strategy == models.UpdateStrategyBlueGreen
+
+	weight := 0
+	if status.Canary != nil {
+		weight = status.Canary.Weight
+	}
+
+	var backends []*backend
+	for _, container := range status.Containers {
+		if container.Status != string(models.ContainerStateRunning) || container.HealthState != string(models.HealthStatusHealthy) {
+			continue
+		}
+		if holdBack && container.SpecHash == status.SpecHash {
+			continue
+		}
+
+		// A replica that refused connections may have been restarted by its
+		// runtime onto another port, so its address is looked up again.
+		key := fmt.Sprintf("%s/%s/%d", container.ID, container.StartedAt, containerPort)
+		address, ok := p.addresses[key]
+		if !ok || pl.unreachable(container.ID, address) {
+			address, err = p.runtime.ContainerAddress(ctx, container.ID, containerPort)
+			if err != nil {
+				log.Printf("Error getting address of container %s for the proxy: %v", container.ID, err)
+				continue
+			}
+			p.addresses[key] = address
+		}
+		seen[key] = true
+
+		backends = append(backends, &backend{
+			containerID: container.ID,
+			address:     address,
+			canary:      status.Canary != nil && container.SpecHash == status.SpecHash,
+		})
+	}
+
+	pl.update(algorithm(balancer), weight, backends)
+	return len(backends)
+}
+
+// ValidateLoadBalancer checks the algorithm and protocol of a load balancer.
+// A nil load balancer is valid and means the service has none.
+func ValidateLoadBalancer(balancer *models.LoadBalancer) error {
+	if balancer == nil {
+		return nil
+	}
+	switch balancer.Algorithm {
+	case "", models.LoadBalancerRoundRobin, models.LoadBalancerLeastConnections:
+	default:
+		return fmt.Errorf("unknown algorithm: %s", balancer.Algorithm)
+	}
+	switch balancer.Protocol {
+	case "", models.LoadBalancerProtocolTCP, models.LoadBalancerProtocolHTTP:
+	default:
+		return fmt.Errorf("unknown protocol: %s", balancer.Protocol)
+	}
+	return nil
+}
+
+func algorithm(balancer *models.LoadBalancer) string {
+	if balancer == nil || balancer.Algorithm == "" {
+		return models.LoadBalancerRoundRobin
+	}
+	return balancer.Algorithm
+}
+
+func protocol(balancer *models.LoadBalancer) string {
+	if balancer.Protocol == "" {
+		return models.LoadBalancerProtocolTCP
+	}
+	return balancer.Protocol
+}
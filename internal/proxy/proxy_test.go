@@ -0,0 +1,268 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"podium/internal/events"
+	"podium/internal/image"
+	"podium/internal/models"
+	"podium/internal/network"
+	"podium/internal/runtime"
+	"podium/internal/service"
+	"podium/internal/store"
+	"podium/internal/volume"
+)
+
+// testProxy is a proxy over services on the fake runtime and the memory
+// store. It is synced by hand rather than started.
+type testProxy struct {
+	*Proxy
+	store   store.Store
+	runtime *runtime.FakeRuntime
+	manager *service.RuntimeServiceManager
+}
+
+func newTestProxy(t *testing.T) *testProxy {
+	t.Helper()
+
+	stateStore := store.NewMemoryStore()
+	rt := runtime.NewFakeRuntime()
+	bus := events.NewBus(stateStore, 100)
+	puller := image.NewPuller(rt, stateStore)
+	networks := network.NewManager(stateStore, rt, time.Minute)
+	volumes := volume.NewManager(stateStore, rt, t.TempDir(), time.Minute)
+	manager := service.NewManager(rt, stateStore, puller, networks, volumes, bus)
+
+	p := NewProxy(stateStore, rt, manager, time.Second)
+	t.Cleanup(p.Stop)
+	return &testProxy{Proxy: p, store: stateStore, runtime: rt, manager: manager}
+}
+
+// createService creates a service whose replicas, on the fake runtime, are
+// reached at the container port on the loopback address.
+func (p *testProxy) createService(t *testing.T, svc models.Service) models.Service {
+	t.Helper()
+
+	svc.ID = "svc-" + svc.Name
+	if err := p.store.CreateService(svc); err != nil {
+		t.Fatalf("storing service: %v", err)
+	}
+	if err := p.manager.CreateService(context.Background(), &svc); err != nil {
+		t.Fatalf("creating service: %v", err)
+	}
+	return svc
+}
+
+// backendServer starts an HTTP server that answers every request with its
+// name, and returns the port it listens on.
+func backendServer(t *testing.T, name string) int {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Seen-Host", r.Host)
+		w.Header().Set("X-Seen-Forwarded-For", r.Header.Get("X-Forwarded-For"))
+		fmt.Fprint(w, name)
+	}))
+	t.Cleanup(server.Close)
+	return server.Listener.Addr().(*net.TCPAddr).Port
+}
+
+// freePort returns a port nothing is listening on.
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("finding a free port: %v", err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func get(t *testing.T, url string) (*http.Response, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading response to GET %s: %v", url, err)
+	}
+	return resp, string(body)
+}
+
+func newPool(algorithm string, weight int, backends ...*backend) *pool {
+	p := &pool{}
+	p.update(algorithm, weight, backends)
+	return p
+}
+
+func TestPoolRoundRobin(t *testing.T) {
+	a, b, c := &backend{containerID: "a"}, &backend{containerID: "b"}, &backend{containerID: "c"}
+	p := newPool(models.LoadBalancerRoundRobin, 0, a, b, c)
+
+	counts := map[*backend]int{}
+	for i := 0; i < 30; i++ {
+		counts[p.pick(nil)]++
+	}
+	for _, backend := range []*backend{a, b, c} {
+		if counts[backend] != 10 {
+			t.Errorf("backend %s picked %d times, want 10", backend.containerID, counts[backend])
+		}
+	}
+
+	if got := p.pick(map[*backend]bool{a: true, b: true}); got != c {
+		t.Errorf("pick excluding a and b = %v, want c", got)
+	}
+	if got := p.pick(map[*backend]bool{a: true, b: true, c: true}); got != nil {
+		t.Errorf("pick excluding every backend = %v, want nil", got)
+	}
+}
+
+func TestPoolLeastConnections(t *testing.T) {
+	a, b, c := &backend{containerID: "a"}, &backend{containerID: "b"}, &backend{containerID: "c"}
+	a.active.Store(2)
+	b.active.Store(1)
+	c.active.Store(3)
+	p := newPool(models.LoadBalancerLeastConnections, 0, a, b, c)
+
+	for i := 0; i < 5; i++ {
+		if got := p.pick(nil); got != b {
+			t.Fatalf("pick = %s, want b, which has the fewest connections", got.containerID)
+		}
+	}
+}
+
+func TestPoolUpdateKeepsConnectionCounts(t *testing.T) {
+	a := &backend{containerID: "a", address: "127.0.0.1:1"}
+	a.active.Store(4)
+	p := newPool(models.LoadBalancerRoundRobin, 0, a)
+
+	p.update(models.LoadBalancerRoundRobin, 0, []*backend{{containerID: "a", address: "127.0.0.1:1", canary: true}})
+	got := p.pick(nil)
+	if got != a || got.active.Load() != 4 || !got.canary {
+		t.Errorf("after update, pick = %+v, want the existing backend marked as a canary", got)
+	}
+}
+
+func TestPoolCanaryWeight(t *testing.T) {
+	stable, canary := &backend{containerID: "stable"}, &backend{containerID: "canary", canary: true}
+
+	for _, tt := range []struct {
+		weight int
+		want   *backend
+	}{
+		{0, stable},
+		{100, canary},
+	} {
+		p := newPool(models.LoadBalancerRoundRobin, tt.weight, stable, canary)
+		for i := 0; i < 20; i++ {
+			if got := p.pick(nil); got != tt.want {
+				t.Fatalf("weight %d: pick = %s, want %s", tt.weight, got.containerID, tt.want.containerID)
+			}
+		}
+	}
+
+	// With only canary backends left, they get all the traffic.
+	p := newPool(models.LoadBalancerRoundRobin, 0, canary)
+	if got := p.pick(nil); got != canary {
+		t.Errorf("pick with only a canary = %v, want the canary", got)
+	}
+}
+
+func TestProxyForwardsHTTPRequests(t *testing.T) {
+	p := newTestProxy(t)
+	backendPort := backendServer(t, "web")
+	hostPort := freePort(t)
+	svc := p.createService(t, models.Service{
+		Name:         "web",
+		Image:        "nginx:latest",
+		Replicas:     2,
+		Ports:        []models.PortMapping{{ContainerPort: backendPort, HostPort: hostPort}},
+		LoadBalancer: &models.LoadBalancer{Protocol: models.LoadBalancerProtocolHTTP},
+	})
+	p.sync()
+
+	url := "http://127.0.0.1:" + strconv.Itoa(hostPort) + "/"
+	resp, body := get(t, url)
+	if resp.StatusCode != http.StatusOK || body != "web" {
+		t.Fatalf("got status %d with body %q, want 200 from the web backend", resp.StatusCode, body)
+	}
+	if host := resp.Header.Get("X-Seen-Host"); host != "127.0.0.1:"+strconv.Itoa(hostPort) {
+		t.Errorf("backend saw host %q, want the proxy's", host)
+	}
+	if forwarded := resp.Header.Get("X-Seen-Forwarded-For"); forwarded != "127.0.0.1" {
+		t.Errorf("backend saw X-Forwarded-For %q, want 127.0.0.1", forwarded)
+	}
+
+	// Replicas that stop running are dropped at the next sync.
+	for _, id := range svc.ContainerIDs {
+		if err := p.runtime.Crash(id, 1); err != nil {
+			t.Fatalf("crashing replica: %v", err)
+		}
+	}
+	p.sync()
+	if resp, _ := get(t, url); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got status %d with no running replicas, want 503", resp.StatusCode)
+	}
+}
+
+func TestProxyForwardsTCPPastUnreachableBackends(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	l, err := newListener(route{protocol: models.LoadBalancerProtocolTCP}, 0)
+	if err != nil {
+		t.Fatalf("starting listener: %v", err)
+	}
+	defer l.close()
+	down := &backend{containerID: "down", address: "127.0.0.1:" + strconv.Itoa(freePort(t))}
+	up := &backend{containerID: "up", address: echo.Addr().String()}
+	l.pool.update(models.LoadBalancerRoundRobin, 0, []*backend{down, up})
+
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", l.ln.Addr().String())
+		if err != nil {
+			t.Fatalf("connecting to the proxy: %v", err)
+		}
+		fmt.Fprint(conn, "ping")
+		conn.(*net.TCPConn).CloseWrite()
+		reply, err := io.ReadAll(conn)
+		conn.Close()
+		if err != nil || string(reply) != "ping" {
+			t.Fatalf("connection %d: got %q, %v, want the echo of ping", i, reply, err)
+		}
+	}
+	if !down.unreachable.Load() {
+		t.Error("the backend that refused connections is not marked unreachable")
+	}
+	if up.unreachable.Load() {
+		t.Error("the backend that accepted connections is marked unreachable")
+	}
+}
@@ -0,0 +1,192 @@
+package runtime
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// writeArchive writes the contents of dir to w as a tar archive, with paths
+// relative to dir. Sockets and devices are skipped.
+func writeArchive(dir string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		mode := info.Mode()
+		if mode&(fs.ModeSocket|fs.ModeDevice|fs.ModeNamedPipe) != 0 {
+			return nil
+		}
+
+		var link string
+		if mode&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !mode.IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", dir, err)
+	}
+	return tw.Close()
+}
+
+// extractArchive unpacks a tar archive into dir. Entries that would land
+// outside dir, or hard links to files outside it, directly or through a
+// symbolic link, are rejected.
+func extractArchive(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		target, err := archiveTarget(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		if target == dir {
+			continue
+		}
+		if err := insideDir(dir, existingParent(target)); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		// A symbolic link in the way is replaced rather than followed.
+		if info, err := os.Lstat(target); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			os.Remove(target)
+		}
+
+		mode := fs.FileMode(header.Mode).Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			// Whatever is in the way is replaced, so that the file is not
+			// written through a hard link to one outside dir.
+			if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			os.Remove(target)
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			source, err := archiveTarget(dir, header.Linkname)
+			if err != nil {
+				return err
+			}
+			if err := insideDir(dir, existingParent(source)); err != nil {
+				return err
+			}
+			os.Remove(target)
+			if err := os.Link(source, target); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+
+		// Ownership can only be kept when running as root.
+		os.Lchown(target, header.Uid, header.Gid)
+		if header.Typeflag != tar.TypeSymlink {
+			os.Chmod(target, mode)
+			os.Chtimes(target, header.ModTime, header.ModTime)
+		}
+	}
+}
+
+// archiveTarget returns where an archive entry goes within dir.
+func archiveTarget(dir, name string) (string, error) {
+	clean := path.Clean("/" + name)
+	if name == "" || path.IsAbs(name) || strings.Contains("/"+name+"/", "/../") {
+		return "", fmt.Errorf("invalid path in archive: %s", name)
+	}
+	return filepath.Join(dir, filepath.FromSlash(clean)), nil
+}
+
+// existingParent returns the closest parent directory of file that exists.
+func existingParent(file string) string {
+	parent := filepath.Dir(file)
+	for {
+		if _, err := os.Lstat(parent); err == nil || parent == filepath.Dir(parent) {
+			return parent
+		}
+		parent = filepath.Dir(parent)
+	}
+}
+
+// insideDir checks that file, once symbolic links are resolved, is within
+// dir.
+func insideDir(dir, file string) error {
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	resolved, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return err
+	}
+	if resolved != resolvedDir && !strings.HasPrefix(resolved, resolvedDir+string(filepath.Separator)) {
+		return errors.New("archive writes outside the volume through a symbolic link")
+	}
+	return nil
+}
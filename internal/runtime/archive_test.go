@@ -0,0 +1,189 @@
+package runtime
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"podium/internal/models"
+)
+
+// entry is a file in an archive built by tarball.
+type entry struct {
+	name, link, body string
+	typeflag         byte
+}
+
+func tarball(t *testing.T, entries ...entry) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Linkname: e.link, Typeflag: e.typeflag, Mode: 0644, Size: int64(len(e.body))}
+		if e.typeflag == tar.TypeDir {
+			header.Mode = 0755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("writing header for %s: %v", e.name, err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatalf("writing %s: %v", e.name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing archive: %v", err)
+	}
+	return &buf
+}
+
+func writeFile(t *testing.T, file, body string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatalf("creating directory: %v", err)
+	}
+	if err := os.WriteFile(file, []byte(body), 0644); err != nil {
+		t.Fatalf("writing %s: %v", file, err)
+	}
+}
+
+func readFile(t *testing.T, file string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("reading %s: %v", file, err)
+	}
+	return string(data)
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "conf", "app.conf"), "port = 80")
+	if err := os.Symlink("conf/app.conf", filepath.Join(src, "current")); err != nil {
+		t.Fatalf("creating symbolic link: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeArchive(src, &buf); err != nil {
+		t.Fatalf("writing archive: %v", err)
+	}
+	dst := t.TempDir()
+	if err := extractArchive(&buf, dst); err != nil {
+		t.Fatalf("extracting archive: %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(dst, "conf", "app.conf")); got != "port = 80" {
+		t.Errorf("conf/app.conf = %q, want port = 80", got)
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "current")); err != nil || link != "conf/app.conf" {
+		t.Errorf("current links to %q, %v, want conf/app.conf", link, err)
+	}
+}
+
+func TestExtractArchiveStaysInDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+	}{
+		{"parent path", []entry{{name: "../secret", body: "new", typeflag: tar.TypeReg}}},
+		{"file through symbolic link", []entry{
+			{name: "out", link: "OUTSIDE", typeflag: tar.TypeSymlink},
+			{name: "out/secret", body: "new", typeflag: tar.TypeReg},
+		}},
+		{"hard link to parent path", []entry{{name: "secret", link: "../secret", typeflag: tar.TypeLink}}},
+		{"hard link through symbolic link", []entry{
+			{name: "out", link: "OUTSIDE", typeflag: tar.TypeSymlink},
+			{name: "secret", link: "out/secret", typeflag: tar.TypeLink},
+			{name: "secret", body: "new", typeflag: tar.TypeReg},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			outside := filepath.Join(root, "outside")
+			writeFile(t, filepath.Join(outside, "secret"), "old")
+			dir := filepath.Join(root, "volume")
+			if err := os.Mkdir(dir, 0755); err != nil {
+				t.Fatalf("creating volume directory: %v", err)
+			}
+
+			entries := append([]entry(nil), tt.entries...)
+			for i := range entries {
+				if entries[i].link == "OUTSIDE" {
+					entries[i].link = outside
+				}
+			}
+			if err := extractArchive(tarball(t, entries...), dir); err == nil {
+				t.Error("extracting archive succeeded, want an error")
+			}
+			if got := readFile(t, filepath.Join(outside, "secret")); got != "old" {
+				t.Errorf("file outside the directory changed to %q", got)
+			}
+			if _, err := os.Lstat(filepath.Join(dir, "secret")); err == nil {
+				t.Error("extracted a link to the file outside the directory")
+			}
+		})
+	}
+}
+
+func TestExtractArchiveReplacesHardLinks(t *testing.T) {
+	root := t.TempDir()
+	outside := filepath.Join(root, "secret")
+	writeFile(t, outside, "old")
+	dir := filepath.Join(root, "volume")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("creating volume directory: %v", err)
+	}
+	if err := os.Link(outside, filepath.Join(dir, "data")); err != nil {
+		t.Fatalf("creating hard link: %v", err)
+	}
+
+	if err := extractArchive(tarball(t, entry{name: "data", body: "new", typeflag: tar.TypeReg}), dir); err != nil {
+		t.Fatalf("extracting archive: %v", err)
+	}
+	if got := readFile(t, filepath.Join(dir, "data")); got != "new" {
+		t.Errorf("data = %q, want new", got)
+	}
+	if got := readFile(t, outside); got != "old" {
+		t.Errorf("file hard linked from the directory changed to %q", got)
+	}
+}
+
+func TestContainerdImportVolumeKeepsDataOnFailure(t *testing.T) {
+	ctx := context.Background()
+	c := &ContainerdRuntime{volumeDir: t.TempDir()}
+	if _, err := c.CreateVolume(ctx, models.Volume{Name: "data"}); err != nil {
+		t.Fatalf("creating volume: %v", err)
+	}
+	writeFile(t, filepath.Join(c.volumePath("data"), "old"), "old")
+
+	broken := tarball(t,
+		entry{name: "new", body: "new", typeflag: tar.TypeReg},
+		entry{name: "../escape", body: "new", typeflag: tar.TypeReg},
+	)
+	err := c.ImportVolume(ctx, "data", broken)
+	if err == nil || errors.Is(err, ErrVolumeIncomplete) {
+		t.Fatalf("importing a broken archive = %v, want an error leaving the volume complete", err)
+	}
+	entries, err := os.ReadDir(c.volumePath("data"))
+	if err != nil || len(entries) != 1 || entries[0].Name() != "old" {
+		t.Errorf("volume holds %v, %v after a failed import, want only its old data", entries, err)
+	}
+
+	if err := c.ImportVolume(ctx, "data", tarball(t, entry{name: "new", body: "new", typeflag: tar.TypeReg})); err != nil {
+		t.Fatalf("importing volume: %v", err)
+	}
+	entries, err = os.ReadDir(c.volumePath("data"))
+	if err != nil || len(entries) != 1 || entries[0].Name() != "new" {
+		t.Errorf("volume holds %v, %v after an import, want only the imported data", entries, err)
+	}
+	if entries, _ := os.ReadDir(c.volumeDir); len(entries) != 1 {
+		t.Errorf("volume directory holds %v, want only the volume", entries)
+	}
+}
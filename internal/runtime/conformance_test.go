@@ -0,0 +1,310 @@
+package runtime
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"podium/internal/models"
+)
+
+// conformanceImage is the image the conformance suite runs its containers
+// from. Its command keeps the container running until it is stopped.
+const conformanceImage = "busybox:latest"
+
+// TestFakeConformance runs the conformance suite against the FakeRuntime.
+func TestFakeConformance(t *testing.T) {
+	testConformance(t, NewFakeRuntime())
+}
+
+// TestDockerConformance runs the conformance suite against the Docker daemon,
+// when there is one.
+func TestDockerConformance(t *testing.T) {
+	if os.Getenv("DOCKER_HOST") == "" {
+		if _, err := os.Stat("/var/run/docker.sock"); err != nil {
+			t.Skip("no Docker daemon")
+		}
+	}
+	rt, err := NewDockerRuntime()
+	if err != nil {
+		t.Skipf("no Docker daemon: %v", err)
+	}
+	testConformance(t, rt)
+}
+
+// TestContainerdConformance runs the conformance suite against containerd,
+// when it is running.
+func TestContainerdConformance(t *testing.T) {
+	const address = "/run/containerd/containerd.sock"
+	if _, err := os.Stat(address); err != nil {
+		t.Skip("no containerd daemon")
+	}
+	rt, err := NewContainerdRuntime(address, "podium-conformance", t.TempDir(), t.TempDir())
+	if err != nil {
+		t.Skipf("no containerd daemon: %v", err)
+	}
+	t.Cleanup(func() { rt.Close() })
+	testConformance(t, rt)
+}
+
+// testConformance checks the behaviour every Runtime must share, which the
+// rest of Podium relies on.
+func testConformance(t *testing.T, rt Runtime) {
+	ctx := context.Background()
+	if err := rt.PullImage(ctx, conformanceImage, nil, nil); err != nil {
+		t.Fatalf("pulling %s: %v", conformanceImage, err)
+	}
+	if exists, err := rt.ImageExists(ctx, conformanceImage); err != nil || !exists {
+		t.Fatalf("ImageExists after pull = %v, %v, want true", exists, err)
+	}
+
+	t.Run("Lifecycle", func(t *testing.T) { testLifecycle(t, rt) })
+	t.Run("Exec", func(t *testing.T) { testExec(t, rt) })
+	t.Run("Volumes", func(t *testing.T) { testVolumes(t, rt) })
+	t.Run("Networks", func(t *testing.T) { testNetworks(t, rt) })
+}
+
+// createConformanceContainer creates a container that runs until stopped
+// and deletes it when the test ends.
+func createConformanceContainer(t *testing.T, rt Runtime, spec models.Container) models.Container {
+	t.Helper()
+
+	spec.ID = fmt.Sprintf("conformance-%d", time.Now().UnixNano())
+	spec.Name = spec.ID
+	spec.Image = conformanceImage
+	spec.Command = []string{"sleep", "3600"}
+	if err := rt.CreateContainer(context.Background(), spec); err != nil {
+		t.Fatalf("creating container: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx := context.Background()
+		rt.StopContainer(ctx, spec.ID)
+		rt.DeleteContainer(ctx, spec.ID)
+	})
+	return spec
+}
+
+func startConformanceContainer(t *testing.T, rt Runtime, id string) {
+	t.Helper()
+
+	if err := rt.StartContainer(context.Background(), id); err != nil {
+		t.Fatalf("starting container: %v", err)
+	}
+	if state, err := rt.GetContainerStatus(context.Background(), id); err != nil || state != models.ContainerStateRunning {
+		t.Fatalf("status after start = %q, %v, want running", state, err)
+	}
+}
+
+func testLifecycle(t *testing.T, rt Runtime) {
+	ctx := context.Background()
+	container := createConformanceContainer(t, rt, models.Container{})
+
+	ids, err := rt.ListContainers(ctx)
+	if err != nil {
+		t.Fatalf("listing containers: %v", err)
+	}
+	if !slices.Contains(ids, container.ID) {
+		t.Errorf("ListContainers = %v, want it to include %s", ids, container.ID)
+	}
+	if state, err := rt.GetContainerStatus(ctx, container.ID); err != nil || state == models.ContainerStateRunning {
+		t.Errorf("status before start = %q, %v, want it not running", state, err)
+	}
+
+	startConformanceContainer(t, rt, container.ID)
+	// Starting a running container is not an error.
+	if err := rt.StartContainer(ctx, container.ID); err != nil {
+		t.Errorf("starting running container: %v", err)
+	}
+
+	if err := rt.PauseContainer(ctx, container.ID); err != nil {
+		t.Fatalf("pausing container: %v", err)
+	}
+	if state, err := rt.GetContainerStatus(ctx, container.ID); err != nil || state != models.ContainerStateRunning {
+		t.Errorf("status while paused = %q, %v, want running", state, err)
+	}
+	if err := rt.UnpauseContainer(ctx, container.ID); err != nil {
+		t.Fatalf("unpausing container: %v", err)
+	}
+
+	if _, err := rt.GetContainerStats(ctx, container.ID); err != nil {
+		t.Errorf("getting stats: %v", err)
+	}
+	if err := rt.GetContainerLogs(ctx, container.ID, LogOptions{Tail: -1, Stdout: true, Stderr: true}, func(LogEntry) error { return nil }); err != nil {
+		t.Errorf("getting logs: %v", err)
+	}
+
+	ip, err := rt.ContainerIP(ctx, container.ID)
+	switch {
+	case errors.Is(err, ErrNoContainerIP):
+	case err != nil:
+		t.Errorf("getting container IP: %v", err)
+	case net.ParseIP(ip) == nil:
+		t.Errorf("ContainerIP = %q, want an IP address", ip)
+	}
+	if host, err := rt.HostAddress(ctx); err != nil || net.ParseIP(host) == nil {
+		t.Errorf("HostAddress = %q, %v, want an IP address", host, err)
+	}
+
+	if err := rt.StopContainer(ctx, container.ID); err != nil {
+		t.Fatalf("stopping container: %v", err)
+	}
+	if state, err := rt.GetContainerStatus(ctx, container.ID); err != nil || state == models.ContainerStateRunning {
+		t.Errorf("status after stop = %q, %v, want it stopped", state, err)
+	}
+
+	// A stopped container can be started again.
+	startConformanceContainer(t, rt, container.ID)
+	if err := rt.StopContainer(ctx, container.ID); err != nil {
+		t.Fatalf("stopping container again: %v", err)
+	}
+
+	if err := rt.DeleteContainer(ctx, container.ID); err != nil {
+		t.Fatalf("deleting container: %v", err)
+	}
+	if _, err := rt.GetContainerStatus(ctx, container.ID); err == nil {
+		t.Errorf("status of deleted container succeeded, want an error")
+	}
+	ids, err = rt.ListContainers(ctx)
+	if err != nil {
+		t.Fatalf("listing containers: %v", err)
+	}
+	if slices.Contains(ids, container.ID) {
+		t.Errorf("ListContainers = %v, want it not to include deleted %s", ids, container.ID)
+	}
+}
+
+func testExec(t *testing.T, rt Runtime) {
+	ctx := context.Background()
+	container := createConformanceContainer(t, rt, models.Container{})
+	startConformanceContainer(t, rt, container.ID)
+
+	result, err := rt.Exec(ctx, container.ID, []string{"echo", "conformance"})
+	if err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if result.ExitCode != 0 || !strings.Contains(result.Output, "conformance") {
+		t.Errorf("exec = %+v, want exit code 0 and output containing %q", result, "conformance")
+	}
+
+	var stdout bytes.Buffer
+	session, err := rt.StartExec(ctx, container.ID, ExecOptions{Cmd: []string{"echo", "session"}}, &stdout, io.Discard)
+	if err != nil {
+		t.Fatalf("starting exec: %v", err)
+	}
+	defer session.Close()
+	waitCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if code, err := session.Wait(waitCtx); err != nil || code != 0 {
+		t.Errorf("exec session exited with %d, %v, want 0", code, err)
+	}
+	if !strings.Contains(stdout.String(), "session") {
+		t.Errorf("exec session output = %q, want it to contain %q", stdout.String(), "session")
+	}
+
+	if err := rt.StopContainer(ctx, container.ID); err != nil {
+		t.Fatalf("stopping container: %v", err)
+	}
+	if _, err := rt.Exec(ctx, container.ID, []string{"true"}); err == nil {
+		t.Errorf("exec in stopped container succeeded, want an error")
+	}
+}
+
+func testVolumes(t *testing.T, rt Runtime) {
+	ctx := context.Background()
+	volume := models.Volume{Name: fmt.Sprintf("conformance-%d", time.Now().UnixNano())}
+	if _, err := rt.CreateVolume(ctx, volume); err != nil {
+		t.Fatalf("creating volume: %v", err)
+	}
+	t.Cleanup(func() { rt.DeleteVolume(context.Background(), volume.Name) })
+	// Creating an existing volume is not an error.
+	if _, err := rt.CreateVolume(ctx, volume); err != nil {
+		t.Errorf("creating existing volume: %v", err)
+	}
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	content := []byte("conformance\n")
+	tw.WriteHeader(&tar.Header{Name: "data.txt", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	tw.Write(content)
+	tw.Close()
+	if err := rt.ImportVolume(ctx, volume.Name, &archive); err != nil {
+		t.Fatalf("importing volume: %v", err)
+	}
+
+	var exported bytes.Buffer
+	if err := rt.ExportVolume(ctx, volume.Name, &exported); err != nil {
+		t.Fatalf("exporting volume: %v", err)
+	}
+	found := false
+	tr := tar.NewReader(&exported)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		if strings.TrimPrefix(header.Name, "./") != "data.txt" {
+			continue
+		}
+		data, _ := io.ReadAll(tr)
+		found = bytes.Equal(data, content)
+	}
+	if !found {
+		t.Errorf("exported volume does not hold the imported data.txt")
+	}
+
+	if err := rt.DeleteVolume(ctx, volume.Name); err != nil {
+		t.Fatalf("deleting volume: %v", err)
+	}
+	// Deleting a missing volume is not an error.
+	if err := rt.DeleteVolume(ctx, volume.Name); err != nil {
+		t.Errorf("deleting missing volume: %v", err)
+	}
+}
+
+func testNetworks(t *testing.T, rt Runtime) {
+	ctx := context.Background()
+	network := models.Network{Name: fmt.Sprintf("conformance-%d", time.Now().UnixNano())}
+	err := rt.CreateNetwork(ctx, network)
+	if errors.Is(err, errNetworksUnsupported) {
+		t.Skip("runtime does not support networks")
+	}
+	if err != nil {
+		t.Fatalf("creating network: %v", err)
+	}
+	t.Cleanup(func() { rt.DeleteNetwork(context.Background(), network.Name) })
+	// Creating an existing network is not an error.
+	if err := rt.CreateNetwork(ctx, network); err != nil {
+		t.Errorf("creating existing network: %v", err)
+	}
+
+	container := createConformanceContainer(t, rt, models.Container{
+		Networks: []models.NetworkAttachment{{Network: network.Name, Aliases: []string{"conformance"}}},
+	})
+	startConformanceContainer(t, rt, container.ID)
+	if err := rt.DeleteNetwork(ctx, network.Name); err == nil {
+		t.Errorf("deleting network in use succeeded, want an error")
+	}
+
+	if err := rt.StopContainer(ctx, container.ID); err != nil {
+		t.Fatalf("stopping container: %v", err)
+	}
+	if err := rt.DeleteContainer(ctx, container.ID); err != nil {
+		t.Fatalf("deleting container: %v", err)
+	}
+	if err := rt.DeleteNetwork(ctx, network.Name); err != nil {
+		t.Fatalf("deleting network: %v", err)
+	}
+	// Deleting a missing network is not an error.
+	if err := rt.DeleteNetwork(ctx, network.Name); err != nil {
+		t.Errorf("deleting missing network: %v", err)
+	}
+}
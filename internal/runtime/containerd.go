@@ -0,0 +1,835 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/containerd/containerd/v2/pkg/cio"
+	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/errdefs"
+	"github.com/google/uuid"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"podium/internal/models"
+)
+
+const cpuPeriod = 100000
+
+// ContainerdRuntime runs containers directly on containerd, for hosts that
+// have no Docker daemon. Container output is recorded by the daemon, one
+// JSON-lines log file per container under logDir.
+type ContainerdRuntime struct {
+	client *containerd.Client
+	logDir string
+	// volumeDir holds a directory for each volume, which is bind mounted
+	// into the containers that use it.
+	volumeDir string
+}
+
+func NewContainerdRuntime(address, namespace, logDir, volumeDir string) (*ContainerdRuntime, error) {
+	log.Printf("Initializing containerd client: address=%s, namespace=%s", address, namespace)
+	cli, err := containerd.New(address, containerd.WithDefaultNamespace(namespace))
+	if err != nil {
+		log.Printf("Error creating containerd client: %v", err)
+		return nil, fmt.Errorf("failed to create containerd client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	log.Println("Testing containerd connection")
+	if _, err := cli.Version(ctx); err != nil {
+		cli.Close()
+		log.Printf("Error connecting to containerd: %v", err)
+		return nil, fmt.Errorf("failed to connect to containerd: %w", err)
+	}
+	log.Println("containerd connection test successful")
+
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		cli.Close()
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	volumeDir, err = filepath.Abs(volumeDir)
+	if err == nil {
+		err = os.MkdirAll(volumeDir, 0755)
+	}
+	if err != nil {
+		cli.Close()
+		return nil, fmt.Errorf("failed to create volume directory: %w", err)
+	}
+
+	c := &ContainerdRuntime{
+		client:    cli,
+		logDir:    logDir,
+		volumeDir: volumeDir,
+	}
+	c.reattachLogs(ctx)
+	return c, nil
+}
+
+func (c *ContainerdRuntime) Close() error {
+	return c.client.Close()
+}
+
+func (c *ContainerdRuntime) logPath(id string) string {
+	return filepath.Join(c.logDir, id+".log")
+}
+
+func (c *ContainerdRuntime) resolvConfPath(id string) string {
+	return filepath.Join(c.logDir, id+".resolv.conf")
+}
+
+// writeResolvConf writes the resolv.conf of a container with its own name
+// servers next to its log file.
+func (c *ContainerdRuntime) writeResolvConf(spec models.Container) (string, error) {
+	var b strings.Builder
+	for _, server := range spec.DNS {
+		fmt.Fprintf(&b, "nameserver %s\n", server)
+	}
+	if len(spec.DNSSearch) > 0 {
+		fmt.Fprintf(&b, "search %s\n", strings.Join(spec.DNSSearch, " "))
+	}
+	path := c.resolvConfPath(spec.ID)
+	return path, os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+func (c *ContainerdRuntime) ImageExists(ctx context.Context, ref string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if _, err := c.client.GetImage(ctx, normalizeImageRef(ref)); err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to inspect image: %w", err)
+	}
+	return true, nil
+}
+
+func (c *ContainerdRuntime) PullImage(ctx context.Context, ref string, auth *models.RegistryCredential, progress func(PullProgress)) error {
+	log.Printf("Pulling image: %s", ref)
+
+	var authorizerOpts []docker.AuthorizerOpt
+	if auth != nil {
+		log.Printf("Using credentials for registry %s", auth.Registry)
+		authorizerOpts = append(authorizerOpts, docker.WithAuthCreds(func(host string) (string, string, error) {
+			return auth.Username, auth.Password, nil
+		}))
+	}
+	resolver := docker.NewResolver(docker.ResolverOptions{
+		Hosts: docker.ConfigureDefaultRegistries(
+			docker.WithAuthorizer(docker.NewDockerAuthorizer(authorizerOpts...)),
+		),
+	})
+
+	// containerd reports descriptors as they are fetched rather than byte
+	// counts, so each layer is reported once with its total size.
+	handler := images.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		if progress != nil && images.IsLayerType(desc.MediaType) {
+			progress(PullProgress{
+				Layer:  desc.Digest.Encoded()[:12],
+				Status: "Downloading",
+				Total:  desc.Size,
+			})
+		}
+		return nil, nil
+	})
+
+	if _, err := c.client.Pull(ctx, normalizeImageRef(ref),
+		containerd.WithPullUnpack,
+		containerd.WithResolver(resolver),
+		containerd.WithImageHandler(handler),
+	); err != nil {
+		log.Printf("Error pulling image %s: %v", ref, err)
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+
+	log.Printf("Image pulled successfully: %s", ref)
+	return nil
+}
+
+func (c *ContainerdRuntime) CreateContainer(ctx context.Context, spec models.Container) error {
+	log.Printf("Creating container: name=%s, image=%s", spec.Name, spec.Image)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	image, err := c.client.GetImage(ctx, normalizeImageRef(spec.Image))
+	if err != nil {
+		log.Printf("Error loading image: %v", err)
+		return fmt.Errorf("failed to load image: %w", err)
+	}
+
+	env := []string{}
+	for k, v := range spec.Env {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	specOpts := []oci.SpecOpts{
+		oci.WithImageConfig(image),
+		oci.WithEnv(env),
+	}
+	if len(spec.Command) > 0 {
+		specOpts = append(specOpts, oci.WithProcessArgs(spec.Command...))
+	}
+
+	if len(spec.Networks) > 0 {
+		return errNetworksUnsupported
+	}
+
+	// containerd has no networking of its own, and Podium sets up no CNI
+	// plugins, so every container shares the host network: those that
+	// expose ports listen on the host directly.
+	for _, port := range spec.Ports {
+		if port.HostPort != 0 && port.HostPort != port.ContainerPort {
+			log.Printf("Warning: containerd runtime cannot remap port %d to host port %d, using host networking",
+				port.ContainerPort, port.HostPort)
+		}
+	}
+	specOpts = append(specOpts,
+		oci.WithHostNamespace(specs.NetworkNamespace),
+		oci.WithHostHostsFile,
+	)
+	if len(spec.DNS) > 0 || len(spec.DNSSearch) > 0 {
+		path, err := c.writeResolvConf(spec)
+		if err != nil {
+			log.Printf("Error writing resolv.conf: %v", err)
+			return fmt.Errorf("failed to write resolv.conf: %w", err)
+		}
+		specOpts = append(specOpts, oci.WithMounts([]specs.Mount{{
+			Destination: "/etc/resolv.conf",
+			Type:        "bind",
+			Source:      path,
+			Options:     []string{"rbind", "ro"},
+		}}))
+	} else {
+		specOpts = append(specOpts, oci.WithHostResolvconf)
+	}
+
+	if len(spec.Mounts) > 0 {
+		mounts, err := c.specMounts(spec.Mounts)
+		if err != nil {
+			return err
+		}
+		specOpts = append(specOpts, oci.WithMounts(mounts))
+	}
+
+	if spec.Resources.CPULimit > 0 {
+		specOpts = append(specOpts, oci.WithCPUCFS(int64(spec.Resources.CPULimit*cpuPeriod), cpuPeriod))
+		log.Printf("CPU limit set to: %v cores", spec.Resources.CPULimit)
+	}
+	if spec.Resources.MemoryLimit > 0 {
+		specOpts = append(specOpts, oci.WithMemoryLimit(uint64(spec.Resources.MemoryLimit)))
+		log.Printf("Memory limit set to: %v bytes", spec.Resources.MemoryLimit)
+	}
+
+	labels := map[string]string{}
+	for k, v := range spec.Labels {
+		labels[k] = v
+	}
+	labels["podium.container.id"] = spec.ID
+	labels["podium.container.name"] = spec.Name
+
+	log.Printf("Calling containerd to create container with ID: %s", spec.ID)
+	_, err = c.client.NewContainer(
+		ctx,
+		spec.ID,
+		containerd.WithImage(image),
+		containerd.WithNewSnapshot(spec.ID+"-snapshot", image),
+		containerd.WithNewSpec(specOpts...),
+		containerd.WithContainerLabels(labels),
+	)
+	if err != nil {
+		log.Printf("Error creating container: %v", err)
+		return fmt.Errorf("failed to create container: %w", err)
+	}
+
+	log.Printf("Container created successfully with ID: %s", spec.ID)
+	return nil
+}
+
+func (c *ContainerdRuntime) StartContainer(ctx context.Context, id string) error {
+	log.Printf("Starting container: %s", id)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cont, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		log.Printf("Error loading container %s: %v", id, err)
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+
+	// A task that has already exited must be deleted before a new one can
+	// be created for the same container.
+	if task, err := cont.Task(ctx, nil); err == nil {
+		status, err := task.Status(ctx)
+		if err == nil && status.Status == containerd.Running {
+			return nil
+		}
+		if _, err := task.Delete(ctx, containerd.WithProcessKill); err != nil {
+			log.Printf("Warning: Failed to delete previous task of container %s: %v", id, err)
+		}
+	}
+
+	containerLog, err := c.openLog(id)
+	if err != nil {
+		log.Printf("Error opening log file for container %s: %v", id, err)
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+
+	task, err := cont.NewTask(ctx, containerLog.creator())
+	if err != nil {
+		containerLog.Close()
+		log.Printf("Error creating task for container %s: %v", id, err)
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+
+	if err := task.Start(ctx); err != nil {
+		task.Delete(ctx)
+		containerLog.Close()
+		log.Printf("Error starting container %s: %v", id, err)
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+	go c.closeLogOnExit(id, task, containerLog)
+
+	log.Printf("Container %s started successfully", id)
+	return nil
+}
+
+func (c *ContainerdRuntime) StopContainer(ctx context.Context, id string) error {
+	log.Printf("Stopping container: %s", id)
+
+	ctx, cancel := context.WithTimeout(ctx, 40*time.Second)
+	defer cancel()
+
+	cont, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		log.Printf("Error loading container %s: %v", id, err)
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+
+	task, err := cont.Task(ctx, nil)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+
+	if err := c.killTask(ctx, task, 30*time.Second); err != nil {
+		log.Printf("Error stopping container %s: %v", id, err)
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+
+	log.Printf("Container %s stopped successfully", id)
+	return nil
+}
+
+func (c *ContainerdRuntime) PauseContainer(ctx context.Context, id string) error {
+	log.Printf("Pausing container: %s", id)
+
+	task, err := c.loadTask(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to pause container: %w", err)
+	}
+	if err := task.Pause(ctx); err != nil {
+		log.Printf("Error pausing container %s: %v", id, err)
+		return fmt.Errorf("failed to pause container: %w", err)
+	}
+	return nil
+}
+
+func (c *ContainerdRuntime) UnpauseContainer(ctx context.Context, id string) error {
+	log.Printf("Unpausing container: %s", id)
+
+	task, err := c.loadTask(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to unpause container: %w", err)
+	}
+	if err := task.Resume(ctx); err != nil {
+		log.Printf("Error unpausing container %s: %v", id, err)
+		return fmt.Errorf("failed to unpause container: %w", err)
+	}
+	return nil
+}
+
+func (c *ContainerdRuntime) loadTask(ctx context.Context, id string) (containerd.Task, error) {
+	cont, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return cont.Task(ctx, nil)
+}
+
+// killTask sends SIGTERM to the task and escalates to SIGKILL if it has not
+// exited within timeout. The exited task is kept so its exit code remains
+// available to GetContainerStatus.
+func (c *ContainerdRuntime) killTask(ctx context.Context, task containerd.Task, timeout time.Duration) error {
+	status, err := task.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Status == containerd.Stopped {
+		return nil
+	}
+
+	exitCh, err := task.Wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := task.Kill(ctx, syscall.SIGTERM); err != nil && !errdefs.IsNotFound(err) {
+		return err
+	}
+
+	select {
+	case <-exitCh:
+		return nil
+	case <-time.After(timeout):
+		log.Printf("Task %s did not exit after SIGTERM, sending SIGKILL", task.ID())
+	}
+
+	if err := task.Kill(ctx, syscall.SIGKILL); err != nil && !errdefs.IsNotFound(err) {
+		return err
+	}
+
+	select {
+	case <-exitCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *ContainerdRuntime) DeleteContainer(ctx context.Context, id string) error {
+	log.Printf("Deleting container: %s", id)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cont, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		log.Printf("Error loading container %s: %v", id, err)
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+
+	if task, err := cont.Task(ctx, nil); err == nil {
+		if _, err := task.Delete(ctx, containerd.WithProcessKill); err != nil && !errdefs.IsNotFound(err) {
+			log.Printf("Error deleting task of container %s: %v", id, err)
+			return fmt.Errorf("failed to remove container: %w", err)
+		}
+	}
+
+	if err := cont.Delete(ctx, containerd.WithSnapshotCleanup); err != nil {
+		log.Printf("Error removing container %s: %v", id, err)
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+
+	if err := os.Remove(c.logPath(id)); err != nil && !os.IsNotExist(err) {
+		log.Printf("Warning: Failed to remove log file of container %s: %v", id, err)
+	}
+	if err := os.Remove(c.resolvConfPath(id)); err != nil && !os.IsNotExist(err) {
+		log.Printf("Warning: Failed to remove resolv.conf of container %s: %v", id, err)
+	}
+
+	log.Printf("Container %s removed successfully", id)
+	return nil
+}
+
+func (c *ContainerdRuntime) ListContainers(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	containers, err := c.client.Containers(ctx, `labels."podium.container.id"`)
+	if err != nil {
+		log.Printf("Error listing containers: %v", err)
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	ids := make([]string, 0, len(containers))
+	for _, cont := range containers {
+		ids = append(ids, cont.ID())
+	}
+	return ids, nil
+}
+
+func (c *ContainerdRuntime) GetContainerStatus(ctx context.Context, id string) (models.ContainerState, error) {
+	log.Printf("Getting status for container: %s", id)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cont, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		log.Printf("Error inspecting container %s: %v", id, err)
+		return "", fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	task, err := cont.Task(ctx, nil)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			// Never started, matching Docker's view of a created container.
+			return models.ContainerStateSucceeded, nil
+		}
+		return "", fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	status, err := task.Status(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	var state models.ContainerState
+	switch {
+	case status.Status == containerd.Running || status.Status == containerd.Paused:
+		state = models.ContainerStateRunning
+	case status.Status == containerd.Created:
+		state = models.ContainerStatePending
+	case status.ExitStatus == 0:
+		state = models.ContainerStateSucceeded
+	default:
+		state = models.ContainerStateFailed
+	}
+
+	log.Printf("Container %s status: %s", id, state)
+	return state, nil
+}
+
+// ContainerAddress returns the port on the host, since containers share the
+// host network.
+func (c *ContainerdRuntime) ContainerAddress(ctx context.Context, id string, containerPort int) (string, error) {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(containerPort)), nil
+}
+
+// specMounts converts mounts to OCI mounts. Volumes are directories under
+// the volume directory, bind mounted like host paths.
+func (c *ContainerdRuntime) specMounts(mounts []models.Mount) ([]specs.Mount, error) {
+	var result []specs.Mount
+	for _, m := range mounts {
+		access := "rw"
+		if m.ReadOnly {
+			access = "ro"
+		}
+		switch m.Type {
+		case models.MountTypeTmpfs:
+			options := []string{"nosuid", "nodev", access}
+			if m.TmpfsSize > 0 {
+				options = append(options, fmt.Sprintf("size=%d", m.TmpfsSize))
+			}
+			result = append(result, specs.Mount{Destination: m.Target, Type: "tmpfs", Source: "tmpfs", Options: options})
+		case models.MountTypeBind:
+			result = append(result, specs.Mount{Destination: m.Target, Type: "bind", Source: m.Source, Options: []string{"rbind", access}})
+		default:
+			source := c.volumePath(m.Source)
+			if _, err := os.Stat(source); err != nil {
+				return nil, fmt.Errorf("volume %s not found", m.Source)
+			}
+			result = append(result, specs.Mount{Destination: m.Target, Type: "bind", Source: source, Options: []string{"rbind", access}})
+		}
+	}
+	return result, nil
+}
+
+func (c *ContainerdRuntime) volumePath(name string) string {
+	return filepath.Join(c.volumeDir, name)
+}
+
+func (c *ContainerdRuntime) CreateVolume(ctx context.Context, volume models.Volume) (string, error) {
+	log.Printf("Creating volume: %s", volume.Name)
+	path := c.volumePath(volume.Name)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		log.Printf("Error creating volume %s: %v", volume.Name, err)
+		return "", fmt.Errorf("failed to create volume: %w", err)
+	}
+	return path, nil
+}
+
+func (c *ContainerdRuntime) DeleteVolume(ctx context.Context, name string) error {
+	log.Printf("Removing volume: %s", name)
+	if err := os.RemoveAll(c.volumePath(name)); err != nil {
+		log.Printf("Error removing volume %s: %v", name, err)
+		return fmt.Errorf("failed to remove volume: %w", err)
+	}
+	return nil
+}
+
+func (c *ContainerdRuntime) ExportVolume(ctx context.Context, name string, w io.Writer) error {
+	log.Printf("Exporting volume: %s", name)
+	path := c.volumePath(name)
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to export volume: %w", err)
+	}
+	return writeArchive(path, w)
+}
+
+// ImportVolume unpacks the archive next to the volume and swaps it in once
+// it is complete, so a broken archive leaves the volume as it was.
+func (c *ContainerdRuntime) ImportVolume(ctx context.Context, name string, r io.Reader) error {
+	log.Printf("Importing volume: %s", name)
+	path := c.volumePath(name)
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to import volume: %w", err)
+	}
+
+	// Volume names start with a letter or digit, so the staging directory
+	// cannot be taken for a volume.
+	staging, err := os.MkdirTemp(c.volumeDir, ".import-"+name+"-")
+	if err != nil {
+		return fmt.Errorf("failed to import volume: %w", err)
+	}
+	defer os.RemoveAll(staging)
+	if err := os.Chmod(staging, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to import volume: %w", err)
+	}
+	if err := extractArchive(r, staging); err != nil {
+		log.Printf("Error importing volume %s: %v", name, err)
+		return fmt.Errorf("failed to import volume: %w", err)
+	}
+
+	previous := staging + "-previous"
+	if err := os.Rename(path, previous); err != nil {
+		return fmt.Errorf("failed to import volume: %w", err)
+	}
+	if err := os.Rename(staging, path); err != nil {
+		if restoreErr := os.Rename(previous, path); restoreErr != nil {
+			log.Printf("Error importing volume %s: its previous data was left in %s", name, previous)
+			return fmt.Errorf("failed to import volume: %w: %w", ErrVolumeIncomplete, err)
+		}
+		return fmt.Errorf("failed to import volume: %w", err)
+	}
+	if err := os.RemoveAll(previous); err != nil {
+		log.Printf("Warning: Failed to remove the previous data of volume %s: %v", name, err)
+	}
+	return nil
+}
+
+// errNetworksUnsupported is returned for networks, which containerd leaves to
+// CNI plugins that Podium does not manage.
+var errNetworksUnsupported = errors.New("the containerd runtime does not support networks")
+
+func (c *ContainerdRuntime) CreateNetwork(ctx context.Context, network models.Network) error {
+	return errNetworksUnsupported
+}
+
+func (c *ContainerdRuntime) DeleteNetwork(ctx context.Context, name string) error {
+	return errNetworksUnsupported
+}
+
+// ContainerIP fails with ErrNoContainerIP, since containers share the host
+// network and have no address of their own.
+func (c *ContainerdRuntime) ContainerIP(ctx context.Context, id string) (string, error) {
+	return "", ErrNoContainerIP
+}
+
+// HostAddress returns the loopback address, which containers sharing the
+// host network reach the host at.
+func (c *ContainerdRuntime) HostAddress(ctx context.Context) (string, error) {
+	return "127.0.0.1", nil
+}
+
+func (c *ContainerdRuntime) Exec(ctx context.Context, id string, cmd []string) (ExecResult, error) {
+	log.Printf("Executing command in container %s: %v", id, cmd)
+
+	cont, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	task, err := cont.Task(ctx, nil)
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("failed to create exec: container is not running: %w", err)
+	}
+
+	spec, err := cont.Spec(ctx)
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("failed to create exec: %w", err)
+	}
+	processSpec := *spec.Process
+	processSpec.Args = cmd
+	processSpec.Terminal = false
+
+	var output bytes.Buffer
+	process, err := task.Exec(ctx, "exec-"+uuid.New().String()[:8], &processSpec,
+		cio.NewCreator(cio.WithStreams(nil, &output, &output)))
+	if err != nil {
+		log.Printf("Error creating exec in container %s: %v", id, err)
+		return ExecResult{}, fmt.Errorf("failed to create exec: %w", err)
+	}
+	defer process.Delete(context.Background(), containerd.WithProcessKill)
+
+	exitCh, err := process.Wait(ctx)
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("failed to wait for exec: %w", err)
+	}
+
+	if err := process.Start(ctx); err != nil {
+		return ExecResult{}, fmt.Errorf("failed to start exec: %w", err)
+	}
+
+	select {
+	case exitStatus := <-exitCh:
+		code, _, err := exitStatus.Result()
+		if err != nil {
+			return ExecResult{}, fmt.Errorf("failed to get exec result: %w", err)
+		}
+		process.IO().Wait()
+		return ExecResult{
+			ExitCode: int(code),
+			Output:   output.String(),
+		}, nil
+	case <-ctx.Done():
+		return ExecResult{}, fmt.Errorf("exec timed out: %w", ctx.Err())
+	}
+}
+
+type containerdExecSession struct {
+	process containerd.Process
+	stdin   *io.PipeWriter
+	exitCh  <-chan containerd.ExitStatus
+}
+
+func (c *ContainerdRuntime) StartExec(ctx context.Context, id string, opts ExecOptions, stdout, stderr io.Writer) (ExecSession, error) {
+	log.Printf("Starting interactive exec in container %s: %v (tty=%v)", id, opts.Cmd, opts.TTY)
+
+	cont, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	task, err := cont.Task(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec: container is not running: %w", err)
+	}
+
+	spec, err := cont.Spec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec: %w", err)
+	}
+	processSpec := *spec.Process
+	processSpec.Args = opts.Cmd
+	processSpec.Terminal = opts.TTY
+
+	session := &containerdExecSession{}
+
+	var stdinReader io.Reader
+	if opts.Stdin {
+		pipeReader, pipeWriter := io.Pipe()
+		stdinReader = pipeReader
+		session.stdin = pipeWriter
+	}
+
+	ioOpts := []cio.Opt{cio.WithStreams(stdinReader, stdout, stderr)}
+	if opts.TTY {
+		ioOpts = append(ioOpts, cio.WithTerminal)
+	}
+
+	process, err := task.Exec(ctx, "exec-"+uuid.New().String()[:8], &processSpec, cio.NewCreator(ioOpts...))
+	if err != nil {
+		log.Printf("Error creating exec in container %s: %v", id, err)
+		return nil, fmt.Errorf("failed to create exec: %w", err)
+	}
+	session.process = process
+
+	// The exit channel outlives this request's context.
+	exitCh, err := process.Wait(context.Background())
+	if err != nil {
+		process.Delete(ctx, containerd.WithProcessKill)
+		return nil, fmt.Errorf("failed to wait for exec: %w", err)
+	}
+	session.exitCh = exitCh
+
+	if err := process.Start(ctx); err != nil {
+		process.Delete(ctx, containerd.WithProcessKill)
+		return nil, fmt.Errorf("failed to start exec: %w", err)
+	}
+
+	if opts.TTY && opts.Width > 0 && opts.Height > 0 {
+		if err := process.Resize(ctx, uint32(opts.Width), uint32(opts.Height)); err != nil {
+			log.Printf("Warning: Failed to set initial terminal size: %v", err)
+		}
+	}
+
+	return session, nil
+}
+
+func (s *containerdExecSession) Write(p []byte) (int, error) {
+	if s.stdin == nil {
+		return 0, fmt.Errorf("stdin is not attached")
+	}
+	return s.stdin.Write(p)
+}
+
+func (s *containerdExecSession) CloseStdin() error {
+	if s.stdin == nil {
+		return nil
+	}
+	s.stdin.Close()
+	return s.process.CloseIO(context.Background(), containerd.WithStdinCloser)
+}
+
+func (s *containerdExecSession) Resize(ctx context.Context, width, height uint) error {
+	return s.process.Resize(ctx, uint32(width), uint32(height))
+}
+
+func (s *containerdExecSession) Wait(ctx context.Context) (int, error) {
+	select {
+	case exitStatus := <-s.exitCh:
+		code, _, err := exitStatus.Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get exec result: %w", err)
+		}
+		s.process.IO().Wait()
+		return int(code), nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func (s *containerdExecSession) Close() error {
+	if s.stdin != nil {
+		s.stdin.Close()
+	}
+	_, err := s.process.Delete(context.Background(), containerd.WithProcessKill)
+	if err != nil && !errdefs.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// normalizeImageRef expands short Docker-style references such as "nginx" or
+// "nginx:latest" into the fully qualified form containerd requires.
+func normalizeImageRef(ref string) string {
+	name := ref
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	if !strings.Contains(name[strings.LastIndex(name, "/")+1:], ":") && !strings.Contains(ref, "@") {
+		ref += ":latest"
+	}
+
+	first := strings.SplitN(ref, "/", 2)[0]
+	if !strings.Contains(ref, "/") {
+		return "docker.io/library/" + ref
+	}
+	if !strings.ContainsAny(first, ".:") && first != "localhost" {
+		return "docker.io/" + ref
+	}
+	return ref
+}
@@ -0,0 +1,230 @@
+package runtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/cio"
+)
+
+const logFollowInterval = 250 * time.Millisecond
+
+// errLogsDone stops reading a log file once entries pass LogOptions.Until.
+var errLogsDone = errors.New("end of requested logs")
+
+// logRecord is one line of a containerd log file, in the same shape as
+// Docker's json-file log driver.
+type logRecord struct {
+	Stream string    `json:"stream"`
+	Time   time.Time `json:"time"`
+	Log    string    `json:"log"`
+}
+
+// containerLog records a task's stdout and stderr to the container's log
+// file, timestamping each line as it arrives.
+type containerLog struct {
+	mu     sync.Mutex
+	file   *os.File
+	stdout *logLineWriter
+	stderr *logLineWriter
+}
+
+func (c *ContainerdRuntime) openLog(id string) (*containerLog, error) {
+	file, err := os.OpenFile(c.logPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	l := &containerLog{file: file}
+	l.stdout = &logLineWriter{stream: LogStreamStdout, fn: l.write}
+	l.stderr = &logLineWriter{stream: LogStreamStderr, fn: l.write}
+	return l, nil
+}
+
+func (l *containerLog) creator() cio.Creator {
+	return cio.NewCreator(cio.WithStreams(nil, l.stdout, l.stderr))
+}
+
+func (l *containerLog) attach() cio.Attach {
+	return cio.NewAttach(cio.WithStreams(nil, l.stdout, l.stderr))
+}
+
+func (l *containerLog) write(entry LogEntry) error {
+	data, err := json.Marshal(logRecord{Stream: entry.Stream, Time: time.Now().UTC(), Log: entry.Line})
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(append(data, '\n'))
+	return err
+}
+
+func (l *containerLog) Close() error {
+	l.stdout.Flush()
+	l.stderr.Flush()
+	return l.file.Close()
+}
+
+// closeLogOnExit closes the log once the task has exited and its output has
+// been copied.
+func (c *ContainerdRuntime) closeLogOnExit(id string, task containerd.Task, l *containerLog) {
+	if exitCh, err := task.Wait(context.Background()); err == nil {
+		<-exitCh
+	}
+	task.IO().Wait()
+	if err := l.Close(); err != nil {
+		log.Printf("Warning: Failed to close log file of container %s: %v", id, err)
+	}
+}
+
+// reattachLogs resumes recording the output of tasks that were started by a
+// previous run of the daemon.
+func (c *ContainerdRuntime) reattachLogs(ctx context.Context) {
+	containers, err := c.client.Containers(ctx)
+	if err != nil {
+		log.Printf("Warning: Failed to list containers to reattach logs: %v", err)
+		return
+	}
+
+	for _, cont := range containers {
+		id := cont.ID()
+		l, err := c.openLog(id)
+		if err != nil {
+			log.Printf("Warning: Failed to reattach logs of container %s: %v", id, err)
+			continue
+		}
+		task, err := cont.Task(ctx, l.attach())
+		if err != nil {
+			// No task, so there is no output to record.
+			l.Close()
+			continue
+		}
+		log.Printf("Reattached logs of container %s", id)
+		go c.closeLogOnExit(id, task, l)
+	}
+}
+
+func (c *ContainerdRuntime) GetContainerLogs(ctx context.Context, id string, opts LogOptions, fn func(LogEntry) error) error {
+	log.Printf("Getting logs for container: %s (follow=%t)", id, opts.Follow)
+
+	cont, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		log.Printf("Error getting logs for container %s: %v", id, err)
+		return fmt.Errorf("failed to get container logs: %v", err)
+	}
+
+	f, err := os.Open(c.logPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read container logs: %v", err)
+	}
+	defer f.Close()
+
+	reader := &logFileReader{reader: bufio.NewReader(f), opts: opts}
+
+	tail := &tailBuffer{n: opts.Tail}
+	err = reader.readAvailable(func(entry LogEntry) error {
+		tail.add(entry)
+		return nil
+	})
+	if flushErr := tail.flush(fn); flushErr != nil {
+		return flushErr
+	}
+	if err != nil || !opts.Follow {
+		return ignoreLogsDone(err)
+	}
+
+	ticker := time.NewTicker(logFollowInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		running := c.taskRunning(ctx, cont)
+		if err := reader.readAvailable(fn); err != nil {
+			return ignoreLogsDone(err)
+		}
+		if !running {
+			return nil
+		}
+	}
+}
+
+func (c *ContainerdRuntime) taskRunning(ctx context.Context, cont containerd.Container) bool {
+	task, err := cont.Task(ctx, nil)
+	if err != nil {
+		return false
+	}
+	status, err := task.Status(ctx)
+	return err == nil && status.Status == containerd.Running
+}
+
+func ignoreLogsDone(err error) error {
+	if errors.Is(err, errLogsDone) {
+		return nil
+	}
+	return err
+}
+
+// logFileReader reads the entries of a containerd log file, keeping any
+// incomplete last line until the rest of it has been written.
+type logFileReader struct {
+	reader  *bufio.Reader
+	opts    LogOptions
+	partial string
+}
+
+// readAvailable passes every complete entry written so far that matches the
+// options to fn.
+func (r *logFileReader) readAvailable(fn func(LogEntry) error) error {
+	for {
+		line, err := r.reader.ReadString('\n')
+		if err == io.EOF {
+			r.partial += line
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read container logs: %v", err)
+		}
+		line = r.partial + strings.TrimSuffix(line, "\n")
+		r.partial = ""
+
+		entry := parseLogRecord(line)
+		if !r.opts.Until.IsZero() && entry.Timestamp.After(r.opts.Until) {
+			return errLogsDone
+		}
+		if !r.opts.matches(entry) {
+			continue
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
+
+func parseLogRecord(line string) LogEntry {
+	var record logRecord
+	if err := json.Unmarshal([]byte(line), &record); err != nil || record.Stream == "" {
+		// Log files written before output was recorded per stream hold the
+		// raw output of both streams.
+		return LogEntry{Stream: LogStreamStdout, Line: line}
+	}
+	return LogEntry{Stream: record.Stream, Timestamp: record.Time, Line: record.Log}
+}
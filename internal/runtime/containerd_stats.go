@@ -0,0 +1,99 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	cgroupsv1 "github.com/containerd/cgroups/v3/cgroup1/stats"
+	cgroupsv2 "github.com/containerd/cgroups/v3/cgroup2/stats"
+	"github.com/containerd/typeurl/v2"
+	"podium/internal/models"
+)
+
+// GetContainerStats reads the task's cgroup metrics. Containers share the
+// host network namespace under containerd, so network counters stay zero.
+func (c *ContainerdRuntime) GetContainerStats(ctx context.Context, id string) (models.ContainerStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cont, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		log.Printf("Error getting stats for container %s: %v", id, err)
+		return models.ContainerStats{}, fmt.Errorf("failed to get container stats: %w", err)
+	}
+	task, err := cont.Task(ctx, nil)
+	if err != nil {
+		return models.ContainerStats{}, fmt.Errorf("failed to get container stats: container is not running: %w", err)
+	}
+
+	metric, err := task.Metrics(ctx)
+	if err != nil {
+		log.Printf("Error getting stats for container %s: %v", id, err)
+		return models.ContainerStats{}, fmt.Errorf("failed to get container stats: %w", err)
+	}
+	data, err := typeurl.UnmarshalAny(metric.Data)
+	if err != nil {
+		return models.ContainerStats{}, fmt.Errorf("failed to decode container stats: %w", err)
+	}
+
+	stats := models.ContainerStats{
+		ContainerID: id,
+		Timestamp:   time.Now(),
+	}
+	if metric.Timestamp != nil {
+		stats.Timestamp = metric.Timestamp.AsTime()
+	}
+
+	switch m := data.(type) {
+	case *cgroupsv1.Metrics:
+		if m.CPU != nil && m.CPU.Usage != nil {
+			stats.CPUUsageNanos = m.CPU.Usage.Total
+			stats.OnlineCPUs = len(m.CPU.Usage.PerCPU)
+		}
+		if m.Memory != nil && m.Memory.Usage != nil {
+			stats.MemoryUsage = m.Memory.Usage.Usage
+			stats.MemoryLimit = m.Memory.Usage.Limit
+			if m.Memory.TotalInactiveFile < stats.MemoryUsage {
+				stats.MemoryUsage -= m.Memory.TotalInactiveFile
+			}
+		}
+		if m.Blkio != nil {
+			for _, entry := range m.Blkio.IoServiceBytesRecursive {
+				switch strings.ToLower(entry.Op) {
+				case "read":
+					stats.BlockReadBytes += entry.Value
+				case "write":
+					stats.BlockWriteBytes += entry.Value
+				}
+			}
+		}
+		for _, network := range m.Network {
+			stats.NetworkRxBytes += network.RxBytes
+			stats.NetworkTxBytes += network.TxBytes
+		}
+	case *cgroupsv2.Metrics:
+		if m.CPU != nil {
+			stats.CPUUsageNanos = m.CPU.UsageUsec * 1000
+		}
+		if m.Memory != nil {
+			stats.MemoryUsage = m.Memory.Usage
+			stats.MemoryLimit = m.Memory.UsageLimit
+			if m.Memory.InactiveFile < stats.MemoryUsage {
+				stats.MemoryUsage -= m.Memory.InactiveFile
+			}
+		}
+		if m.Io != nil {
+			for _, entry := range m.Io.Usage {
+				stats.BlockReadBytes += entry.Rbytes
+				stats.BlockWriteBytes += entry.Wbytes
+			}
+		}
+	default:
+		return models.ContainerStats{}, fmt.Errorf("unsupported metrics type %T", data)
+	}
+
+	return stats, nil
+}
@@ -1,15 +1,30 @@
 package runtime
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
-	"os/exec"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 	
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 	"podium/internal/models"
 )
@@ -43,33 +58,87 @@ func NewDockerRuntime() (*DockerRuntime, error) {
 	}, nil
 }
 
-func (d *DockerRuntime) pullImageWithExec(imageName string) error {
-	log.Printf("Pulling image using docker CLI: %s", imageName)
-	
-	cmd := exec.Command("docker", "pull", imageName)
-	output, err := cmd.CombinedOutput()
+// pullMessage is one line of the JSON progress stream returned by ImagePull.
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	Error string `json:"error"`
+}
+
+func (d *DockerRuntime) ImageExists(ctx context.Context, ref string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if _, err := d.client.ImageInspect(ctx, ref); err != nil {
+		if client.IsErrNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to inspect image: %w", err)
+	}
+	return true, nil
+}
+
+func (d *DockerRuntime) PullImage(ctx context.Context, ref string, auth *models.RegistryCredential, progress func(PullProgress)) error {
+	log.Printf("Pulling image: %s", ref)
+
+	options := image.PullOptions{}
+	if auth != nil {
+		log.Printf("Using credentials for registry %s", auth.Registry)
+		encoded, err := registry.EncodeAuthConfig(registry.AuthConfig{
+			Username:      auth.Username,
+			Password:      auth.Password,
+			ServerAddress: auth.Registry,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode registry credentials: %w", err)
+		}
+		options.RegistryAuth = encoded
+	}
+
+	reader, err := d.client.ImagePull(ctx, ref, options)
 	if err != nil {
-		log.Printf("Error pulling image: %v, output: %s", err, string(output))
+		log.Printf("Error pulling image %s: %v", ref, err)
 		return fmt.Errorf("failed to pull image: %w", err)
 	}
-	
-	log.Printf("Image pull output: %s", string(output))
-	log.Printf("Image pulled successfully: %s", imageName)
+	defer reader.Close()
+
+	decoder := json.NewDecoder(reader)
+	for {
+		var msg pullMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("failed to read pull progress: %w", err)
+		}
+		if msg.Error != "" {
+			log.Printf("Error pulling image %s: %s", ref, msg.Error)
+			return fmt.Errorf("failed to pull image: %s", msg.Error)
+		}
+		if progress != nil {
+			progress(PullProgress{
+				Layer:   msg.ID,
+				Status:  msg.Status,
+				Current: msg.ProgressDetail.Current,
+				Total:   msg.ProgressDetail.Total,
+			})
+		}
+	}
+
+	log.Printf("Image pulled successfully: %s", ref)
 	return nil
 }
 
 func (d *DockerRuntime) CreateContainer(ctx context.Context, spec models.Container) error {
 	log.Printf("Creating container: name=%s, image=%s", spec.Name, spec.Image)
 	
-	// Increase timeout to 5 minutes
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 	
-	// Pull the image using docker CLI
-	if err := d.pullImageWithExec(spec.Image); err != nil {
-		return fmt.Errorf("failed to pull image: %w", err)
-	}
-	
 	log.Println("Setting up port bindings")
 	portBindings := nat.PortMap{}
 	exposedPorts := nat.PortSet{}
@@ -98,9 +167,11 @@ func (d *DockerRuntime) CreateContainer(ctx context.Context, spec models.Contain
 	}
 
 	log.Println("Setting up container labels")
-	labels := map[string]string{
-		"podium.container.id": spec.ID,
+	labels := map[string]string{}
+	for k, v := range spec.Labels {
+		labels[k] = v
 	}
+	labels["podium.container.id"] = spec.ID
 
 	log.Println("Setting up resource limits")
 	resources := container.Resources{}
@@ -139,6 +210,23 @@ func (d *DockerRuntime) CreateContainer(ctx context.Context, spec models.Contain
 		PortBindings: portBindings,
 		Resources:    resources,
 		RestartPolicy: restartPolicy,
+		DNS:           spec.DNS,
+		DNSSearch:     spec.DNSSearch,
+		Mounts:        dockerMounts(spec.Mounts),
+	}
+
+	// The container is created on its first network and connected to the
+	// others before it starts.
+	var networkingConfig *network.NetworkingConfig
+	if len(spec.Networks) > 0 {
+		first := spec.Networks[0]
+		log.Printf("Attaching container to network %s", first.Network)
+		hostConfig.NetworkMode = container.NetworkMode(first.Network)
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				first.Network: {Aliases: first.Aliases},
+			},
+		}
 	}
 
 	log.Printf("Calling Docker API to create container with ID: %s", spec.ID)
@@ -146,7 +234,7 @@ func (d *DockerRuntime) CreateContainer(ctx context.Context, spec models.Contain
 		ctx,
 		containerConfig,
 		hostConfig,
-		nil,
+		networkingConfig,
 		nil,
 		spec.ID,
 	)
@@ -158,6 +246,18 @@ func (d *DockerRuntime) CreateContainer(ctx context.Context, spec models.Contain
 		return fmt.Errorf("failed to create container: %w", err)
 	}
 
+	for _, attachment := range spec.Networks[min(1, len(spec.Networks)):] {
+		log.Printf("Attaching container to network %s", attachment.Network)
+		err := d.client.NetworkConnect(ctx, attachment.Network, spec.ID, &network.EndpointSettings{Aliases: attachment.Aliases})
+		if err != nil {
+			log.Printf("Error attaching container to network %s: %v", attachment.Network, err)
+			if removeErr := d.client.ContainerRemove(ctx, spec.ID, container.RemoveOptions{Force: true}); removeErr != nil {
+				log.Printf("Cleanup failed: could not remove container %s: %v", spec.ID, removeErr)
+			}
+			return fmt.Errorf("failed to attach container to network %s: %w", attachment.Network, err)
+		}
+	}
+
 	log.Printf("Container created successfully with ID: %s", spec.ID)
 	return nil
 }
@@ -210,6 +310,26 @@ func (d *DockerRuntime) DeleteContainer(ctx context.Context, id string) error {
 	return nil
 }
 
+func (d *DockerRuntime) ListContainers(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	containers, err := d.client.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", "podium.container.id")),
+	})
+	if err != nil {
+		log.Printf("Error listing containers: %v", err)
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	ids := make([]string, 0, len(containers))
+	for _, c := range containers {
+		ids = append(ids, c.Labels["podium.container.id"])
+	}
+	return ids, nil
+}
+
 func (d *DockerRuntime) GetContainerStatus(ctx context.Context, id string) (models.ContainerState, error) {
 	log.Printf("Getting status for container: %s", id)
 	
@@ -235,32 +355,497 @@ func (d *DockerRuntime) GetContainerStatus(ctx context.Context, id string) (mode
 	return state, nil
 }
 
-func (d *DockerRuntime) GetContainerLogs(ctx context.Context, id string) (string, error) {
-	log.Printf("Getting logs for container: %s", id)
-	
+func (d *DockerRuntime) ContainerAddress(ctx context.Context, id string, containerPort int) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := d.client.ContainerInspect(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container: %w", err)
+	}
+	natPort, err := nat.NewPort("tcp", strconv.Itoa(containerPort))
+	if err != nil {
+		return "", fmt.Errorf("invalid port: %w", err)
+	}
+
+	// A published port is reachable wherever the daemon runs; the bridge
+	// address only from the Docker host itself.
+	if resp.NetworkSettings != nil {
+		for _, binding := range resp.NetworkSettings.Ports[natPort] {
+			if binding.HostPort != "" {
+				return net.JoinHostPort("127.0.0.1", binding.HostPort), nil
+			}
+		}
+		if ip := containerIP(resp); ip != "" {
+			return net.JoinHostPort(ip, strconv.Itoa(containerPort)), nil
+		}
+	}
+	return "", fmt.Errorf("container %s has no address for port %d", id, containerPort)
+}
+
+func (d *DockerRuntime) ContainerIP(ctx context.Context, id string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := d.client.ContainerInspect(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if ip := containerIP(resp); ip != "" {
+		return ip, nil
+	}
+	return "", fmt.Errorf("container %s has no IP address", id)
+}
+
+// containerIP returns the container's address on the default bridge, or on
+// the first of its networks by name if it is not on the default bridge.
+// containerIP returns the address of a container on Docker's default bridge
+// network, which every container can reach, or failing that its address on
+// the first of its networks by name.
+func containerIP(resp container.InspectResponse) string {
+	if resp.NetworkSettings == nil {
+		return ""
+	}
+	if resp.NetworkSettings.IPAddress != "" {
+		return resp.NetworkSettings.IPAddress
+	}
+	if endpoint := resp.NetworkSettings.Networks[network.NetworkBridge]; endpoint != nil && endpoint.IPAddress != "" {
+		return endpoint.IPAddress
+	}
+	names := make([]string, 0, len(resp.NetworkSettings.Networks))
+	for name := range resp.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if endpoint := resp.NetworkSettings.Networks[name]; endpoint != nil && endpoint.IPAddress != "" {
+			return endpoint.IPAddress
+		}
+	}
+	return ""
+}
+
+// dockerMounts converts mounts to the form the Docker API takes.
+func dockerMounts(mounts []models.Mount) []mount.Mount {
+	var result []mount.Mount
+	for _, m := range mounts {
+		log.Printf("Setting up %s mount at %s", m.Type, m.Target)
+		dm := mount.Mount{
+			Source:   m.Source,
+			Target:   m.Target,
+			ReadOnly: m.ReadOnly,
+		}
+		switch m.Type {
+		case models.MountTypeBind:
+			dm.Type = mount.TypeBind
+		case models.MountTypeTmpfs:
+			dm.Type = mount.TypeTmpfs
+			dm.Source = ""
+			if m.TmpfsSize > 0 {
+				dm.TmpfsOptions = &mount.TmpfsOptions{SizeBytes: m.TmpfsSize}
+			}
+		default:
+			dm.Type = mount.TypeVolume
+		}
+		result = append(result, dm)
+	}
+	return result
+}
+
+func (d *DockerRuntime) CreateVolume(ctx context.Context, spec models.Volume) (string, error) {
+	log.Printf("Creating volume: name=%s, driver=%s", spec.Name, spec.Driver)
+
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
-	
+
+	labels := map[string]string{}
+	for k, v := range spec.Labels {
+		labels[k] = v
+	}
+	labels["podium.managed"] = "true"
+
+	created, err := d.client.VolumeCreate(ctx, volume.CreateOptions{
+		Name:   spec.Name,
+		Driver: spec.Driver,
+		Labels: labels,
+	})
+	if err != nil {
+		log.Printf("Error creating volume %s: %v", spec.Name, err)
+		return "", fmt.Errorf("failed to create volume: %w", err)
+	}
+
+	log.Printf("Volume %s created successfully", spec.Name)
+	return created.Mountpoint, nil
+}
+
+func (d *DockerRuntime) DeleteVolume(ctx context.Context, name string) error {
+	log.Printf("Removing volume: %s", name)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := d.client.VolumeRemove(ctx, name, false); err != nil {
+		if client.IsErrNotFound(err) {
+			return nil
+		}
+		log.Printf("Error removing volume %s: %v", name, err)
+		return fmt.Errorf("failed to remove volume: %w", err)
+	}
+
+	log.Printf("Volume %s removed successfully", name)
+	return nil
+}
+
+func (d *DockerRuntime) CreateNetwork(ctx context.Context, spec models.Network) error {
+	log.Printf("Creating network: name=%s, driver=%s", spec.Name, spec.Driver)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	labels := map[string]string{}
+	for k, v := range spec.Labels {
+		labels[k] = v
+	}
+	labels["podium.managed"] = "true"
+
+	options := network.CreateOptions{
+		Driver:   spec.Driver,
+		Internal: spec.Internal,
+		Labels:   labels,
+	}
+	if spec.Subnet != "" {
+		options.IPAM = &network.IPAM{Config: []network.IPAMConfig{{Subnet: spec.Subnet}}}
+	}
+
+	if _, err := d.client.NetworkCreate(ctx, spec.Name, options); err != nil {
+		if errdefs.IsConflict(err) {
+			return d.adoptNetwork(ctx, spec.Name)
+		}
+		log.Printf("Error creating network %s: %v", spec.Name, err)
+		return fmt.Errorf("failed to create network: %w", err)
+	}
+
+	log.Printf("Network %s created successfully", spec.Name)
+	return nil
+}
+
+// adoptNetwork accepts an existing network in place of creating it if
+// Podium created it before, such as before a restore.
+func (d *DockerRuntime) adoptNetwork(ctx context.Context, name string) error {
+	resp, err := d.client.NetworkInspect(ctx, name, network.InspectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to inspect existing network: %w", err)
+	}
+	if resp.Labels["podium.managed"] != "true" {
+		log.Printf("Network %s already exists and is not managed by Podium", name)
+		return fmt.Errorf("%w: %s", ErrNetworkExists, name)
+	}
+	log.Printf("Network %s already exists", name)
+	return nil
+}
+
+func (d *DockerRuntime) DeleteNetwork(ctx context.Context, name string) error {
+	log.Printf("Removing network: %s", name)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := d.client.NetworkRemove(ctx, name); err != nil {
+		if client.IsErrNotFound(err) {
+			return nil
+		}
+		log.Printf("Error removing network %s: %v", name, err)
+		return fmt.Errorf("failed to remove network: %w", err)
+	}
+
+	log.Printf("Network %s removed successfully", name)
+	return nil
+}
+
+// HostAddress returns the gateway of Docker's default bridge network, which
+// is an address of the host on that network.
+func (d *DockerRuntime) HostAddress(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := d.client.NetworkInspect(ctx, "bridge", network.InspectOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect the bridge network: %w", err)
+	}
+	for _, config := range resp.IPAM.Config {
+		if config.Gateway != "" {
+			return config.Gateway, nil
+		}
+	}
+	return "", fmt.Errorf("the bridge network has no gateway")
+}
+
+func (d *DockerRuntime) GetContainerLogs(ctx context.Context, id string, opts LogOptions, fn func(LogEntry) error) error {
+	log.Printf("Getting logs for container: %s (follow=%t)", id, opts.Follow)
+
+	if !opts.Follow {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
+
 	options := container.LogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
-		Tail:       "100",
+		ShowStdout: opts.Stdout,
+		ShowStderr: opts.Stderr,
+		Follow:     opts.Follow,
+		// Timestamps are always requested so entries can be ordered and
+		// filtered; callers decide whether to show them.
+		Timestamps: true,
+		Tail:       "all",
+	}
+	if opts.Tail >= 0 {
+		options.Tail = strconv.Itoa(opts.Tail)
+	}
+	if !opts.Since.IsZero() {
+		options.Since = dockerTimestamp(opts.Since)
+	}
+	if !opts.Until.IsZero() {
+		options.Until = dockerTimestamp(opts.Until)
 	}
 
 	logs, err := d.client.ContainerLogs(ctx, id, options)
 	if err != nil {
 		log.Printf("Error getting logs for container %s: %v", id, err)
-		return "", fmt.Errorf("failed to get container logs: %v", err)
+		return fmt.Errorf("failed to get container logs: %v", err)
 	}
 	defer logs.Close()
 
-	log.Printf("Reading logs for container %s", id)
-	logBytes, err := io.ReadAll(logs)
-	if err != nil {
+	// Containers are created without a TTY, so the stream is multiplexed.
+	stdout := &logLineWriter{stream: LogStreamStdout, timestamped: true, fn: fn}
+	stderr := &logLineWriter{stream: LogStreamStderr, timestamped: true, fn: fn}
+	if _, err := stdcopy.StdCopy(stdout, stderr, logs); err != nil && ctx.Err() == nil {
 		log.Printf("Error reading logs for container %s: %v", id, err)
-		return "", fmt.Errorf("failed to read container logs: %v", err)
+		return fmt.Errorf("failed to read container logs: %v", err)
 	}
+	if err := stdout.Flush(); err != nil {
+		return err
+	}
+	return stderr.Flush()
+}
 
-	log.Printf("Successfully retrieved logs for container %s (%d bytes)", id, len(logBytes))
-	return string(logBytes), nil
-}
\ No newline at end of file
+// dockerTimestamp formats t the way the Docker API expects for since/until.
+func dockerTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
+
+func (d *DockerRuntime) GetContainerStats(ctx context.Context, id string) (models.ContainerStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := d.client.ContainerStatsOneShot(ctx, id)
+	if err != nil {
+		log.Printf("Error getting stats for container %s: %v", id, err)
+		return models.ContainerStats{}, fmt.Errorf("failed to get container stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var raw container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return models.ContainerStats{}, fmt.Errorf("failed to decode container stats: %w", err)
+	}
+
+	stats := models.ContainerStats{
+		ContainerID:   id,
+		Timestamp:     raw.Read,
+		CPUUsageNanos: raw.CPUStats.CPUUsage.TotalUsage,
+		OnlineCPUs:    int(raw.CPUStats.OnlineCPUs),
+		MemoryUsage:   raw.MemoryStats.Usage,
+		MemoryLimit:   raw.MemoryStats.Limit,
+	}
+	if stats.Timestamp.IsZero() {
+		stats.Timestamp = time.Now()
+	}
+
+	// Page cache is reclaimable, so leave it out of memory usage the same
+	// way "docker stats" does.
+	cache := raw.MemoryStats.Stats["inactive_file"]
+	if cache == 0 {
+		cache = raw.MemoryStats.Stats["total_inactive_file"]
+	}
+	if cache < stats.MemoryUsage {
+		stats.MemoryUsage -= cache
+	}
+
+	for _, network := range raw.Networks {
+		stats.NetworkRxBytes += network.RxBytes
+		stats.NetworkTxBytes += network.TxBytes
+	}
+	for _, entry := range raw.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			stats.BlockReadBytes += entry.Value
+		case "write":
+			stats.BlockWriteBytes += entry.Value
+		}
+	}
+
+	return stats, nil
+}
+
+func (d *DockerRuntime) Exec(ctx context.Context, id string, cmd []string) (ExecResult, error) {
+	log.Printf("Executing command in container %s: %v", id, cmd)
+
+	created, err := d.client.ContainerExecCreate(ctx, id, container.ExecOptions{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		log.Printf("Error creating exec in container %s: %v", id, err)
+		return ExecResult{}, fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	attach, err := d.client.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		log.Printf("Error attaching to exec in container %s: %v", id, err)
+		return ExecResult{}, fmt.Errorf("failed to attach to exec: %w", err)
+	}
+	defer attach.Close()
+
+	// The hijacked connection does not observe ctx, so close it when ctx is
+	// done to unblock the copy below.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			attach.Close()
+		case <-done:
+		}
+	}()
+
+	var output bytes.Buffer
+	if _, err := stdcopy.StdCopy(&output, &output, attach.Reader); err != nil {
+		if ctx.Err() != nil {
+			return ExecResult{}, fmt.Errorf("exec timed out: %w", ctx.Err())
+		}
+		return ExecResult{}, fmt.Errorf("failed to read exec output: %w", err)
+	}
+
+	inspect, err := d.client.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("failed to inspect exec: %w", err)
+	}
+
+	return ExecResult{
+		ExitCode: inspect.ExitCode,
+		Output:   output.String(),
+	}, nil
+}
+
+type dockerExecSession struct {
+	client    *client.Client
+	execID    string
+	attach    types.HijackedResponse
+	copyDone  chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (d *DockerRuntime) StartExec(ctx context.Context, id string, opts ExecOptions, stdout, stderr io.Writer) (ExecSession, error) {
+	log.Printf("Starting interactive exec in container %s: %v (tty=%v)", id, opts.Cmd, opts.TTY)
+
+	execOptions := container.ExecOptions{
+		Cmd:          opts.Cmd,
+		Tty:          opts.TTY,
+		AttachStdin:  opts.Stdin,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+	if opts.TTY && opts.Width > 0 && opts.Height > 0 {
+		execOptions.ConsoleSize = &[2]uint{opts.Height, opts.Width}
+	}
+
+	created, err := d.client.ContainerExecCreate(ctx, id, execOptions)
+	if err != nil {
+		log.Printf("Error creating exec in container %s: %v", id, err)
+		return nil, fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	attach, err := d.client.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{
+		Tty:         opts.TTY,
+		ConsoleSize: execOptions.ConsoleSize,
+	})
+	if err != nil {
+		log.Printf("Error attaching to exec in container %s: %v", id, err)
+		return nil, fmt.Errorf("failed to attach to exec: %w", err)
+	}
+
+	session := &dockerExecSession{
+		client:   d.client,
+		execID:   created.ID,
+		attach:   attach,
+		copyDone: make(chan struct{}),
+		closed:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(session.copyDone)
+		var err error
+		if opts.TTY {
+			_, err = io.Copy(stdout, attach.Reader)
+		} else {
+			_, err = stdcopy.StdCopy(stdout, stderr, attach.Reader)
+		}
+		if err != nil {
+			log.Printf("Exec output stream for container %s ended: %v", id, err)
+		}
+	}()
+
+	return session, nil
+}
+
+func (s *dockerExecSession) Write(p []byte) (int, error) {
+	return s.attach.Conn.Write(p)
+}
+
+func (s *dockerExecSession) CloseStdin() error {
+	return s.attach.CloseWrite()
+}
+
+func (s *dockerExecSession) Resize(ctx context.Context, width, height uint) error {
+	return s.client.ContainerExecResize(ctx, s.execID, container.ResizeOptions{
+		Width:  width,
+		Height: height,
+	})
+}
+
+func (s *dockerExecSession) Wait(ctx context.Context) (int, error) {
+	select {
+	case <-s.copyDone:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	// The output stream can close slightly before Docker records the exit code.
+	for {
+		inspect, err := s.client.ContainerExecInspect(ctx, s.execID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to inspect exec: %w", err)
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+
+		select {
+		case <-time.After(50 * time.Millisecond):
+		case <-s.closed:
+			return 0, fmt.Errorf("exec session closed")
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+func (s *dockerExecSession) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		s.attach.Close()
+	})
+	return nil
+}
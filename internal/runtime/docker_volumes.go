@@ -0,0 +1,184 @@
+package runtime
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// volumeHelperImage is the image of the short-lived containers Podium mounts
+// a volume in to copy data in and out of it, since Docker only gives access
+// to a volume's data through a container.
+const volumeHelperImage = "busybox:1.36"
+
+// volumeHelperPath is where helper containers mount the volume.
+const volumeHelperPath = "/volume"
+
+// volumeHelperStagingPath is the directory of the helper image that an
+// imported archive is copied to before it replaces the volume's data. It
+// exists, and is empty, in the image.
+const volumeHelperStagingPath = "/tmp"
+
+func (d *DockerRuntime) ExportVolume(ctx context.Context, name string, w io.Writer) error {
+	log.Printf("Exporting volume: %s", name)
+
+	id, err := d.createVolumeHelper(ctx, name, true, []string{"true"})
+	if err != nil {
+		return err
+	}
+	defer d.removeVolumeHelper(id)
+
+	reader, _, err := d.client.CopyFromContainer(ctx, id, volumeHelperPath)
+	if err != nil {
+		log.Printf("Error copying from volume %s: %v", name, err)
+		return fmt.Errorf("failed to export volume: %w", err)
+	}
+	defer reader.Close()
+
+	// Docker names the entries after the mount point, so it is stripped to
+	// make the paths relative to the volume.
+	if err := rebaseArchive(reader, w, strings.TrimPrefix(volumeHelperPath, "/")); err != nil {
+		return fmt.Errorf("failed to export volume: %w", err)
+	}
+
+	log.Printf("Volume %s exported successfully", name)
+	return nil
+}
+
+func (d *DockerRuntime) ImportVolume(ctx context.Context, name string, r io.Reader) error {
+	log.Printf("Importing volume: %s", name)
+
+	// The archive is first copied into the helper's own filesystem, so the
+	// volume is only touched once all of it has arrived. The helper then
+	// empties the volume and copies the staged data into it in one command.
+	script := fmt.Sprintf("find %[1]s -mindepth 1 -delete && cp -a %[2]s/. %[1]s/", volumeHelperPath, volumeHelperStagingPath)
+	id, err := d.createVolumeHelper(ctx, name, false, []string{"sh", "-c", script})
+	if err != nil {
+		return err
+	}
+	defer d.removeVolumeHelper(id)
+
+	if err := d.client.CopyToContainer(ctx, id, volumeHelperStagingPath, r, container.CopyToContainerOptions{CopyUIDGID: true}); err != nil {
+		log.Printf("Error copying to volume %s: %v", name, err)
+		return fmt.Errorf("failed to import volume: %w", err)
+	}
+
+	statusCh, errCh := d.client.ContainerWait(ctx, id, container.WaitConditionNextExit)
+	if err := d.client.ContainerStart(ctx, id, container.StartOptions{}); err != nil {
+		log.Printf("Error importing volume %s: %v", name, err)
+		return fmt.Errorf("failed to import volume: %w", err)
+	}
+	select {
+	case status := <-statusCh:
+		if status.StatusCode != 0 {
+			log.Printf("Error importing volume %s: helper exited with code %d", name, status.StatusCode)
+			return fmt.Errorf("failed to import volume: %w: exit code %d", ErrVolumeIncomplete, status.StatusCode)
+		}
+	case err := <-errCh:
+		return fmt.Errorf("failed to import volume: %w: %w", ErrVolumeIncomplete, err)
+	}
+
+	log.Printf("Volume %s imported successfully", name)
+	return nil
+}
+
+func (d *DockerRuntime) createVolumeHelper(ctx context.Context, name string, readOnly bool, cmd []string) (string, error) {
+	exists, err := d.ImageExists(ctx, volumeHelperImage)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		if err := d.PullImage(ctx, volumeHelperImage, nil, nil); err != nil {
+			return "", err
+		}
+	}
+
+	resp, err := d.client.ContainerCreate(ctx,
+		&container.Config{
+			Image:  volumeHelperImage,
+			Cmd:    cmd,
+			Labels: map[string]string{"podium.volume.helper": name},
+		},
+		&container.HostConfig{
+			Mounts: []mount.Mount{{Type: mount.TypeVolume, Source: name, Target: volumeHelperPath, ReadOnly: readOnly}},
+		},
+		nil, nil, "")
+	if err != nil {
+		log.Printf("Error creating helper container for volume %s: %v", name, err)
+		return "", fmt.Errorf("failed to create helper container: %w", err)
+	}
+	return resp.ID, nil
+}
+
+func (d *DockerRuntime) removeVolumeHelper(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := d.client.ContainerRemove(ctx, id, container.RemoveOptions{Force: true}); err != nil {
+		log.Printf("Cleanup failed: could not remove helper container %s: %v", id, err)
+	}
+}
+
+// rebaseArchive copies a tar archive from r to w, making the paths of the
+// entries under dir relative to it and dropping dir itself.
+func rebaseArchive(r io.Reader, w io.Writer, dir string) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		header.Name = strings.TrimPrefix(strings.TrimPrefix(header.Name, dir), "/")
+		if header.Name == "" {
+			continue
+		}
+		if header.Typeflag == tar.TypeLink {
+			header.Linkname = strings.TrimPrefix(strings.TrimPrefix(header.Linkname, dir), "/")
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func (d *DockerRuntime) PauseContainer(ctx context.Context, id string) error {
+	log.Printf("Pausing container: %s", id)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := d.client.ContainerPause(ctx, id); err != nil {
+		log.Printf("Error pausing container %s: %v", id, err)
+		return fmt.Errorf("failed to pause container: %w", err)
+	}
+	return nil
+}
+
+func (d *DockerRuntime) UnpauseContainer(ctx context.Context, id string) error {
+	log.Printf("Unpausing container: %s", id)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := d.client.ContainerUnpause(ctx, id); err != nil {
+		log.Printf("Error unpausing container %s: %v", id, err)
+		return fmt.Errorf("failed to unpause container: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,735 @@
+package runtime
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"podium/internal/models"
+)
+
+// FakeFault describes failures the FakeRuntime injects for every container
+// created from a given image.
+type FakeFault struct {
+	PullError    error
+	StartDelay   time.Duration
+	CrashAfter   time.Duration
+	ExitCode     int
+	ExecExitCode int
+}
+
+type fakeContainer struct {
+	spec       models.Container
+	running    bool
+	paused     bool
+	exitCode   int
+	logs       []LogEntry
+	logUpdated chan struct{}
+	// startedAt and uptime drive the synthetic resource usage reported by
+	// GetContainerStats.
+	startedAt  time.Time
+	uptime     time.Duration
+	crashTimer *time.Timer
+	ip         string
+	// run counts the container's starts, so that a crash timer firing after
+	// the run it was set for has ended does nothing.
+	run int
+}
+
+func (c *fakeContainer) appendLog(stream, line string) {
+	c.logs = append(c.logs, LogEntry{Stream: stream, Timestamp: time.Now(), Line: line})
+	if c.logUpdated != nil {
+		close(c.logUpdated)
+		c.logUpdated = nil
+	}
+}
+
+// logUpdates returns a channel that is closed when the next log entry is
+// appended.
+func (c *fakeContainer) logUpdates() <-chan struct{} {
+	if c.logUpdated == nil {
+		c.logUpdated = make(chan struct{})
+	}
+	return c.logUpdated
+}
+
+// FakeRuntime is an in-memory Runtime that simulates the container lifecycle
+// without a Docker daemon. It is safe for concurrent use.
+type FakeRuntime struct {
+	mu         sync.Mutex
+	containers map[string]*fakeContainer
+	images     map[string]bool
+	faults     map[string]FakeFault
+	networks   map[string]models.Network
+	volumes    map[string]models.Volume
+	// volumeData holds the data of each volume as a tar archive.
+	volumeData map[string][]byte
+	// lastIP numbers the addresses given to containers.
+	lastIP int
+}
+
+func NewFakeRuntime() *FakeRuntime {
+	log.Println("Initializing in-memory fake runtime")
+	return &FakeRuntime{
+		containers: make(map[string]*fakeContainer),
+		images:     make(map[string]bool),
+		faults:     make(map[string]FakeFault),
+		networks:   make(map[string]models.Network),
+		volumes:    make(map[string]models.Volume),
+		volumeData: make(map[string][]byte),
+	}
+}
+
+// InjectFault makes every container created from image behave according to
+// fault. Passing a zero FakeFault clears any previously injected fault.
+func (f *FakeRuntime) InjectFault(image string, fault FakeFault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if fault == (FakeFault{}) {
+		delete(f.faults, image)
+		return
+	}
+	f.faults[image] = fault
+}
+
+// Crash terminates a running container with the given exit code, as if the
+// process inside it had died.
+func (f *FakeRuntime) Crash(id string, exitCode int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return fmt.Errorf("container not found: %s", id)
+	}
+	f.exit(c, exitCode, "crashed")
+	return nil
+}
+
+// ExitCode returns the exit code of the container's last run.
+func (f *FakeRuntime) ExitCode(id string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return 0, fmt.Errorf("container not found: %s", id)
+	}
+	return c.exitCode, nil
+}
+
+// AppendLogs adds lines to a container's stdout.
+func (f *FakeRuntime) AppendLogs(id string, lines ...string) error {
+	return f.appendLogs(id, LogStreamStdout, lines)
+}
+
+// AppendErrorLogs adds lines to a container's stderr.
+func (f *FakeRuntime) AppendErrorLogs(id string, lines ...string) error {
+	return f.appendLogs(id, LogStreamStderr, lines)
+}
+
+func (f *FakeRuntime) appendLogs(id, stream string, lines []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return fmt.Errorf("container not found: %s", id)
+	}
+	for _, line := range lines {
+		c.appendLog(stream, line)
+	}
+	return nil
+}
+
+// ContainerIDs returns the IDs of every container the runtime knows about.
+func (f *FakeRuntime) ContainerIDs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ids := make([]string, 0, len(f.containers))
+	for id := range f.containers {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (f *FakeRuntime) ImageExists(ctx context.Context, image string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.images[image], nil
+}
+
+func (f *FakeRuntime) PullImage(ctx context.Context, image string, auth *models.RegistryCredential, progress func(PullProgress)) error {
+	log.Printf("Pulling fake image: %s", image)
+
+	f.mu.Lock()
+	fault := f.faults[image]
+	f.mu.Unlock()
+
+	if fault.PullError != nil {
+		return fmt.Errorf("failed to pull image: %w", fault.PullError)
+	}
+
+	const layerSize = 1 << 20
+	for _, layer := range []string{"layer0", "layer1"} {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("failed to pull image: %w", err)
+		}
+		if progress != nil {
+			progress(PullProgress{Layer: layer, Status: "Downloading", Total: layerSize})
+			progress(PullProgress{Layer: layer, Status: "Pull complete", Current: layerSize, Total: layerSize})
+		}
+	}
+
+	f.mu.Lock()
+	f.images[image] = true
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *FakeRuntime) CreateContainer(ctx context.Context, spec models.Container) error {
+	log.Printf("Creating fake container: name=%s, image=%s", spec.Name, spec.Image)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.images[spec.Image] {
+		return fmt.Errorf("failed to create container: no such image: %s", spec.Image)
+	}
+	if _, exists := f.containers[spec.ID]; exists {
+		return fmt.Errorf("failed to create container: container %s already exists", spec.ID)
+	}
+	for _, attachment := range spec.Networks {
+		if _, ok := f.networks[attachment.Network]; !ok {
+			return fmt.Errorf("failed to create container: network %s not found", attachment.Network)
+		}
+	}
+	for _, m := range spec.Mounts {
+		if m.Type != models.MountTypeVolume && m.Type != "" {
+			continue
+		}
+		if _, ok := f.volumes[m.Source]; !ok {
+			return fmt.Errorf("failed to create container: volume %s not found", m.Source)
+		}
+	}
+
+	f.lastIP++
+	ip := fmt.Sprintf("10.88.%d.%d", f.lastIP/254%256, f.lastIP%254+1)
+	f.containers[spec.ID] = &fakeContainer{spec: spec, ip: ip}
+	return nil
+}
+
+func (f *FakeRuntime) StartContainer(ctx context.Context, id string) error {
+	log.Printf("Starting fake container: %s", id)
+
+	f.mu.Lock()
+	c, ok := f.containers[id]
+	if !ok {
+		f.mu.Unlock()
+		return fmt.Errorf("failed to start container: container not found: %s", id)
+	}
+	fault := f.faults[c.spec.Image]
+	f.mu.Unlock()
+
+	if fault.StartDelay > 0 {
+		select {
+		case <-time.After(fault.StartDelay):
+		case <-ctx.Done():
+			return fmt.Errorf("failed to start container: %w", ctx.Err())
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// The container may have been deleted while we were waiting.
+	c, ok = f.containers[id]
+	if !ok {
+		return fmt.Errorf("failed to start container: container not found: %s", id)
+	}
+	f.start(c, fault)
+	return nil
+}
+
+// start runs a stopped container and arms the crash its fault asks for. The
+// caller must hold f.mu.
+func (f *FakeRuntime) start(c *fakeContainer, fault FakeFault) {
+	if c.running {
+		return
+	}
+
+	c.running = true
+	c.exitCode = 0
+	c.startedAt = time.Now()
+	c.run++
+	c.appendLog(LogStreamStdout, fmt.Sprintf("started: %s %s", c.spec.Image, strings.Join(c.spec.Command, " ")))
+
+	if fault.CrashAfter > 0 {
+		exitCode := fault.ExitCode
+		if exitCode == 0 {
+			exitCode = 1
+		}
+		// Stopping the timer does not stop a callback that has already
+		// fired and is waiting for f.mu, so the callback checks the run.
+		run := c.run
+		c.crashTimer = time.AfterFunc(fault.CrashAfter, func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if c.run == run {
+				f.exit(c, exitCode, "crashed")
+			}
+		})
+	}
+}
+
+func (f *FakeRuntime) StopContainer(ctx context.Context, id string) error {
+	log.Printf("Stopping fake container: %s", id)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return fmt.Errorf("failed to stop container: container not found: %s", id)
+	}
+	f.exit(c, 0, "stopped")
+	return nil
+}
+
+func (f *FakeRuntime) PauseContainer(ctx context.Context, id string) error {
+	log.Printf("Pausing fake container: %s", id)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return fmt.Errorf("failed to pause container: container not found: %s", id)
+	}
+	if !c.running {
+		return fmt.Errorf("failed to pause container: container %s is not running", id)
+	}
+	c.paused = true
+	return nil
+}
+
+func (f *FakeRuntime) UnpauseContainer(ctx context.Context, id string) error {
+	log.Printf("Unpausing fake container: %s", id)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return fmt.Errorf("failed to unpause container: container not found: %s", id)
+	}
+	if !c.paused {
+		return fmt.Errorf("failed to unpause container: container %s is not paused", id)
+	}
+	c.paused = false
+	return nil
+}
+
+func (f *FakeRuntime) DeleteContainer(ctx context.Context, id string) error {
+	log.Printf("Deleting fake container: %s", id)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return fmt.Errorf("failed to remove container: container not found: %s", id)
+	}
+	if c.crashTimer != nil {
+		c.crashTimer.Stop()
+	}
+	delete(f.containers, id)
+	return nil
+}
+
+// ContainerAddress returns the container's host port, or the container port
+// itself if it publishes none, on the loopback address.
+func (f *FakeRuntime) ContainerAddress(ctx context.Context, id string, containerPort int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return "", fmt.Errorf("failed to inspect container: container not found: %s", id)
+	}
+	for _, port := range c.spec.Ports {
+		if port.ContainerPort != containerPort {
+			continue
+		}
+		if port.HostPort != 0 {
+			return net.JoinHostPort("127.0.0.1", strconv.Itoa(port.HostPort)), nil
+		}
+		return net.JoinHostPort("127.0.0.1", strconv.Itoa(containerPort)), nil
+	}
+	return "", fmt.Errorf("container %s does not expose port %d", id, containerPort)
+}
+
+// ContainerIP returns the made-up address the container was given when it
+// was created.
+func (f *FakeRuntime) ContainerIP(ctx context.Context, id string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return "", fmt.Errorf("failed to inspect container: container not found: %s", id)
+	}
+	return c.ip, nil
+}
+
+func (f *FakeRuntime) HostAddress(ctx context.Context) (string, error) {
+	return "127.0.0.1", nil
+}
+
+func (f *FakeRuntime) CreateVolume(ctx context.Context, volume models.Volume) (string, error) {
+	log.Printf("Creating fake volume: %s", volume.Name)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.volumes[volume.Name]; !exists {
+		f.volumes[volume.Name] = volume
+	}
+	return "/fake/volumes/" + volume.Name, nil
+}
+
+// DeleteVolume refuses to remove a volume that containers mount, as Docker
+// does.
+func (f *FakeRuntime) DeleteVolume(ctx context.Context, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for id, c := range f.containers {
+		for _, m := range c.spec.Mounts {
+			if (m.Type == models.MountTypeVolume || m.Type == "") && m.Source == name {
+				return fmt.Errorf("failed to remove volume: volume %s is in use by container %s", name, id)
+			}
+		}
+	}
+	delete(f.volumes, name)
+	delete(f.volumeData, name)
+	return nil
+}
+
+func (f *FakeRuntime) ExportVolume(ctx context.Context, name string, w io.Writer) error {
+	f.mu.Lock()
+	_, ok := f.volumes[name]
+	data := f.volumeData[name]
+	f.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("failed to export volume: volume %s not found", name)
+	}
+	if data == nil {
+		// An empty volume is an empty archive.
+		return tar.NewWriter(w).Close()
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func (f *FakeRuntime) ImportVolume(ctx context.Context, name string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to import volume: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.volumes[name]; !ok {
+		return fmt.Errorf("failed to import volume: volume %s not found", name)
+	}
+	f.volumeData[name] = data
+	return nil
+}
+
+func (f *FakeRuntime) CreateNetwork(ctx context.Context, network models.Network) error {
+	log.Printf("Creating fake network: %s", network.Name)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.networks[network.Name]; !exists {
+		f.networks[network.Name] = network
+	}
+	return nil
+}
+
+// DeleteNetwork refuses to remove a network that containers are attached
+// to, as Docker does.
+func (f *FakeRuntime) DeleteNetwork(ctx context.Context, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for id, c := range f.containers {
+		for _, attachment := range c.spec.Networks {
+			if attachment.Network == name {
+				return fmt.Errorf("failed to remove network: container %s is attached to network %s", id, name)
+			}
+		}
+	}
+	delete(f.networks, name)
+	return nil
+}
+
+func (f *FakeRuntime) ListContainers(ctx context.Context) ([]string, error) {
+	return f.ContainerIDs(), nil
+}
+
+func (f *FakeRuntime) GetContainerStatus(ctx context.Context, id string) (models.ContainerState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return "", fmt.Errorf("failed to inspect container: container not found: %s", id)
+	}
+
+	switch {
+	case c.running:
+		return models.ContainerStateRunning, nil
+	case c.exitCode == 0:
+		return models.ContainerStateSucceeded, nil
+	default:
+		return models.ContainerStateFailed, nil
+	}
+}
+
+func (f *FakeRuntime) GetContainerLogs(ctx context.Context, id string, opts LogOptions, fn func(LogEntry) error) error {
+	f.mu.Lock()
+	c, ok := f.containers[id]
+	if !ok {
+		f.mu.Unlock()
+		return fmt.Errorf("failed to get container logs: container not found: %s", id)
+	}
+	tail := &tailBuffer{n: opts.Tail}
+	next, done := collectFakeLogs(c, 0, opts, tail.add)
+	f.mu.Unlock()
+
+	if err := tail.flush(fn); err != nil {
+		return err
+	}
+	if done || !opts.Follow {
+		return nil
+	}
+
+	for {
+		f.mu.Lock()
+		c, ok := f.containers[id]
+		if !ok {
+			f.mu.Unlock()
+			return nil
+		}
+		var entries []LogEntry
+		next, done = collectFakeLogs(c, next, opts, func(entry LogEntry) {
+			entries = append(entries, entry)
+		})
+		running := c.running
+		updates := c.logUpdates()
+		f.mu.Unlock()
+
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		if done || !running {
+			return nil
+		}
+
+		select {
+		case <-updates:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// collectFakeLogs passes the entries of c from index start on that match opts
+// to add. It returns the index to continue from and whether the entries have
+// passed opts.Until. The caller must hold f.mu.
+func collectFakeLogs(c *fakeContainer, start int, opts LogOptions, add func(LogEntry)) (int, bool) {
+	for i := start; i < len(c.logs); i++ {
+		entry := c.logs[i]
+		if !opts.Until.IsZero() && entry.Timestamp.After(opts.Until) {
+			return i, true
+		}
+		if opts.matches(entry) {
+			add(entry)
+		}
+	}
+	return len(c.logs), false
+}
+
+// GetContainerStats reports synthetic usage that grows with the time the
+// container has been running: a twentieth of a CPU, 32MiB of memory and a
+// steady trickle of network and disk traffic.
+func (f *FakeRuntime) GetContainerStats(ctx context.Context, id string) (models.ContainerStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return models.ContainerStats{}, fmt.Errorf("failed to get container stats: container not found: %s", id)
+	}
+	if !c.running {
+		return models.ContainerStats{}, fmt.Errorf("failed to get container stats: container is not running: %s", id)
+	}
+
+	now := time.Now()
+	uptime := c.uptime + now.Sub(c.startedAt)
+	seconds := uint64(uptime.Seconds())
+
+	stats := models.ContainerStats{
+		ContainerID:     id,
+		Timestamp:       now,
+		CPUUsageNanos:   uint64(uptime.Nanoseconds() / 20),
+		OnlineCPUs:      1,
+		MemoryUsage:     32 << 20,
+		NetworkRxBytes:  seconds * 1024,
+		NetworkTxBytes:  seconds * 512,
+		BlockReadBytes:  4 << 20,
+		BlockWriteBytes: seconds * 256,
+	}
+	if c.spec.Resources.MemoryLimit > 0 {
+		stats.MemoryLimit = uint64(c.spec.Resources.MemoryLimit)
+		if stats.MemoryUsage > stats.MemoryLimit {
+			stats.MemoryUsage = stats.MemoryLimit
+		}
+	}
+	return stats, nil
+}
+
+// Exec simulates running cmd in a container. The command is echoed as its
+// output and exits with the ExecExitCode injected for the container's image.
+func (f *FakeRuntime) Exec(ctx context.Context, id string, cmd []string) (ExecResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return ExecResult{}, fmt.Errorf("failed to create exec: container not found: %s", id)
+	}
+	if !c.running {
+		return ExecResult{}, fmt.Errorf("failed to create exec: container %s is not running", id)
+	}
+
+	return ExecResult{
+		ExitCode: f.faults[c.spec.Image].ExecExitCode,
+		Output:   strings.Join(cmd, " ") + "\n",
+	}, nil
+}
+
+type fakeExecSession struct {
+	stdout   io.Writer
+	exitCode int
+
+	mu     sync.Mutex
+	exited chan struct{}
+	closed bool
+}
+
+// StartExec starts a simulated interactive command that echoes its stdin to
+// stdout and exits with the image's ExecExitCode once stdin is closed.
+func (f *FakeRuntime) StartExec(ctx context.Context, id string, opts ExecOptions, stdout, stderr io.Writer) (ExecSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to create exec: container not found: %s", id)
+	}
+	if !c.running {
+		return nil, fmt.Errorf("failed to create exec: container %s is not running", id)
+	}
+
+	session := &fakeExecSession{
+		stdout:   stdout,
+		exitCode: f.faults[c.spec.Image].ExecExitCode,
+		exited:   make(chan struct{}),
+	}
+	if !opts.Stdin {
+		fmt.Fprintln(stdout, strings.Join(opts.Cmd, " "))
+		session.finish()
+	}
+	return session, nil
+}
+
+func (s *fakeExecSession) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+	return s.stdout.Write(p)
+}
+
+func (s *fakeExecSession) CloseStdin() error {
+	s.finish()
+	return nil
+}
+
+func (s *fakeExecSession) Resize(ctx context.Context, width, height uint) error {
+	return nil
+}
+
+func (s *fakeExecSession) Wait(ctx context.Context) (int, error) {
+	select {
+	case <-s.exited:
+		return s.exitCode, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func (s *fakeExecSession) Close() error {
+	s.finish()
+	return nil
+}
+
+func (s *fakeExecSession) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.exited)
+	}
+}
+
+// exit moves a running container to its terminal state. The caller must hold f.mu.
+func (f *FakeRuntime) exit(c *fakeContainer, exitCode int, reason string) {
+	if !c.running {
+		return
+	}
+	if c.crashTimer != nil {
+		c.crashTimer.Stop()
+		c.crashTimer = nil
+	}
+	c.running = false
+	c.paused = false
+	c.exitCode = exitCode
+	c.uptime += time.Since(c.startedAt)
+	stream := LogStreamStdout
+	if exitCode != 0 {
+		stream = LogStreamStderr
+	}
+	c.appendLog(stream, fmt.Sprintf("%s with exit code %d", reason, exitCode))
+}
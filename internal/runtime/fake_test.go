@@ -0,0 +1,72 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"podium/internal/models"
+)
+
+// TestFakeCrashTimerOnlyCrashesItsRun checks that a crash timer that fires
+// while its container is being stopped does not crash the next run.
+func TestFakeCrashTimerOnlyCrashesItsRun(t *testing.T) {
+	ctx := context.Background()
+	f := NewFakeRuntime()
+	f.InjectFault("crasher", FakeFault{CrashAfter: 20 * time.Millisecond})
+	if err := f.PullImage(ctx, "crasher", nil, nil); err != nil {
+		t.Fatalf("pulling image: %v", err)
+	}
+
+	if err := f.CreateContainer(ctx, models.Container{ID: "c1", Image: "crasher"}); err != nil {
+		t.Fatalf("creating container: %v", err)
+	}
+	if err := f.StartContainer(ctx, "c1"); err != nil {
+		t.Fatalf("starting container: %v", err)
+	}
+
+	// Hold the lock until the timer has fired, so its callback is waiting
+	// for it, then stop and start the container before the callback runs.
+	f.mu.Lock()
+	time.Sleep(50 * time.Millisecond)
+	c := f.containers["c1"]
+	f.exit(c, 0, "stopped")
+	f.start(c, FakeFault{})
+	f.mu.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+	if state, err := f.GetContainerStatus(ctx, "c1"); err != nil || state != models.ContainerStateRunning {
+		t.Errorf("status after restart = %q, %v, want running", state, err)
+	}
+}
+
+// TestFakeCrashAfter checks that a fault's crash ends the run it was set for.
+func TestFakeCrashAfter(t *testing.T) {
+	ctx := context.Background()
+	f := NewFakeRuntime()
+	f.InjectFault("crasher", FakeFault{CrashAfter: 20 * time.Millisecond, ExitCode: 3})
+	if err := f.PullImage(ctx, "crasher", nil, nil); err != nil {
+		t.Fatalf("pulling image: %v", err)
+	}
+
+	if err := f.CreateContainer(ctx, models.Container{ID: "c1", Image: "crasher"}); err != nil {
+		t.Fatalf("creating container: %v", err)
+	}
+	if err := f.StartContainer(ctx, "c1"); err != nil {
+		t.Fatalf("starting container: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if state, _ := f.GetContainerStatus(ctx, "c1"); state != models.ContainerStateRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("container still running a second after it should have crashed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if code, err := f.ExitCode("c1"); err != nil || code != 3 {
+		t.Errorf("ExitCode = %d, %v, want 3", code, err)
+	}
+}
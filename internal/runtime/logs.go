@@ -0,0 +1,110 @@
+package runtime
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// matches reports whether entry is selected by the stream and time filters
+// of o.
+func (o LogOptions) matches(entry LogEntry) bool {
+	switch entry.Stream {
+	case LogStreamStdout:
+		if !o.Stdout {
+			return false
+		}
+	case LogStreamStderr:
+		if !o.Stderr {
+			return false
+		}
+	}
+	if entry.Timestamp.IsZero() {
+		return true
+	}
+	if !o.Since.IsZero() && entry.Timestamp.Before(o.Since) {
+		return false
+	}
+	if !o.Until.IsZero() && entry.Timestamp.After(o.Until) {
+		return false
+	}
+	return true
+}
+
+// tailBuffer keeps the last n entries appended to it, or all of them when n
+// is negative.
+type tailBuffer struct {
+	n       int
+	entries []LogEntry
+}
+
+func (b *tailBuffer) add(entry LogEntry) {
+	if b.n == 0 {
+		return
+	}
+	if b.n > 0 && len(b.entries) == b.n {
+		b.entries = b.entries[1:]
+	}
+	b.entries = append(b.entries, entry)
+}
+
+func (b *tailBuffer) flush(fn func(LogEntry) error) error {
+	for _, entry := range b.entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	b.entries = nil
+	return nil
+}
+
+// logLineWriter splits the output of one stream into lines and passes each
+// line to fn as a LogEntry. When timestamped is set, each line is expected
+// to start with an RFC 3339 timestamp followed by a space, as Docker writes
+// them.
+type logLineWriter struct {
+	stream      string
+	timestamped bool
+	fn          func(LogEntry) error
+	partial     []byte
+}
+
+func (w *logLineWriter) Write(p []byte) (int, error) {
+	data := append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		if err := w.emit(string(data[:i])); err != nil {
+			w.partial = nil
+			return 0, err
+		}
+		data = data[i+1:]
+	}
+	w.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// Flush emits any trailing output that did not end with a newline.
+func (w *logLineWriter) Flush() error {
+	if len(w.partial) == 0 {
+		return nil
+	}
+	line := string(w.partial)
+	w.partial = nil
+	return w.emit(line)
+}
+
+func (w *logLineWriter) emit(line string) error {
+	entry := LogEntry{Stream: w.stream, Line: strings.TrimSuffix(line, "\r")}
+	if w.timestamped {
+		if i := strings.IndexByte(line, ' '); i > 0 {
+			if ts, err := time.Parse(time.RFC3339Nano, line[:i]); err == nil {
+				entry.Timestamp = ts
+				entry.Line = strings.TrimSuffix(line[i+1:], "\r")
+			}
+		}
+	}
+	return w.fn(entry)
+}
@@ -0,0 +1,31 @@
+package runtime
+
+import (
+	"context"
+
+	"podium/internal/models"
+)
+
+// resolverRuntime is a Runtime whose containers resolve names with the
+// given name servers unless their spec sets its own.
+type resolverRuntime struct {
+	Runtime
+	servers []string
+	search  []string
+}
+
+// WithResolver returns a Runtime that creates containers with the given name
+// servers and search domains.
+func WithResolver(rt Runtime, servers, search []string) Runtime {
+	return &resolverRuntime{Runtime: rt, servers: servers, search: search}
+}
+
+func (r *resolverRuntime) CreateContainer(ctx context.Context, spec models.Container) error {
+	if len(spec.DNS) == 0 {
+		spec.DNS = r.servers
+		if len(spec.DNSSearch) == 0 {
+			spec.DNSSearch = r.search
+		}
+	}
+	return r.Runtime.CreateContainer(ctx, spec)
+}
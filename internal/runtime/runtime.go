@@ -2,15 +2,140 @@ package runtime
 
 import (
 	"context"
+	"errors"
+	"io"
+	"time"
 
 	"podium/internal/models"
 )
 
+// PullProgress reports the state of one layer of an image pull.
+type PullProgress struct {
+	Layer   string
+	Status  string
+	Current int64
+	Total   int64
+}
+
+// ExecResult is the outcome of a command run inside a container.
+type ExecResult struct {
+	ExitCode int
+	Output   string
+}
+
+// ExecOptions configures an interactive command started with StartExec.
+type ExecOptions struct {
+	Cmd    []string
+	TTY    bool
+	Stdin  bool
+	Width  uint
+	Height uint
+}
+
+// ExecSession is an interactive command running inside a container. Output
+// is delivered to the writers passed to StartExec; with a TTY, stderr is
+// merged into stdout.
+type ExecSession interface {
+	// Write sends input to the command's stdin.
+	Write(p []byte) (int, error)
+	CloseStdin() error
+	Resize(ctx context.Context, width, height uint) error
+	// Wait blocks until the command exits and returns its exit code.
+	Wait(ctx context.Context) (int, error)
+	Close() error
+}
+
+// LogOptions selects the log entries returned by GetContainerLogs.
+type LogOptions struct {
+	// Follow keeps the stream open for new output until the container stops
+	// or the context is cancelled.
+	Follow bool
+	Since  time.Time
+	Until  time.Time
+	// Tail is the number of most recent entries to return; a negative value
+	// returns all of them.
+	Tail   int
+	Stdout bool
+	Stderr bool
+}
+
+// LogEntry is one line of container output.
+type LogEntry struct {
+	Stream    string
+	Timestamp time.Time
+	Line      string
+}
+
+const (
+	LogStreamStdout = "stdout"
+	LogStreamStderr = "stderr"
+)
+
+// ErrNoContainerIP is returned by ContainerIP for containers that have no
+// address of their own, such as those sharing the host network.
+var ErrNoContainerIP = errors.New("container has no address of its own")
+
+// ErrVolumeIncomplete is returned by ImportVolume when it failed after
+// clearing the volume, leaving none or only part of the archive in it.
+var ErrVolumeIncomplete = errors.New("volume was cleared but not fully imported")
+
+// ErrNetworkExists is returned by CreateNetwork for a network of the same
+// name that Podium did not create, which it must not take over.
+var ErrNetworkExists = errors.New("a network of that name exists and is not managed by Podium")
+
 type Runtime interface {
 	CreateContainer(ctx context.Context, spec models.Container) error
 	StartContainer(ctx context.Context, id string) error
 	StopContainer(ctx context.Context, id string) error
+	// PauseContainer freezes the processes of a running container, which is
+	// still reported as running, until UnpauseContainer.
+	PauseContainer(ctx context.Context, id string) error
+	UnpauseContainer(ctx context.Context, id string) error
 	DeleteContainer(ctx context.Context, id string) error
 	GetContainerStatus(ctx context.Context, id string) (models.ContainerState, error)
-	GetContainerLogs(ctx context.Context, id string) (string, error)
-}
\ No newline at end of file
+	// ListContainers returns the IDs of every container Podium has created
+	// in the runtime, running or not.
+	ListContainers(ctx context.Context) ([]string, error)
+	// ContainerAddress returns the host:port address at which Podium reaches
+	// the given port of the container.
+	ContainerAddress(ctx context.Context, id string, containerPort int) (string, error)
+	// ContainerIP returns the IP address other containers reach the
+	// container at, failing with ErrNoContainerIP if it has none of its own.
+	ContainerIP(ctx context.Context, id string) (string, error)
+	// HostAddress returns the IP address at which containers reach servers
+	// listening on the host.
+	HostAddress(ctx context.Context) (string, error)
+	// CreateNetwork creates an isolated network containers can be attached
+	// to with models.Container.Networks. Creating one that Podium already
+	// created is not an error, but one created by anything else fails with
+	// ErrNetworkExists.
+	CreateNetwork(ctx context.Context, network models.Network) error
+	// DeleteNetwork removes a network. Removing one that does not exist is
+	// not an error.
+	DeleteNetwork(ctx context.Context, name string) error
+	// CreateVolume creates a named volume containers can mount and returns
+	// where its data is kept on the host. Creating one that already exists
+	// is not an error.
+	CreateVolume(ctx context.Context, volume models.Volume) (string, error)
+	// DeleteVolume removes a volume and its data. Removing one that does not
+	// exist is not an error.
+	DeleteVolume(ctx context.Context, name string) error
+	// ExportVolume writes the data in a volume to w as a tar archive, with
+	// paths relative to the root of the volume.
+	ExportVolume(ctx context.Context, name string, w io.Writer) error
+	// ImportVolume replaces the data in a volume with the contents of a tar
+	// archive written by ExportVolume. If it fails before clearing the
+	// volume, the volume is left as it was; if it fails after, the error
+	// wraps ErrVolumeIncomplete.
+	ImportVolume(ctx context.Context, name string, r io.Reader) error
+	// GetContainerLogs calls fn for each log entry selected by opts, in order.
+	// It returns the first error returned by fn.
+	GetContainerLogs(ctx context.Context, id string, opts LogOptions, fn func(LogEntry) error) error
+	// GetContainerStats returns a sample of the container's resource usage.
+	// CPUPercent is left for the caller to derive from consecutive samples.
+	GetContainerStats(ctx context.Context, id string) (models.ContainerStats, error)
+	ImageExists(ctx context.Context, image string) (bool, error)
+	PullImage(ctx context.Context, image string, auth *models.RegistryCredential, progress func(PullProgress)) error
+	Exec(ctx context.Context, id string, cmd []string) (ExecResult, error)
+	StartExec(ctx context.Context, id string, opts ExecOptions, stdout, stderr io.Writer) (ExecSession, error)
+}
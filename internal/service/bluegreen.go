@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"podium/internal/models"
+)
+
+// runBlueGreen runs one pass of a blue-green update. A complete "green" set
+// of replicas of the new spec is started next to the "blue" set, publishing
+// no host ports. Once every green replica is healthy, the service's host
+// ports move over to the green set and the blue set is kept on standby.
+//
+// It reports false when the update should be carried on as a rolling update
+// instead: when the service does not use the blue-green strategy, has no old
+// replicas to cut over from, or is rolling back to a spec none of its
+// replicas run.
+func (m *RuntimeServiceManager) runBlueGreen(ctx context.Context, serviceID string) bool {
+	m.mu.Lock()
+	service, err := m.store.GetService(serviceID)
+	if err != nil || updateConfig(&service).Strategy != models.UpdateStrategyBlueGreen {
+		m.mu.Unlock()
+		return false
+	}
+	status := service.UpdateStatus
+	if status == nil || (status.State != models.UpdateStateUpdating && status.State != models.UpdateStateRollingBack) {
+		m.mu.Unlock()
+		return true
+	}
+
+	green, blue := m.splitReplicas(service)
+	if status.Rollback {
+		if len(green) == 0 {
+			m.mu.Unlock()
+			return false
+		}
+		// The replicas being rolled back to were never taken down, so the
+		// new set only has to go.
+		for _, container := range blue {
+			m.removeReplica(ctx, &service, container)
+		}
+		m.completeRollout(&service)
+		m.mu.Unlock()
+		return true
+	}
+	if len(blue) == 0 {
+		m.mu.Unlock()
+		return false
+	}
+
+	timeout, deadline := batchTimeout(&service)
+	if timeout <= 0 {
+		m.handleBatchFailure(&service, deadlineError(&service, deadline, nil))
+		m.mu.Unlock()
+		return true
+	}
+
+	batch := m.startGreen(ctx, &service, green)
+	if err := m.store.UpdateService(service); err != nil {
+		log.Printf("Failed to save service %s: %v", service.ID, err)
+	}
+	m.mu.Unlock()
+
+	m.waitForBatch(ctx, batch, timeout)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if service, err = m.store.GetService(serviceID); err != nil {
+		log.Printf("Stopping blue-green update of service %s: %v", serviceID, err)
+		return true
+	}
+	if ctx.Err() != nil {
+		return true
+	}
+	for _, s := range batch {
+		if s.err != nil {
+			m.handleBatchFailure(&service, deadlineError(&service, deadline, s.err))
+			return true
+		}
+	}
+	if service.UpdateStatus.State != models.UpdateStateUpdating {
+		// Paused while the green set was starting.
+		return true
+	}
+
+	m.cutOver(ctx, &service)
+	return true
+}
+
+// startGreen starts the green replicas that are not running yet, with no
+// host ports published, and returns a batch covering the whole green set.
+func (m *RuntimeServiceManager) startGreen(ctx context.Context, service *models.Service, green []models.Container) []*slot {
+	var batch []*slot
+	running := map[int]bool{}
+	for _, container := range green {
+		container := container
+		running[replicaIndex(container)] = true
+		batch = append(batch, &slot{index: replicaIndex(container), replacement: &container})
+	}
+
+	for i := 0; i < service.Replicas; i++ {
+		if running[i] {
+			continue
+		}
+		s := &slot{index: i}
+		batch = append(batch, s)
+
+		container := serviceContainer(service, i)
+		for j := range container.Ports {
+			container.Ports[j].HostPort = 0
+		}
+		if err := m.launchContainer(ctx, service, container); err != nil {
+			s.err = fmt.Errorf("failed to start replica %d: %w", i, err)
+			continue
+		}
+		s.replacement = &container
+	}
+	return batch
+}
+
+// cutOver moves the service's host ports from the blue set to a new green
+// replica 0, then puts the blue set on standby and completes the update. If
+// the new replica fails, the blue replica is started again. The caller must
+// hold m.mu, which is released while the new replica starts.
+func (m *RuntimeServiceManager) cutOver(ctx context.Context, service *models.Service) {
+	green, blue := m.splitReplicas(*service)
+
+	if servicePublishesHostPorts(service) && !publishesHostPorts(green) {
+		for _, container := range blue {
+			if publishesHostPort(container) {
+				m.stopReplica(ctx, container)
+			}
+		}
+
+		container, err := m.createReplica(ctx, service, 0)
+		if err == nil {
+			if err := m.store.UpdateService(*service); err != nil {
+				log.Printf("Failed to save service %s: %v", service.ID, err)
+			}
+			timeout := updateConfig(service).HealthyTimeout
+			m.mu.Unlock()
+			err = m.waitHealthy(ctx, container, (&slot{replacement: &container}).timeout(timeout))
+			m.mu.Lock()
+
+			reloaded, getErr := m.store.GetService(service.ID)
+			if getErr != nil {
+				log.Printf("Stopping blue-green update of service %s: %v", service.ID, getErr)
+				return
+			}
+			*service = reloaded
+			if err == nil {
+				err = ctx.Err()
+			}
+			if err != nil {
+				cleanupCtx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+				defer cancel()
+				m.removeReplica(cleanupCtx, service, container)
+			}
+		}
+		if err != nil {
+			cleanupCtx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+			defer cancel()
+			for _, container := range blue {
+				if publishesHostPort(container) {
+					m.startReplica(cleanupCtx, container)
+				}
+			}
+			if ctx.Err() != nil {
+				if err := m.store.UpdateService(*service); err != nil {
+					log.Printf("Failed to save service %s: %v", service.ID, err)
+				}
+				return
+			}
+			m.handleBatchFailure(service, fmt.Errorf("replica 0 failed on the service's host ports: %w", err))
+			return
+		}
+		green, blue = m.splitReplicas(*service)
+	}
+
+	// The replica publishing the host ports replaces its green counterpart.
+	for _, holder := range green {
+		if !publishesHostPort(holder) {
+			continue
+		}
+		for _, container := range green {
+			if container.ID != holder.ID && replicaIndex(container) == replicaIndex(holder) {
+				m.removeReplica(ctx, service, container)
+			}
+		}
+	}
+
+	m.keepStandby(ctx, service, blue)
+	log.Printf("Service %s cut over to revision %d", service.ID, service.Revision)
+	m.completeRollout(service)
+}
+
+// keepStandby moves the blue replicas out of the service and keeps them on
+// standby, replacing any previous standby set. Replicas of specs other than
+// the one the service ran before the update are removed. The caller must
+// hold m.mu.
+func (m *RuntimeServiceManager) keepStandby(ctx context.Context, service *models.Service, blue []models.Container) {
+	m.removeStandby(ctx, service)
+
+	status := service.UpdateStatus
+	previous := ""
+	if status.PreviousSpec != nil {
+		previous = status.PreviousSpec.Hash()
+	}
+
+	standby := &models.StandbyReplicas{
+		Revision:  status.PreviousRevision,
+		ExpiresAt: time.Now().Add(updateConfig(service).StandbyRetention),
+	}
+	for _, container := range blue {
+		if container.Labels[specHashLabel] != previous {
+			m.removeReplica(ctx, service, container)
+			continue
+		}
+		if publishesHostPort(container) {
+			m.stopReplica(ctx, container)
+		}
+		standby.ContainerIDs = append(standby.ContainerIDs, container.ID)
+		removeContainerID(service, container.ID)
+	}
+	if len(standby.ContainerIDs) > 0 {
+		service.Standby = standby
+	}
+}
+
+// switchToStandby puts the service's standby replicas, which run the given
+// revision, back in service and the current replicas on standby in their
+// place. The caller must hold m.mu.
+func (m *RuntimeServiceManager) switchToStandby(ctx context.Context, service *models.Service, target models.ServiceRevision) error {
+	standby := m.containersByID(service.Standby.ContainerIDs)
+	if len(standby) == 0 {
+		return fmt.Errorf("standby replicas of service %s no longer exist", service.ID)
+	}
+	active := m.serviceContainers(*service)
+
+	for _, container := range active {
+		if publishesHostPort(container) {
+			m.stopReplica(ctx, container)
+		}
+	}
+	for _, container := range standby {
+		if container.State == models.ContainerStateRunning {
+			continue
+		}
+		if err := m.startReplica(ctx, container); err != nil {
+			for _, container := range active {
+				if publishesHostPort(container) {
+					m.startReplica(ctx, container)
+				}
+			}
+			return fmt.Errorf("failed to start standby replica %d: %w", replicaIndex(container), err)
+		}
+	}
+
+	now := time.Now()
+	previous := service.Spec()
+	previousRevision := service.Revision
+	retired := &models.StandbyReplicas{
+		Revision:  service.Revision,
+		ExpiresAt: now.Add(updateConfig(service).StandbyRetention),
+	}
+	for _, container := range active {
+		retired.ContainerIDs = append(retired.ContainerIDs, container.ID)
+	}
+	service.ContainerIDs = nil
+	for _, container := range standby {
+		service.ContainerIDs = append(service.ContainerIDs, container.ID)
+	}
+	service.Standby = retired
+
+	service.SetSpec(target.Spec)
+	service.Revision = target.Revision
+	service.UpdatedAt = now
+	service.UpdateStatus = &models.UpdateStatus{
+		State:            models.UpdateStateRollingBack,
+		PreviousSpec:     &previous,
+		PreviousRevision: previousRevision,
+		Rollback:         true,
+		RollbackReason:   "Switched back to the standby replicas by request",
+		StartedAt:        now,
+		LastProgressAt:   now,
+	}
+
+	log.Printf("Service %s switched back to its standby replicas of revision %d", service.ID, target.Revision)
+	m.completeRollout(service)
+	return nil
+}
+
+// removeStandby removes the service's standby replicas. The caller must hold
+// m.mu.
+func (m *RuntimeServiceManager) removeStandby(ctx context.Context, service *models.Service) {
+	if service.Standby == nil {
+		return
+	}
+	for _, id := range service.Standby.ContainerIDs {
+		if err := m.removeServiceContainer(ctx, service, id); err != nil {
+			log.Printf("Failed to remove standby container %s of service %s: %v", id, service.ID, err)
+		}
+	}
+	service.Standby = nil
+}
+
+// splitReplicas divides the replicas of a service into those running its
+// current spec and the rest.
+func (m *RuntimeServiceManager) splitReplicas(service models.Service) (current, outdated []models.Container) {
+	target := service.Spec().Hash()
+	for _, container := range m.serviceContainers(service) {
+		if container.Labels[specHashLabel] == target {
+			current = append(current, container)
+		} else {
+			outdated = append(outdated, container)
+		}
+	}
+	return current, outdated
+}
+
+// stopReplica stops a replica without removing it, so it can be started
+// again.
+func (m *RuntimeServiceManager) stopReplica(ctx context.Context, container models.Container) {
+	if err := m.runtime.StopContainer(ctx, container.ID); err != nil {
+		log.Printf("Warning: Failed to stop container %s: %v", container.ID, err)
+	}
+	now := time.Now()
+	container.State = models.ContainerStateSucceeded
+	container.FinishedAt = &now
+	if err := m.store.UpdateContainer(container); err != nil {
+		log.Printf("Warning: Failed to update container %s in database: %v", container.ID, err)
+	}
+}
+
+// startReplica starts a replica stopped by stopReplica.
+func (m *RuntimeServiceManager) startReplica(ctx context.Context, container models.Container) error {
+	if err := m.runtime.StartContainer(ctx, container.ID); err != nil {
+		log.Printf("Error starting container %s: %v", container.ID, err)
+		return err
+	}
+	now := time.Now()
+	container.State = models.ContainerStateRunning
+	container.StartedAt = &now
+	container.FinishedAt = nil
+	if err := m.store.UpdateContainer(container); err != nil {
+		log.Printf("Warning: Failed to update container %s in database: %v", container.ID, err)
+	}
+	return nil
+}
+
+// containersByID returns the stored containers with the given IDs, skipping
+// any that no longer exist.
+func (m *RuntimeServiceManager) containersByID(ids []string) []models.Container {
+	containers := make([]models.Container, 0, len(ids))
+	for _, id := range ids {
+		container, err := m.store.GetContainer(id)
+		if err != nil {
+			log.Printf("Warning: Container %s not found in database: %v", id, err)
+			continue
+		}
+		containers = append(containers, container)
+	}
+	return containers
+}
+
+func removeContainerID(service *models.Service, containerID string) {
+	for i, id := range service.ContainerIDs {
+		if id == containerID {
+			service.ContainerIDs = append(service.ContainerIDs[:i], service.ContainerIDs[i+1:]...)
+			return
+		}
+	}
+}
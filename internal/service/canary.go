@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"podium/internal/models"
+)
+
+// CanaryStatus compares the canary replicas of a canary update with the
+// stable replicas still running the previous revision.
+type CanaryStatus struct {
+	// Weight is the percentage of traffic sent to the canary replicas.
+	Weight         int
+	Revision       int
+	StableRevision int
+	Canary         ReplicaGroupStatus
+	Stable         ReplicaGroupStatus
+}
+
+// ReplicaGroupStatus sums up the health of a group of replicas.
+type ReplicaGroupStatus struct {
+	Replicas  int
+	Running   int
+	Healthy   int
+	Unhealthy int
+	Restarts  int
+}
+
+// PromoteCanary rolls the spec of a canary that is waiting to be promoted
+// out to the rest of the service's replicas.
+func (m *RuntimeServiceManager) PromoteCanary(ctx context.Context, serviceID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	service, err := m.store.GetService(serviceID)
+	if err != nil {
+		return err
+	}
+	status := service.UpdateStatus
+	if status == nil || status.State != models.UpdateStateCanary {
+		return fmt.Errorf("service %s has no canary waiting to be promoted", serviceID)
+	}
+
+	status.Promoted = true
+	status.State = models.UpdateStateUpdating
+	status.Message = fmt.Sprintf("%s in progress", updateKind(&service))
+	status.LastProgressAt = time.Now()
+	if err := m.store.UpdateService(service); err != nil {
+		return err
+	}
+
+	log.Printf("Promoting canary of service %s to revision %d", serviceID, service.Revision)
+	m.events.Publish(models.Event{
+		Type:      models.EventServiceCanaryPromoted,
+		ServiceID: service.ID,
+		Message:   fmt.Sprintf("Canary of service %s promoted to every replica", service.Name),
+		Attributes: map[string]string{
+			"revision": strconv.Itoa(service.Revision),
+		},
+	})
+
+	m.startRollout(serviceID)
+	return nil
+}
+
+// canaryLimit returns how many more replicas a canary update may replace
+// before it has to wait to be promoted. It reports false if the service has
+// no canary update waiting for promotion.
+func (m *RuntimeServiceManager) canaryLimit(service *models.Service) (int, bool) {
+	status := service.UpdateStatus
+	if updateConfig(service).Strategy != models.UpdateStrategyCanary || status == nil || status.Promoted || status.Rollback {
+		return 0, false
+	}
+	return canaryReplicas(service) - m.countUpdated(*service), true
+}
+
+// startCanary records that the canary replicas are running and the update is
+// waiting to be promoted. The caller must hold m.mu.
+func (m *RuntimeServiceManager) startCanary(service *models.Service) {
+	config := updateConfig(service)
+	status := service.UpdateStatus
+	status.State = models.UpdateStateCanary
+	status.UpdatedReplicas = m.countUpdated(*service)
+	status.Message = fmt.Sprintf("Canary running on %d of %d replicas with %d%% of traffic, waiting to be promoted",
+		status.UpdatedReplicas, service.Replicas, config.CanaryWeight)
+	if err := m.store.UpdateService(*service); err != nil {
+		log.Printf("Failed to save service %s: %v", service.ID, err)
+	}
+
+	log.Printf("Canary of service %s running on %d replicas", service.ID, status.UpdatedReplicas)
+	m.events.Publish(models.Event{
+		Type:      models.EventServiceCanaryStarted,
+		ServiceID: service.ID,
+		Message:   fmt.Sprintf("Canary of service %s revision %d running on %d of %d replicas", service.Name, service.Revision, status.UpdatedReplicas, service.Replicas),
+		Attributes: map[string]string{
+			"revision": strconv.Itoa(service.Revision),
+			"replicas": strconv.Itoa(status.UpdatedReplicas),
+			"weight":   strconv.Itoa(config.CanaryWeight),
+		},
+	})
+}
+
+// canaryReplicas returns how many replicas a canary update of the service
+// replaces before waiting to be promoted.
+func canaryReplicas(service *models.Service) int {
+	config := updateConfig(service)
+	count := config.CanaryReplicas
+	if count <= 0 {
+		count = (service.Replicas*config.CanaryWeight + 99) / 100
+	}
+	return min(max(count, 1), service.Replicas)
+}
+
+// canaryStatus compares the canary replicas of a service with its stable
+// replicas, or returns nil if the service has no canary running.
+func canaryStatus(service models.Service, containers []models.Container, states map[string]models.ContainerState) *CanaryStatus {
+	status := service.UpdateStatus
+	if updateConfig(&service).Strategy != models.UpdateStrategyCanary || !status.InProgress() || status.Promoted || status.Rollback {
+		return nil
+	}
+
+	result := &CanaryStatus{
+		Weight:         updateConfig(&service).CanaryWeight,
+		Revision:       service.Revision,
+		StableRevision: status.PreviousRevision,
+	}
+	target := service.Spec().Hash()
+	for _, container := range containers {
+		group := &result.Stable
+		if container.Labels[specHashLabel] == target {
+			group = &result.Canary
+		}
+		group.Replicas++
+		group.Restarts += container.RestartCount
+		if states[container.ID] == models.ContainerStateRunning {
+			group.Running++
+		}
+		switch {
+		case container.HealthCheck == nil && states[container.ID] == models.ContainerStateRunning,
+			container.Health.Status == models.HealthStatusHealthy:
+			group.Healthy++
+		case container.Health.Status == models.HealthStatusUnhealthy:
+			group.Unhealthy++
+		}
+	}
+	return result
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+
+	"podium/internal/models"
+)
+
+func TestCanaryReplicas(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int
+		config   models.UpdateConfig
+		want     int
+	}{
+		{"replica count", 4, models.UpdateConfig{CanaryReplicas: 2, CanaryWeight: 75}, 2},
+		{"weight", 4, models.UpdateConfig{CanaryWeight: 50}, 2},
+		{"weight rounded up", 4, models.UpdateConfig{CanaryWeight: 10}, 1},
+		{"at least one", 4, models.UpdateConfig{}, 1},
+		{"at most all", 3, models.UpdateConfig{CanaryReplicas: 5}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Strategy = models.UpdateStrategyCanary
+			service := &models.Service{Replicas: tt.replicas, UpdateConfig: &config}
+			if got := canaryReplicas(service); got != tt.want {
+				t.Errorf("canaryReplicas() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
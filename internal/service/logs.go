@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"podium/internal/models"
+	"podium/internal/runtime"
+)
+
+// logMergeDelay is how long followed entries are held back so that lines
+// written at about the same time by different replicas come out in
+// timestamp order.
+const logMergeDelay = 250 * time.Millisecond
+
+// ReplicaLogEntry is a log entry from one replica of a service.
+type ReplicaLogEntry struct {
+	Replica int
+	runtime.LogEntry
+}
+
+// ServiceLogs calls fn for the log entries of every replica of a service,
+// merged in timestamp order. opts.Tail applies to the merged output. With
+// opts.Follow, new entries are streamed until every replica has stopped or
+// ctx is cancelled; replicas added after the call are not followed.
+func (m *RuntimeServiceManager) ServiceLogs(ctx context.Context, serviceID string, opts runtime.LogOptions, fn func(ReplicaLogEntry) error) error {
+	service, err := m.store.GetService(serviceID)
+	if err != nil {
+		return err
+	}
+	containers := m.serviceContainers(service)
+
+	// History up to now is read in full and sorted; following resumes from
+	// the same instant so no entry is returned twice.
+	historyOpts := opts
+	historyOpts.Follow = false
+	cutoff := time.Now()
+	follow := opts.Follow && (opts.Until.IsZero() || opts.Until.After(cutoff))
+	if follow {
+		historyOpts.Until = cutoff
+	}
+
+	var history []ReplicaLogEntry
+	for _, container := range containers {
+		replica := replicaIndex(container)
+		err := m.runtime.GetContainerLogs(ctx, container.ID, historyOpts, func(entry runtime.LogEntry) error {
+			history = append(history, ReplicaLogEntry{Replica: replica, LogEntry: entry})
+			return nil
+		})
+		if err != nil {
+			log.Printf("Error getting logs for container %s of service %s: %v", container.ID, serviceID, err)
+		}
+	}
+
+	sortReplicaLogs(history)
+	if opts.Tail >= 0 && len(history) > opts.Tail {
+		history = history[len(history)-opts.Tail:]
+	}
+	for _, entry := range history {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	if !follow {
+		return nil
+	}
+	return m.followServiceLogs(ctx, serviceID, containers, opts, cutoff, fn)
+}
+
+func (m *RuntimeServiceManager) followServiceLogs(ctx context.Context, serviceID string, containers []models.Container, opts runtime.LogOptions, since time.Time, fn func(ReplicaLogEntry) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	followOpts := opts
+	followOpts.Since = since
+	followOpts.Tail = -1
+
+	entries := make(chan ReplicaLogEntry, 256)
+	var wg sync.WaitGroup
+	for _, container := range containers {
+		wg.Add(1)
+		go func(container models.Container) {
+			defer wg.Done()
+			replica := replicaIndex(container)
+			err := m.runtime.GetContainerLogs(ctx, container.ID, followOpts, func(entry runtime.LogEntry) error {
+				select {
+				case entries <- ReplicaLogEntry{Replica: replica, LogEntry: entry}:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Error following logs for container %s of service %s: %v", container.ID, serviceID, err)
+			}
+		}(container)
+	}
+	go func() {
+		wg.Wait()
+		close(entries)
+	}()
+
+	ticker := time.NewTicker(logMergeDelay / 2)
+	defer ticker.Stop()
+
+	var pending []ReplicaLogEntry
+	emit := func(before time.Time) error {
+		sortReplicaLogs(pending)
+		n := 0
+		for n < len(pending) && (before.IsZero() || pending[n].Timestamp.Before(before)) {
+			if err := fn(pending[n]); err != nil {
+				return err
+			}
+			n++
+		}
+		pending = pending[n:]
+		return nil
+	}
+
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				return emit(time.Time{})
+			}
+			pending = append(pending, entry)
+		case <-ticker.C:
+			if err := emit(time.Now().Add(-logMergeDelay)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func sortReplicaLogs(entries []ReplicaLogEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+}
@@ -4,23 +4,43 @@ import (
 	"context"
 
 	"podium/internal/models"
+	"podium/internal/runtime"
 )
 
 type Manager interface {
 	CreateService(ctx context.Context, service *models.Service) error
 	UpdateService(ctx context.Context, service *models.Service) error
+	PauseUpdate(ctx context.Context, serviceID string) error
+	ResumeUpdate(ctx context.Context, serviceID string) error
+	AbortUpdate(ctx context.Context, serviceID string) error
+	RollbackService(ctx context.Context, serviceID string, revision int) error
+	PromoteCanary(ctx context.Context, serviceID string) error
 	DeleteService(ctx context.Context, serviceID string) error
 	ScaleService(ctx context.Context, serviceID string, replicas int) error
 	GetServiceStatus(ctx context.Context, serviceID string) (*ServiceStatus, error)
 	ReconcileServices(ctx context.Context) error
+	ServiceLogs(ctx context.Context, serviceID string, opts runtime.LogOptions, fn func(ReplicaLogEntry) error) error
 }
 
 type ServiceStatus struct {
-	ServiceID      string
+	ServiceID       string
 	DesiredReplicas int
 	CurrentReplicas int
 	HealthyReplicas int
-	Containers     []ContainerStatus
+	// UpdatedReplicas counts the replicas running the current spec, whose
+	// hash is SpecHash.
+	UpdatedReplicas int
+	SpecHash        string
+	Revision        int
+	// LastHealthyRevision is the revision a failed update rolls back to.
+	LastHealthyRevision int
+	Update              *models.UpdateStatus
+	// Standby lists the replicas a blue-green update cut over from.
+	Standby *models.StandbyReplicas
+	// Canary compares the canary and stable replicas of a canary update
+	// until it is promoted.
+	Canary     *CanaryStatus
+	Containers []ContainerStatus
 }
 
 type ContainerStatus struct {
@@ -28,6 +48,7 @@ type ContainerStatus struct {
 	Name        string
 	Status      string
 	HealthState string
+	SpecHash    string
 	CreatedAt   string
 	StartedAt   string
 }
\ No newline at end of file
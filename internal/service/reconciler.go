@@ -4,6 +4,8 @@ import (
 	"context"
 	"log"
 	"time"
+
+	"podium/internal/metrics"
 )
 
 type Reconciler struct {
@@ -48,7 +50,10 @@ func (r *Reconciler) reconcileLoop() {
 		select {
 		case <-ticker.C:
 			ctx, cancel := context.WithTimeout(context.Background(), r.interval/2)
-			if err := r.manager.ReconcileServices(ctx); err != nil {
+			start := time.Now()
+			err := r.manager.ReconcileServices(ctx)
+			metrics.ObserveReconcile(time.Since(start), err)
+			if err != nil {
 				log.Printf("Error reconciling services: %v", err)
 			}
 			cancel()
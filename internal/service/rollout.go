@@ -0,0 +1,1012 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"podium/internal/health"
+	"podium/internal/models"
+)
+
+const (
+	// specHashLabel records the hash of the spec a replica was created from,
+	// so a rolling update can tell which replicas are out of date.
+	specHashLabel = "podium.service.spec"
+	// readyDelay is how long a new replica without a health check has to keep
+	// running before the update moves on.
+	readyDelay = 5 * time.Second
+	// cleanupTimeout bounds the runtime calls made to undo a failed batch.
+	cleanupTimeout = time.Minute
+)
+
+// rollout is a rolling update running in the background.
+type rollout struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// slot is a replica index being replaced in the current batch.
+type slot struct {
+	index int
+	// outdated holds the replicas of this index that run an old spec.
+	outdated []models.Container
+	// removed holds the outdated replicas taken down before their
+	// replacement was started, so they can be restored if it fails.
+	removed []models.Container
+	// replacement is the new replica, once created.
+	replacement *models.Container
+	err         error
+}
+
+// UpdateService stores the new spec of a service and replaces its replicas
+// in the background, in batches limited by the service's update config. If
+// an update is already running it carries on towards the new spec.
+func (m *RuntimeServiceManager) UpdateService(ctx context.Context, service *models.Service) error {
+	if err := ValidateUpdateConfig(service); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, err := m.store.GetService(service.ID)
+	if err != nil {
+		return err
+	}
+	service.Replicas = current.Replicas
+	service.ContainerIDs = current.ContainerIDs
+	service.UpdateStatus = current.UpdateStatus
+	service.Standby = current.Standby
+
+	service.Revision = current.Revision
+	service.LastHealthyRevision = current.LastHealthyRevision
+
+	// Without a spec change there is nothing to roll out, and an update in
+	// progress carries on as it was.
+	if service.Spec().Hash() == current.Spec().Hash() {
+		return m.store.UpdateService(*service)
+	}
+
+	// Services created before revisions were recorded get one for the spec
+	// they run, so the update can be rolled back.
+	if current.Revision == 0 {
+		revision, err := m.recordRevision(&current)
+		if err != nil {
+			return err
+		}
+		current.Revision = revision
+	}
+
+	revision, err := m.recordRevision(service)
+	if err != nil {
+		return err
+	}
+
+	return m.beginUpdate(service, current, revision,
+		fmt.Sprintf("%s of service %s to revision %d (image %s) started", updateKind(service), service.Name, revision, service.Image))
+}
+
+// RollbackService redeploys the spec of a stored revision with a rolling
+// update. Revision 0 means the revision the service ran before its latest
+// update. If a blue-green update left replicas of the revision on standby,
+// the service switches back to them instead.
+func (m *RuntimeServiceManager) RollbackService(ctx context.Context, serviceID string, revision int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, err := m.store.GetService(serviceID)
+	if err != nil {
+		return err
+	}
+
+	if revision == 0 {
+		if current.UpdateStatus == nil || current.UpdateStatus.PreviousRevision == 0 {
+			return fmt.Errorf("service %s has no previous revision", serviceID)
+		}
+		revision = current.UpdateStatus.PreviousRevision
+	}
+	target, err := m.store.GetServiceRevision(serviceID, revision)
+	if err != nil {
+		return err
+	}
+	if revision == current.Revision && !current.UpdateStatus.InProgress() {
+		return fmt.Errorf("service %s already runs revision %d", serviceID, revision)
+	}
+
+	if current.Standby != nil && current.Standby.Revision == revision && !current.UpdateStatus.InProgress() {
+		return m.switchToStandby(ctx, &current, target)
+	}
+
+	service := current
+	service.SetSpec(target.Spec)
+	service.UpdatedAt = time.Now()
+
+	return m.beginUpdate(&service, current, revision,
+		fmt.Sprintf("Rolling service %s back to revision %d (image %s)", service.Name, revision, service.Image))
+}
+
+// beginUpdate stores service, which has current's replicas and the spec of
+// the given revision, and starts rolling it out. The caller must hold m.mu.
+func (m *RuntimeServiceManager) beginUpdate(service *models.Service, current models.Service, revision int, message string) error {
+	// An update that replaces one still in progress rolls back to the spec
+	// that was running before either of them.
+	previous := current.Spec()
+	previousRevision := current.Revision
+	if current.UpdateStatus.InProgress() && current.UpdateStatus.PreviousSpec != nil {
+		previous = *current.UpdateStatus.PreviousSpec
+		previousRevision = current.UpdateStatus.PreviousRevision
+	}
+
+	now := time.Now()
+	service.Revision = revision
+	service.UpdateStatus = &models.UpdateStatus{
+		State:            models.UpdateStateUpdating,
+		Message:          fmt.Sprintf("%s in progress", updateKind(service)),
+		PreviousSpec:     &previous,
+		PreviousRevision: previousRevision,
+		StartedAt:        now,
+		LastProgressAt:   now,
+	}
+	if err := m.store.UpdateService(*service); err != nil {
+		return err
+	}
+
+	log.Printf("%s", message)
+	m.events.Publish(models.Event{
+		Type:      models.EventServiceUpdateStarted,
+		ServiceID: service.ID,
+		Message:   message,
+		Attributes: map[string]string{
+			"image":    service.Image,
+			"spec":     service.Spec().Hash(),
+			"revision": strconv.Itoa(revision),
+		},
+	})
+
+	m.startRollout(service.ID)
+	return nil
+}
+
+// recordRevision stores the service's spec as a new revision and returns its
+// number.
+func (m *RuntimeServiceManager) recordRevision(service *models.Service) (int, error) {
+	revision := models.ServiceRevision{
+		ServiceID: service.ID,
+		Spec:      service.Spec(),
+		SpecHash:  service.Spec().Hash(),
+		CreatedAt: time.Now(),
+	}
+	if err := m.store.SaveServiceRevision(&revision); err != nil {
+		return 0, fmt.Errorf("failed to record revision: %w", err)
+	}
+	return revision.Revision, nil
+}
+
+// PauseUpdate stops a rolling update once its current batch has finished.
+func (m *RuntimeServiceManager) PauseUpdate(ctx context.Context, serviceID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	service, err := m.store.GetService(serviceID)
+	if err != nil {
+		return err
+	}
+	status := service.UpdateStatus
+	if status == nil || (status.State != models.UpdateStateUpdating && status.State != models.UpdateStateRollingBack) {
+		return fmt.Errorf("service %s has no update in progress", serviceID)
+	}
+
+	status.State = models.UpdateStatePaused
+	status.Message = "Paused by request"
+	if err := m.store.UpdateService(service); err != nil {
+		return err
+	}
+
+	m.events.Publish(models.Event{
+		Type:      models.EventServiceUpdatePaused,
+		ServiceID: service.ID,
+		Message:   fmt.Sprintf("%s of service %s paused by request", updateKind(&service), service.Name),
+	})
+	return nil
+}
+
+// ResumeUpdate continues a paused rolling update.
+func (m *RuntimeServiceManager) ResumeUpdate(ctx context.Context, serviceID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	service, err := m.store.GetService(serviceID)
+	if err != nil {
+		return err
+	}
+	status := service.UpdateStatus
+	if status == nil || status.State != models.UpdateStatePaused {
+		return fmt.Errorf("service %s has no paused update", serviceID)
+	}
+
+	// Time spent paused does not count towards the progress deadline.
+	status.LastProgressAt = time.Now()
+	status.State = models.UpdateStateUpdating
+	status.Message = fmt.Sprintf("%s in progress", updateKind(&service))
+	if status.Rollback {
+		status.State = models.UpdateStateRollingBack
+		status.Message = "Rolling back to the previous spec"
+	}
+	if err := m.store.UpdateService(service); err != nil {
+		return err
+	}
+
+	m.startRollout(serviceID)
+	return nil
+}
+
+// AbortUpdate stops a rolling update and rolls the service back to the spec
+// it ran before the update.
+func (m *RuntimeServiceManager) AbortUpdate(ctx context.Context, serviceID string) error {
+	// The running batch is cancelled and undone before rolling back.
+	m.stopRollout(serviceID)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.allowRollout(serviceID)
+
+	service, err := m.store.GetService(serviceID)
+	if err != nil {
+		return err
+	}
+	if !service.UpdateStatus.InProgress() {
+		return fmt.Errorf("service %s has no update in progress", serviceID)
+	}
+	if service.UpdateStatus.Rollback {
+		// Already rolling back; just make sure it is running.
+		service.UpdateStatus.State = models.UpdateStateRollingBack
+		if err := m.store.UpdateService(service); err != nil {
+			return err
+		}
+		m.startRollout(serviceID)
+		return nil
+	}
+
+	return m.beginRollback(&service, "Aborted by request")
+}
+
+// beginRollback switches an in-progress update to rolling back to the spec
+// the service ran before it. The caller must hold m.mu.
+func (m *RuntimeServiceManager) beginRollback(service *models.Service, reason string) error {
+	spec, revision, err := m.rollbackTarget(service)
+	if err != nil {
+		return err
+	}
+
+	service.SetSpec(spec)
+	service.Revision = revision
+	service.UpdatedAt = time.Now()
+
+	status := service.UpdateStatus
+	status.State = models.UpdateStateRollingBack
+	status.Rollback = true
+	status.RollbackReason = reason
+	status.Message = fmt.Sprintf("Rolling back to revision %d: %s", revision, reason)
+	status.LastProgressAt = time.Now()
+	if err := m.store.UpdateService(*service); err != nil {
+		return err
+	}
+
+	log.Printf("Rolling back service %s to revision %d: %s", service.ID, revision, reason)
+	m.events.Publish(models.Event{
+		Type:      models.EventServiceUpdateRollingBack,
+		ServiceID: service.ID,
+		Message:   fmt.Sprintf("Rolling service %s back to revision %d: %s", service.Name, revision, reason),
+		Attributes: map[string]string{
+			"revision": strconv.Itoa(revision),
+			"reason":   reason,
+		},
+	})
+
+	m.startRollout(service.ID)
+	return nil
+}
+
+// rollbackTarget returns the spec and revision a failed update reverts to:
+// the last revision deployed healthily, or failing that the one the service
+// ran before the update.
+func (m *RuntimeServiceManager) rollbackTarget(service *models.Service) (models.ServiceSpec, int, error) {
+	if healthy := service.LastHealthyRevision; healthy > 0 && healthy != service.Revision {
+		revision, err := m.store.GetServiceRevision(service.ID, healthy)
+		if err == nil {
+			return revision.Spec, revision.Revision, nil
+		}
+		log.Printf("Failed to load last healthy revision of service %s: %v", service.ID, err)
+	}
+
+	status := service.UpdateStatus
+	if status.PreviousSpec == nil {
+		return models.ServiceSpec{}, 0, fmt.Errorf("service %s has no previous spec to roll back to", service.ID)
+	}
+	return *status.PreviousSpec, status.PreviousRevision, nil
+}
+
+// startRollout runs the rolling update of a service in the background unless
+// one is already running or being stopped. The caller must hold m.mu.
+func (m *RuntimeServiceManager) startRollout(serviceID string) {
+	if _, ok := m.rollouts[serviceID]; ok || m.stopping[serviceID] > 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &rollout{cancel: cancel, done: make(chan struct{})}
+	m.rollouts[serviceID] = r
+
+	go func() {
+		defer close(r.done)
+		defer cancel()
+		for {
+			if !m.runBlueGreen(ctx, serviceID) {
+				m.runRollout(ctx, serviceID)
+			}
+
+			m.mu.Lock()
+			// The update may have been resumed while this one was finishing.
+			if ctx.Err() == nil && m.rolloutWanted(serviceID) {
+				m.mu.Unlock()
+				continue
+			}
+			delete(m.rollouts, serviceID)
+			m.mu.Unlock()
+			return
+		}
+	}()
+}
+
+// stopRollout cancels the rolling update of a service, if one is running,
+// and waits for it to undo its current batch. Until the caller, holding
+// m.mu again, calls allowRollout, neither the reconciler nor the API can
+// start another one. The caller must not hold m.mu.
+func (m *RuntimeServiceManager) stopRollout(serviceID string) {
+	m.mu.Lock()
+	m.stopping[serviceID]++
+	r, ok := m.rollouts[serviceID]
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	r.cancel()
+	<-r.done
+}
+
+// allowRollout lets rollouts of the service start again after stopRollout.
+// The caller must hold m.mu.
+func (m *RuntimeServiceManager) allowRollout(serviceID string) {
+	m.stopping[serviceID]--
+	if m.stopping[serviceID] <= 0 {
+		delete(m.stopping, serviceID)
+	}
+}
+
+// rolloutWanted reports whether the service has an update that should be
+// running. The caller must hold m.mu.
+func (m *RuntimeServiceManager) rolloutWanted(serviceID string) bool {
+	service, err := m.store.GetService(serviceID)
+	if err != nil || service.UpdateStatus == nil {
+		return false
+	}
+	state := service.UpdateStatus.State
+	return state == models.UpdateStateUpdating || state == models.UpdateStateRollingBack
+}
+
+// rolloutActive reports whether a rolling update is running for the
+// service. The caller must hold m.mu.
+func (m *RuntimeServiceManager) rolloutActive(serviceID string) bool {
+	_, ok := m.rollouts[serviceID]
+	return ok
+}
+
+// runRollout replaces the outdated replicas of a service batch by batch
+// until all of them run its current spec, the update is paused, or ctx is
+// cancelled.
+func (m *RuntimeServiceManager) runRollout(ctx context.Context, serviceID string) {
+	for {
+		m.mu.Lock()
+		service, err := m.store.GetService(serviceID)
+		if err != nil {
+			m.mu.Unlock()
+			log.Printf("Stopping rolling update of service %s: %v", serviceID, err)
+			return
+		}
+		status := service.UpdateStatus
+		if status == nil || (status.State != models.UpdateStateUpdating && status.State != models.UpdateStateRollingBack) {
+			m.mu.Unlock()
+			return
+		}
+
+		if limit, ok := m.canaryLimit(&service); ok && limit <= 0 && m.countUpdated(service) < service.Replicas {
+			m.startCanary(&service)
+			m.mu.Unlock()
+			return
+		}
+
+		batch := m.nextBatch(ctx, &service)
+		if len(batch) == 0 {
+			m.completeRollout(&service)
+			m.mu.Unlock()
+			return
+		}
+
+		timeout, deadline := batchTimeout(&service)
+		if timeout <= 0 {
+			m.handleBatchFailure(&service, deadlineError(&service, deadline, nil))
+			m.mu.Unlock()
+			continue
+		}
+
+		m.startBatch(ctx, &service, batch)
+		if err := m.store.UpdateService(service); err != nil {
+			log.Printf("Failed to save service %s: %v", service.ID, err)
+		}
+		m.mu.Unlock()
+
+		m.waitForBatch(ctx, batch, timeout)
+
+		m.mu.Lock()
+		if service, err = m.store.GetService(serviceID); err != nil {
+			m.mu.Unlock()
+			log.Printf("Stopping rolling update of service %s: %v", serviceID, err)
+			return
+		}
+		failed := m.finishBatch(ctx, &service, batch)
+		if ctx.Err() != nil {
+			if err := m.store.UpdateService(service); err != nil {
+				log.Printf("Failed to save service %s: %v", service.ID, err)
+			}
+			m.mu.Unlock()
+			return
+		}
+		if failed != nil {
+			m.handleBatchFailure(&service, deadlineError(&service, deadline, failed))
+			m.mu.Unlock()
+			continue
+		}
+
+		service.UpdateStatus.UpdatedReplicas = m.countUpdated(service)
+		service.UpdateStatus.LastProgressAt = time.Now()
+		if err := m.store.UpdateService(service); err != nil {
+			log.Printf("Failed to save service %s: %v", service.ID, err)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// batchTimeout returns how long the next batch of new replicas has to become
+// healthy: the health timeout, cut short by the update's progress deadline,
+// which it also returns.
+func batchTimeout(service *models.Service) (time.Duration, time.Time) {
+	config := updateConfig(service)
+	status := service.UpdateStatus
+	deadline := status.LastProgressAt
+	if deadline.IsZero() {
+		deadline = status.StartedAt
+	}
+	deadline = deadline.Add(config.ProgressDeadline)
+
+	timeout := config.HealthyTimeout
+	if remaining := time.Until(deadline); remaining < timeout {
+		// Whole seconds read better in messages. Rounding up keeps a batch
+		// from failing before it starts when less than half a second is
+		// left.
+		timeout = remaining.Truncate(time.Second)
+		if timeout < remaining {
+			timeout += time.Second
+		}
+	}
+	return timeout, deadline
+}
+
+// deadlineError explains a batch failure caused by running past the
+// progress deadline. Other failures are returned as they are; a batch with
+// no failure of its own failed because no time was left.
+func deadlineError(service *models.Service, deadline time.Time, failure error) error {
+	if failure != nil && time.Now().Before(deadline) {
+		return failure
+	}
+	limit := updateConfig(service).ProgressDeadline
+	if failure == nil {
+		return fmt.Errorf("no progress within the %s progress deadline", limit)
+	}
+	return fmt.Errorf("no progress within the %s progress deadline: %w", limit, failure)
+}
+
+// nextBatch removes replicas the service no longer needs and picks the next
+// replica indices to replace. Missing replicas come first, since they are
+// already unavailable.
+func (m *RuntimeServiceManager) nextBatch(ctx context.Context, service *models.Service) []*slot {
+	target := service.Spec().Hash()
+	config := updateConfig(service)
+
+	byIndex := map[int][]models.Container{}
+	for _, container := range m.serviceContainers(*service) {
+		index := replicaIndex(container)
+		if index >= service.Replicas {
+			m.removeReplica(ctx, service, container)
+			continue
+		}
+		byIndex[index] = append(byIndex[index], container)
+	}
+
+	var missing, outdated []*slot
+	for i := 0; i < service.Replicas; i++ {
+		var current, old []models.Container
+		for _, container := range byIndex[i] {
+			if container.Labels[specHashLabel] == target {
+				current = append(current, container)
+			} else {
+				old = append(old, container)
+			}
+		}
+
+		switch {
+		case len(current) > 0:
+			// Left over from an interrupted batch.
+			for _, container := range old {
+				m.removeReplica(ctx, service, container)
+			}
+		case len(old) == 0:
+			missing = append(missing, &slot{index: i})
+		default:
+			outdated = append(outdated, &slot{index: i, outdated: old})
+		}
+	}
+
+	// Canaries replace the highest replica indices first, leaving replica 0
+	// and the host ports it publishes on the stable spec.
+	if config.Strategy == models.UpdateStrategyCanary {
+		sort.Slice(outdated, func(i, j int) bool {
+			return outdated[i].index > outdated[j].index
+		})
+	}
+
+	batch := append(missing, outdated...)
+	// Blue-green configs, whose leftovers are rolled out here, need not set
+	// any limits.
+	size := max(config.MaxSurge+config.MaxUnavailable, 1)
+	if limit, ok := m.canaryLimit(service); ok && size > limit {
+		size = max(limit, 0)
+	}
+	if size > len(batch) {
+		size = len(batch)
+	}
+	return batch[:size]
+}
+
+// startBatch takes down the outdated replicas that may be unavailable during
+// the batch and starts the replacements.
+func (m *RuntimeServiceManager) startBatch(ctx context.Context, service *models.Service, batch []*slot) {
+	config := updateConfig(service)
+	surge := config.MaxSurge
+
+	for _, s := range batch {
+		if len(s.outdated) == 0 {
+			continue
+		}
+		// Replicas publishing fixed host ports have to make way for their
+		// replacement, whatever the surge allows.
+		if surge > 0 && !publishesHostPorts(s.outdated) {
+			surge--
+			continue
+		}
+		for _, container := range s.outdated {
+			if m.removeReplica(ctx, service, container) {
+				s.removed = append(s.removed, container)
+			}
+		}
+	}
+
+	for _, s := range batch {
+		container, err := m.createReplica(ctx, service, s.index)
+		if err != nil {
+			s.err = fmt.Errorf("failed to start replica %d: %w", s.index, err)
+			continue
+		}
+		s.replacement = &container
+	}
+}
+
+// waitForBatch waits for every replacement in the batch to become healthy.
+func (m *RuntimeServiceManager) waitForBatch(ctx context.Context, batch []*slot, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, s := range batch {
+		if s.replacement == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(s *slot) {
+			defer wg.Done()
+			if err := m.waitHealthy(ctx, *s.replacement, s.timeout(timeout)); err != nil {
+				s.err = fmt.Errorf("replica %d: %w", s.index, err)
+			}
+		}(s)
+	}
+	wg.Wait()
+}
+
+// finishBatch removes the replicas replaced by healthy replacements. Failed
+// replacements are removed and the replicas they replaced are restored. It
+// returns the first failure, if any.
+func (m *RuntimeServiceManager) finishBatch(ctx context.Context, service *models.Service, batch []*slot) error {
+	cleanupCtx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	var failed error
+	for _, s := range batch {
+		err := s.err
+		if err == nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+
+		if err == nil {
+			for _, container := range s.outdated {
+				if !containsContainer(s.removed, container.ID) {
+					m.removeReplica(cleanupCtx, service, container)
+				}
+			}
+			continue
+		}
+
+		if failed == nil && !errors.Is(err, context.Canceled) {
+			failed = err
+		}
+		if s.replacement != nil {
+			m.removeReplica(cleanupCtx, service, *s.replacement)
+		}
+		for _, container := range s.removed {
+			if err := m.restoreReplica(cleanupCtx, service, container); err != nil {
+				log.Printf("Failed to restore replica %d of service %s: %v", s.index, service.ID, err)
+			}
+		}
+	}
+	return failed
+}
+
+// handleBatchFailure pauses the update, or rolls it back when the service's
+// update config asks for that. The caller must hold m.mu.
+func (m *RuntimeServiceManager) handleBatchFailure(service *models.Service, failure error) {
+	log.Printf("%s of service %s failed: %v", updateKind(service), service.ID, failure)
+	status := service.UpdateStatus
+
+	if updateConfig(service).FailureAction == models.UpdateFailureActionRollback && !status.Rollback {
+		err := m.beginRollback(service, failure.Error())
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to roll back service %s: %v", service.ID, err)
+	}
+
+	status.State = models.UpdateStatePaused
+	status.Message = fmt.Sprintf("Paused after a failure: %v", failure)
+	status.UpdatedReplicas = m.countUpdated(*service)
+	if err := m.store.UpdateService(*service); err != nil {
+		log.Printf("Failed to save service %s: %v", service.ID, err)
+	}
+
+	m.events.Publish(models.Event{
+		Type:      models.EventServiceUpdatePaused,
+		ServiceID: service.ID,
+		Message:   fmt.Sprintf("%s of service %s paused: %v", updateKind(service), service.Name, failure),
+		Attributes: map[string]string{
+			"error": failure.Error(),
+		},
+	})
+}
+
+// completeRollout records that every replica runs the current spec. The
+// caller must hold m.mu.
+func (m *RuntimeServiceManager) completeRollout(service *models.Service) {
+	status := service.UpdateStatus
+	now := time.Now()
+	status.CompletedAt = &now
+	status.UpdatedReplicas = m.countUpdated(*service)
+	service.LastHealthyRevision = service.Revision
+
+	eventType := models.EventServiceUpdateCompleted
+	message := fmt.Sprintf("%s of service %s to revision %d completed", updateKind(service), service.Name, service.Revision)
+	attributes := map[string]string{
+		"image":    service.Image,
+		"spec":     service.Spec().Hash(),
+		"revision": strconv.Itoa(service.Revision),
+	}
+	if status.Rollback {
+		status.State = models.UpdateStateRolledBack
+		status.Message = fmt.Sprintf("Rolled back to revision %d: %s", service.Revision, status.RollbackReason)
+		eventType = models.EventServiceUpdateRolledBack
+		message = fmt.Sprintf("Service %s rolled back to revision %d: %s", service.Name, service.Revision, status.RollbackReason)
+		attributes["reason"] = status.RollbackReason
+	} else {
+		status.State = models.UpdateStateCompleted
+		status.Message = "All replicas run the current spec"
+	}
+
+	if err := m.store.UpdateService(*service); err != nil {
+		log.Printf("Failed to save service %s: %v", service.ID, err)
+	}
+	log.Printf("%s", message)
+	m.events.Publish(models.Event{
+		Type:       eventType,
+		ServiceID:  service.ID,
+		Message:    message,
+		Attributes: attributes,
+	})
+}
+
+// waitHealthy waits until the container passes its health check the number
+// of times its success threshold requires. Containers without a health
+// check only have to keep running for readyDelay.
+func (m *RuntimeServiceManager) waitHealthy(ctx context.Context, container models.Container, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	check := container.HealthCheck
+	delay := readyDelay
+	if check != nil {
+		delay = check.InitialDelay
+	}
+	if err := sleep(ctx, delay); err != nil {
+		return healthTimeoutError(err, timeout)
+	}
+
+	checker := health.NewChecker(m.runtime)
+	successes, failures := 0, 0
+	for {
+		state, err := m.runtime.GetContainerStatus(ctx, container.ID)
+		if err != nil {
+			return healthTimeoutError(err, timeout)
+		}
+		if state != models.ContainerStateRunning {
+			return fmt.Errorf("container exited (state: %s)", state)
+		}
+		// The health check worker restarting the replica means it is crash
+		// looping even if it happens to be running now.
+		if stored, err := m.store.GetContainer(container.ID); err == nil && stored.RestartCount > container.RestartCount {
+			return fmt.Errorf("container is crash looping (restarted %d times)", stored.RestartCount)
+		}
+		if check == nil {
+			return nil
+		}
+
+		status, _, err := checker.Check(ctx, container)
+		if err == nil && status == models.HealthStatusHealthy {
+			successes++
+			failures = 0
+			if successes >= max(check.SuccessThreshold, 1) {
+				return nil
+			}
+		} else {
+			successes = 0
+			failures++
+			if check.FailureThreshold > 0 && failures >= check.FailureThreshold {
+				if err == nil {
+					err = fmt.Errorf("status %s", status)
+				}
+				return fmt.Errorf("health check failed %d times: %v", failures, err)
+			}
+		}
+
+		interval := check.Interval
+		if interval <= 0 {
+			interval = 2 * time.Second
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return healthTimeoutError(err, timeout)
+		}
+	}
+}
+
+// createReplica starts a replica of the service's current spec.
+func (m *RuntimeServiceManager) createReplica(ctx context.Context, service *models.Service, index int) (models.Container, error) {
+	if err := m.createServiceContainer(ctx, service, index); err != nil {
+		return models.Container{}, err
+	}
+	return m.store.GetContainer(service.ContainerIDs[len(service.ContainerIDs)-1])
+}
+
+// restoreReplica starts a copy of a replica that was taken down for a
+// replacement that then failed.
+func (m *RuntimeServiceManager) restoreReplica(ctx context.Context, service *models.Service, old models.Container) error {
+	container := old
+	container.ID = uuid.New().String()
+	container.State = models.ContainerStatePending
+	container.CreatedAt = time.Now()
+	container.StartedAt = nil
+	container.FinishedAt = nil
+	container.Health = models.HealthState{}
+	container.RestartCount = 0
+
+	if err := m.launchContainer(ctx, service, container); err != nil {
+		return err
+	}
+	log.Printf("Restored replica %d of service %s as container %s", replicaIndex(container), service.ID, container.ID)
+	return nil
+}
+
+// removeReplica removes a replica, logging rather than returning failures,
+// and reports whether it was removed.
+func (m *RuntimeServiceManager) removeReplica(ctx context.Context, service *models.Service, container models.Container) bool {
+	if err := m.removeServiceContainer(ctx, service, container.ID); err != nil {
+		log.Printf("Failed to remove container %s of service %s: %v", container.ID, service.ID, err)
+		return false
+	}
+	return true
+}
+
+func (m *RuntimeServiceManager) countUpdated(service models.Service) int {
+	target := service.Spec().Hash()
+	count := 0
+	for _, container := range m.serviceContainers(service) {
+		if container.Labels[specHashLabel] == target {
+			count++
+		}
+	}
+	return count
+}
+
+// timeout extends fallback if the replacement's health check cannot pass
+// within it.
+func (s *slot) timeout(fallback time.Duration) time.Duration {
+	if s.replacement != nil && s.replacement.HealthCheck != nil {
+		check := s.replacement.HealthCheck
+		if minimum := check.InitialDelay + time.Duration(max(check.SuccessThreshold, 1))*check.Interval; minimum > fallback {
+			return minimum
+		}
+	}
+	return fallback
+}
+
+// updateKind names the kind of update the service's strategy makes, for
+// messages.
+func updateKind(service *models.Service) string {
+	switch updateConfig(service).Strategy {
+	case models.UpdateStrategyBlueGreen:
+		return "Blue-green update"
+	case models.UpdateStrategyCanary:
+		return "Canary update"
+	default:
+		return "Rolling update"
+	}
+}
+
+// updateConfig returns the service's update config with defaults filled in.
+func updateConfig(service *models.Service) models.UpdateConfig {
+	defaults := models.DefaultUpdateConfig()
+	if service.UpdateConfig == nil {
+		return defaults
+	}
+	result := *service.UpdateConfig
+	if result.HealthyTimeout <= 0 {
+		result.HealthyTimeout = defaults.HealthyTimeout
+	}
+	if result.ProgressDeadline <= 0 {
+		result.ProgressDeadline = defaults.ProgressDeadline
+	}
+	if result.FailureAction == "" {
+		result.FailureAction = defaults.FailureAction
+	}
+	if result.Strategy == "" {
+		result.Strategy = defaults.Strategy
+	}
+	if result.StandbyRetention <= 0 {
+		result.StandbyRetention = defaults.StandbyRetention
+	}
+	if result.CanaryWeight <= 0 {
+		result.CanaryWeight = defaults.CanaryWeight
+	}
+	return result
+}
+
+// ValidateUpdateConfig checks the strategy, limits and failure action of the
+// update config of a service. A nil config is valid and means the defaults.
+// Only the load balancer and the ingress can send the canary replicas their
+// share of traffic, or keep a blue-green update's new replicas out of it
+// until the cutover, so both strategies need one of them.
+func ValidateUpdateConfig(service *models.Service) error {
+	config := service.UpdateConfig
+	if config == nil {
+		return nil
+	}
+	if config.MaxSurge < 0 || config.MaxUnavailable < 0 {
+		return fmt.Errorf("maxSurge and maxUnavailable must not be negative")
+	}
+	if config.CanaryWeight < 0 || config.CanaryWeight > 100 {
+		return fmt.Errorf("canaryWeight must be between 0 and 100")
+	}
+	if config.CanaryReplicas < 0 {
+		return fmt.Errorf("canaryReplicas must not be negative")
+	}
+	switch config.Strategy {
+	case "", models.UpdateStrategyRolling, models.UpdateStrategyCanary:
+		if config.MaxSurge == 0 && config.MaxUnavailable == 0 {
+			return fmt.Errorf("maxSurge and maxUnavailable cannot both be zero")
+		}
+	case models.UpdateStrategyBlueGreen:
+	default:
+		return fmt.Errorf("unknown update strategy: %s", config.Strategy)
+	}
+	if config.Strategy == models.UpdateStrategyCanary || config.Strategy == models.UpdateStrategyBlueGreen {
+		if service.LoadBalancer == nil && len(service.Ingress) == 0 {
+			return fmt.Errorf("the %s strategy needs a load balancer or ingress rules", config.Strategy)
+		}
+	}
+	switch config.FailureAction {
+	case "", models.UpdateFailureActionPause, models.UpdateFailureActionRollback:
+		return nil
+	default:
+		return fmt.Errorf("unknown failure action: %s", config.FailureAction)
+	}
+}
+
+func publishesHostPorts(containers []models.Container) bool {
+	for _, container := range containers {
+		if publishesHostPort(container) {
+			return true
+		}
+	}
+	return false
+}
+
+func publishesHostPort(container models.Container) bool {
+	for _, port := range container.Ports {
+		if port.HostPort != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// servicePublishesHostPorts reports whether the service's replica 0
+// publishes host ports.
+func servicePublishesHostPorts(service *models.Service) bool {
+	if service.LoadBalancer != nil {
+		return false
+	}
+	for _, port := range service.Ports {
+		if port.HostPort != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func containsContainer(containers []models.Container, id string) bool {
+	for _, container := range containers {
+		if container.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func healthTimeoutError(err error, timeout time.Duration) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("not healthy within %s", timeout)
+	}
+	return err
+}
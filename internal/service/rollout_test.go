@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+
+	"podium/internal/models"
+)
+
+func TestValidateUpdateConfig(t *testing.T) {
+	balancer := &models.LoadBalancer{}
+	ingress := []models.IngressRule{{Host: "web.example.com"}}
+
+	tests := []struct {
+		name    string
+		service models.Service
+		wantErr bool
+	}{
+		{"no config", models.Service{}, false},
+		{"rolling", models.Service{UpdateConfig: &models.UpdateConfig{MaxSurge: 1}}, false},
+		{"blue-green without traffic routing", models.Service{
+			UpdateConfig: &models.UpdateConfig{Strategy: models.UpdateStrategyBlueGreen},
+		}, true},
+		{"blue-green with load balancer", models.Service{
+			LoadBalancer: balancer,
+			UpdateConfig: &models.UpdateConfig{Strategy: models.UpdateStrategyBlueGreen},
+		}, false},
+		{"blue-green with ingress", models.Service{
+			Ingress:      ingress,
+			UpdateConfig: &models.UpdateConfig{Strategy: models.UpdateStrategyBlueGreen},
+		}, false},
+		{"canary without traffic routing", models.Service{
+			UpdateConfig: &models.UpdateConfig{Strategy: models.UpdateStrategyCanary, MaxSurge: 1},
+		}, true},
+		{"canary with load balancer", models.Service{
+			LoadBalancer: balancer,
+			UpdateConfig: &models.UpdateConfig{Strategy: models.UpdateStrategyCanary, MaxSurge: 1},
+		}, false},
+		{"canary with ingress", models.Service{
+			Ingress:      ingress,
+			UpdateConfig: &models.UpdateConfig{Strategy: models.UpdateStrategyCanary, MaxSurge: 1},
+		}, false},
+		{"unknown strategy", models.Service{UpdateConfig: &models.UpdateConfig{Strategy: "big-bang"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUpdateConfig(&tt.service)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdateConfig() = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
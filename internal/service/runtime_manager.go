@@ -0,0 +1,564 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"podium/internal/events"
+	"podium/internal/image"
+	"podium/internal/models"
+	"podium/internal/network"
+	"podium/internal/runtime"
+	"podium/internal/store"
+	"podium/internal/volume"
+)
+
+// RuntimeServiceManager manages service replicas through a runtime.Runtime, so
+// services work with any runtime backend, including the in-memory fake.
+type RuntimeServiceManager struct {
+	runtime  runtime.Runtime
+	store    store.Store
+	puller   *image.Puller
+	networks *network.Manager
+	volumes  *volume.Manager
+	events   *events.Bus
+
+	// mu serialises changes to services and their replicas between the API,
+	// the reconciler and rolling updates.
+	mu       sync.Mutex
+	rollouts map[string]*rollout
+	// stopping counts, per service, the deletes and aborts waiting for its
+	// rollout to stop. No rollout is started for a service while they wait.
+	stopping map[string]int
+}
+
+func NewManager(runtime runtime.Runtime, store store.Store, puller *image.Puller, networks *network.Manager, volumes *volume.Manager, bus *events.Bus) *RuntimeServiceManager {
+	return &RuntimeServiceManager{
+		runtime:  runtime,
+		store:    store,
+		puller:   puller,
+		networks: networks,
+		volumes:  volumes,
+		events:   bus,
+		rollouts: make(map[string]*rollout),
+		stopping: make(map[string]int),
+	}
+}
+
+func (m *RuntimeServiceManager) CreateService(ctx context.Context, service *models.Service) error {
+	if err := ValidateUpdateConfig(service); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if service.Replicas <= 0 {
+		service.Replicas = 1
+	}
+
+	revision, err := m.recordRevision(service)
+	if err != nil {
+		return err
+	}
+	service.Revision = revision
+
+	for i := 0; i < service.Replicas; i++ {
+		if err := m.createServiceContainer(ctx, service, i); err != nil {
+			return fmt.Errorf("failed to create container %d for service %s: %w", i, service.ID, err)
+		}
+	}
+
+	service.State = models.ServiceStateRunning
+	return m.store.UpdateService(*service)
+}
+
+func (m *RuntimeServiceManager) createServiceContainer(ctx context.Context, service *models.Service, index int) error {
+	return m.launchContainer(ctx, service, serviceContainer(service, index))
+}
+
+// serviceContainer returns a new replica container of the service's current
+// spec. Only replica 0 publishes the service's host ports, and none do when
+// the service's load balancer listens on them.
+func serviceContainer(service *models.Service, index int) models.Container {
+	ports := make([]models.PortMapping, 0, len(service.Ports))
+	for _, port := range service.Ports {
+		hostPort := port.HostPort
+		if index > 0 || service.LoadBalancer != nil {
+			hostPort = 0
+		}
+		ports = append(ports, models.PortMapping{
+			ContainerPort: port.ContainerPort,
+			HostPort:      hostPort,
+		})
+	}
+
+	env := map[string]string{}
+	for k, v := range service.Env {
+		env[k] = v
+	}
+	env["PODIUM_SERVICE_ID"] = service.ID
+	env["PODIUM_SERVICE_NAME"] = service.Name
+	env["PODIUM_REPLICA_INDEX"] = strconv.Itoa(index)
+
+	return models.Container{
+		ID:            uuid.New().String(),
+		Name:          fmt.Sprintf("%s-%d", service.Name, index),
+		Image:         service.Image,
+		Command:       service.Command,
+		Env:           env,
+		Ports:         ports,
+		Resources:     service.Resources,
+		State:         models.ContainerStatePending,
+		NodeID:        "local",
+		CreatedAt:     time.Now(),
+		RestartPolicy: service.RestartPolicy,
+		HealthCheck:   service.HealthCheck,
+		Networks:      serviceNetworks(service),
+		Mounts:        service.Mounts,
+		Labels: map[string]string{
+			"podium.service.id":    service.ID,
+			"podium.service.name":  service.Name,
+			"podium.replica.index": strconv.Itoa(index),
+			"podium.managed":       "true",
+			specHashLabel:          service.Spec().Hash(),
+		},
+	}
+}
+
+// launchContainer creates and starts a replica container and adds it to the
+// service.
+func (m *RuntimeServiceManager) launchContainer(ctx context.Context, service *models.Service, container models.Container) error {
+	index := replicaIndex(container)
+
+	if err := m.puller.EnsureImage(ctx, container.Image, models.PullPolicyIfNotPresent); err != nil {
+		return err
+	}
+	if err := m.networks.EnsureNetworks(ctx, container.Networks); err != nil {
+		return err
+	}
+	if err := m.volumes.EnsureVolumes(ctx, container.Mounts); err != nil {
+		return err
+	}
+
+	if err := m.runtime.CreateContainer(ctx, container); err != nil {
+		return err
+	}
+
+	if err := m.runtime.StartContainer(ctx, container.ID); err != nil {
+		if deleteErr := m.runtime.DeleteContainer(ctx, container.ID); deleteErr != nil {
+			log.Printf("Cleanup failed: could not delete container %s: %v", container.ID, deleteErr)
+		}
+		return err
+	}
+
+	container.State = models.ContainerStateRunning
+	now := time.Now()
+	container.StartedAt = &now
+
+	if err := m.store.CreateContainer(container); err != nil {
+		return err
+	}
+
+	m.events.Publish(models.Event{
+		Type:        models.EventContainerCreated,
+		ContainerID: container.ID,
+		ServiceID:   service.ID,
+		Message:     fmt.Sprintf("Replica %d of service %s created", index, service.Name),
+		Attributes:  map[string]string{"name": container.Name, "image": container.Image, "replica": strconv.Itoa(index)},
+	})
+	m.events.Publish(models.Event{
+		Type:        models.EventContainerStarted,
+		ContainerID: container.ID,
+		ServiceID:   service.ID,
+		Message:     fmt.Sprintf("Replica %d of service %s started", index, service.Name),
+	})
+
+	service.ContainerIDs = append(service.ContainerIDs, container.ID)
+	return nil
+}
+
+func (m *RuntimeServiceManager) removeServiceContainer(ctx context.Context, service *models.Service, containerID string) error {
+	if err := m.runtime.StopContainer(ctx, containerID); err != nil {
+		log.Printf("Warning: Failed to stop container %s: %v", containerID, err)
+	}
+	if err := m.runtime.DeleteContainer(ctx, containerID); err != nil {
+		return err
+	}
+	if err := m.store.DeleteContainer(containerID); err != nil {
+		log.Printf("Warning: Failed to delete container from database: %v", err)
+	}
+
+	m.events.Publish(models.Event{
+		Type:        models.EventContainerStopped,
+		ContainerID: containerID,
+		ServiceID:   service.ID,
+		Message:     fmt.Sprintf("Replica of service %s stopped and removed", service.Name),
+	})
+
+	for i, id := range service.ContainerIDs {
+		if id == containerID {
+			service.ContainerIDs = append(service.ContainerIDs[:i], service.ContainerIDs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// DeleteService removes the replicas of a service, standby ones included,
+// and then the service itself. A rolling update in progress is cancelled
+// first, and its current batch undone.
+func (m *RuntimeServiceManager) DeleteService(ctx context.Context, serviceID string) error {
+	m.stopRollout(serviceID)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.allowRollout(serviceID)
+
+	service, err := m.store.GetService(serviceID)
+	if err != nil {
+		return err
+	}
+
+	for _, containerID := range append([]string(nil), service.ContainerIDs...) {
+		if err := m.removeServiceContainer(ctx, &service, containerID); err != nil {
+			log.Printf("Warning: Failed to remove container %s of service %s: %v", containerID, serviceID, err)
+			if err := m.store.DeleteContainer(containerID); err != nil {
+				log.Printf("Warning: Failed to delete container from database: %v", err)
+			}
+		}
+	}
+	m.removeStandby(ctx, &service)
+
+	return m.store.DeleteService(serviceID)
+}
+
+func (m *RuntimeServiceManager) ScaleService(ctx context.Context, serviceID string, replicas int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.scaleService(ctx, serviceID, replicas)
+}
+
+// scaleService does the work of ScaleService. The caller must hold m.mu.
+func (m *RuntimeServiceManager) scaleService(ctx context.Context, serviceID string, replicas int) error {
+	service, err := m.store.GetService(serviceID)
+	if err != nil {
+		return err
+	}
+	if service.UpdateStatus.InProgress() {
+		return fmt.Errorf("service %s cannot be scaled while an update is %s", serviceID, service.UpdateStatus.State)
+	}
+
+	containers := m.serviceContainers(service)
+	currentCount := len(containers)
+
+	if replicas > currentCount {
+		used := map[int]bool{}
+		for _, container := range containers {
+			used[replicaIndex(container)] = true
+		}
+		for i := 0; currentCount < replicas; i++ {
+			if used[i] {
+				continue
+			}
+			if err := m.createServiceContainer(ctx, &service, i); err != nil {
+				return err
+			}
+			currentCount++
+		}
+	}
+
+	if replicas < currentCount {
+		for _, container := range containers[replicas:] {
+			if err := m.removeServiceContainer(ctx, &service, container.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	previous := service.Replicas
+	service.Replicas = replicas
+	service.UpdatedAt = time.Now()
+	if err := m.store.UpdateService(service); err != nil {
+		return err
+	}
+
+	if previous != replicas || currentCount != replicas {
+		m.events.Publish(models.Event{
+			Type:      models.EventServiceScaled,
+			ServiceID: service.ID,
+			Message:   fmt.Sprintf("Service %s scaled from %d to %d replicas", service.Name, currentCount, replicas),
+			Attributes: map[string]string{
+				"from": strconv.Itoa(currentCount),
+				"to":   strconv.Itoa(replicas),
+			},
+		})
+	}
+	return nil
+}
+
+func (m *RuntimeServiceManager) GetServiceStatus(ctx context.Context, serviceID string) (*ServiceStatus, error) {
+	service, err := m.store.GetService(serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	containers := m.serviceContainers(service)
+
+	healthyCount := 0
+	updatedCount := 0
+	target := service.Spec().Hash()
+	containerStatuses := make([]ContainerStatus, 0, len(containers))
+	states := make(map[string]models.ContainerState, len(containers))
+
+	for _, container := range containers {
+		state, err := m.runtime.GetContainerStatus(ctx, container.ID)
+		if err != nil {
+			log.Printf("Error checking status of container %s: %v", container.ID, err)
+			state = models.ContainerStateFailed
+		}
+		states[container.ID] = state
+
+		healthState := string(models.HealthStatusUnknown)
+		if container.HealthCheck == nil && state == models.ContainerStateRunning {
+			healthState = string(models.HealthStatusHealthy)
+		} else if container.Health.Status != "" {
+			healthState = string(container.Health.Status)
+		}
+
+		if healthState == string(models.HealthStatusHealthy) {
+			healthyCount++
+		}
+
+		if container.Labels[specHashLabel] == target {
+			updatedCount++
+		}
+
+		status := ContainerStatus{
+			ID:          container.ID,
+			Name:        container.Name,
+			Status:      string(state),
+			HealthState: healthState,
+			SpecHash:    container.Labels[specHashLabel],
+			CreatedAt:   container.CreatedAt.Format(time.RFC3339),
+		}
+		if container.StartedAt != nil {
+			status.StartedAt = container.StartedAt.Format(time.RFC3339)
+		}
+		containerStatuses = append(containerStatuses, status)
+	}
+
+	return &ServiceStatus{
+		ServiceID:           serviceID,
+		DesiredReplicas:     service.Replicas,
+		CurrentReplicas:     len(containers),
+		HealthyReplicas:     healthyCount,
+		UpdatedReplicas:     updatedCount,
+		SpecHash:            target,
+		Revision:            service.Revision,
+		LastHealthyRevision: service.LastHealthyRevision,
+		Standby:             service.Standby,
+		Canary:              canaryStatus(service, containers, states),
+		Update:              service.UpdateStatus,
+		Containers:          containerStatuses,
+	}, nil
+}
+
+func (m *RuntimeServiceManager) ReconcileServices(ctx context.Context) error {
+	services, err := m.store.ListServices()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, service := range services {
+		if err := m.reconcile(ctx, service.ID); err != nil {
+			log.Printf("Error reconciling service %s: %v", service.ID, err)
+			errs = append(errs, fmt.Errorf("service %s: %w", service.ID, err))
+			m.events.Publish(models.Event{
+				Type:      models.EventServiceReconcileFailed,
+				ServiceID: service.ID,
+				Message:   err.Error(),
+			})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// reconcile reconciles one service, unless a rolling update is responsible
+// for its replicas. An update left unfinished by a restart is resumed.
+func (m *RuntimeServiceManager) reconcile(ctx context.Context, serviceID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.rolloutActive(serviceID) {
+		return nil
+	}
+
+	service, err := m.store.GetService(serviceID)
+	if err != nil {
+		return err
+	}
+	if service.UpdateStatus.InProgress() {
+		if m.rolloutWanted(serviceID) {
+			log.Printf("Resuming rolling update of service %s", serviceID)
+			m.startRollout(serviceID)
+		}
+		return nil
+	}
+
+	if service.Standby != nil && time.Now().After(service.Standby.ExpiresAt) {
+		log.Printf("Removing standby replicas of service %s", serviceID)
+		m.removeStandby(ctx, &service)
+		if err := m.store.UpdateService(service); err != nil {
+			return err
+		}
+	}
+
+	return m.reconcileService(ctx, service)
+}
+
+func (m *RuntimeServiceManager) reconcileService(ctx context.Context, service models.Service) error {
+	// Replicas whose container has disappeared from the runtime are recreated
+	// in place so they keep their replica index.
+	for _, container := range m.serviceContainers(service) {
+		if _, err := m.runtime.GetContainerStatus(ctx, container.ID); err == nil {
+			continue
+		}
+
+		log.Printf("Container %s of service %s is missing from the runtime, recreating", container.ID, service.ID)
+		if err := m.store.DeleteContainer(container.ID); err != nil {
+			log.Printf("Warning: Failed to delete container from database: %v", err)
+		}
+		for i, id := range service.ContainerIDs {
+			if id == container.ID {
+				service.ContainerIDs = append(service.ContainerIDs[:i], service.ContainerIDs[i+1:]...)
+				break
+			}
+		}
+		if err := m.createServiceContainer(ctx, &service, replicaIndex(container)); err != nil {
+			return err
+		}
+		if err := m.store.UpdateService(service); err != nil {
+			return err
+		}
+		m.events.Publish(models.Event{
+			Type:        models.EventServiceReplicaRecreated,
+			ContainerID: service.ContainerIDs[len(service.ContainerIDs)-1],
+			ServiceID:   service.ID,
+			Message:     fmt.Sprintf("Replica %d was missing from the runtime and has been recreated", replicaIndex(container)),
+			Attributes:  map[string]string{"replica": strconv.Itoa(replicaIndex(container)), "previousContainerId": container.ID},
+		})
+	}
+
+	// Replicas that have exited are recreated in place too, unless their
+	// restart policy leaves restarting them to the health worker, which caps
+	// how often it does, or they were stopped to back up or restore a volume
+	// and will be started again.
+	for _, container := range m.serviceContainers(service) {
+		if container.RestartPolicy == "Always" || container.RestartPolicy == "OnFailure" {
+			continue
+		}
+		if m.volumes.Quiesced(container.ID) {
+			continue
+		}
+		state, err := m.runtime.GetContainerStatus(ctx, container.ID)
+		if err != nil || (state != models.ContainerStateFailed && state != models.ContainerStateSucceeded) {
+			continue
+		}
+
+		log.Printf("Container %s of service %s has exited (state: %s), recreating", container.ID, service.ID, state)
+		if err := m.removeServiceContainer(ctx, &service, container.ID); err != nil {
+			return err
+		}
+		if err := m.createServiceContainer(ctx, &service, replicaIndex(container)); err != nil {
+			return err
+		}
+		if err := m.store.UpdateService(service); err != nil {
+			return err
+		}
+		m.events.Publish(models.Event{
+			Type:        models.EventServiceReplicaRecreated,
+			ContainerID: service.ContainerIDs[len(service.ContainerIDs)-1],
+			ServiceID:   service.ID,
+			Message:     fmt.Sprintf("Replica %d exited and has been recreated", replicaIndex(container)),
+			Attributes:  map[string]string{"replica": strconv.Itoa(replicaIndex(container)), "previousContainerId": container.ID},
+		})
+	}
+
+	status, err := m.GetServiceStatus(ctx, service.ID)
+	if err != nil {
+		return err
+	}
+
+	if status.CurrentReplicas != service.Replicas {
+		if err := m.scaleService(ctx, service.ID, service.Replicas); err != nil {
+			return err
+		}
+	}
+
+	for _, containerStatus := range status.Containers {
+		if containerStatus.HealthState != string(models.HealthStatusUnhealthy) {
+			continue
+		}
+		if err := m.runtime.StopContainer(ctx, containerStatus.ID); err != nil {
+			log.Printf("Warning: Failed to stop container %s: %v", containerStatus.ID, err)
+		}
+		if err := m.runtime.StartContainer(ctx, containerStatus.ID); err != nil {
+			log.Printf("Error restarting container %s: %v", containerStatus.ID, err)
+			continue
+		}
+		m.events.Publish(models.Event{
+			Type:        models.EventContainerRestarted,
+			ContainerID: containerStatus.ID,
+			ServiceID:   service.ID,
+			Message:     "Unhealthy replica restarted by the reconciler",
+		})
+	}
+
+	return nil
+}
+
+// serviceContainers returns the stored replica containers of a service,
+// ordered by replica index.
+func (m *RuntimeServiceManager) serviceContainers(service models.Service) []models.Container {
+	containers := make([]models.Container, 0, len(service.ContainerIDs))
+	for _, id := range service.ContainerIDs {
+		container, err := m.store.GetContainer(id)
+		if err != nil {
+			log.Printf("Warning: Container %s of service %s not found in database: %v", id, service.ID, err)
+			continue
+		}
+		containers = append(containers, container)
+	}
+
+	sort.Slice(containers, func(i, j int) bool {
+		return replicaIndex(containers[i]) < replicaIndex(containers[j])
+	})
+	return containers
+}
+
+// serviceNetworks returns the service's network attachments with the service
+// name added to the aliases, so replicas are reachable by it.
+func serviceNetworks(service *models.Service) []models.NetworkAttachment {
+	var attachments []models.NetworkAttachment
+	for _, attachment := range service.Networks {
+		aliases := append([]string{service.Name}, attachment.Aliases...)
+		attachments = append(attachments, models.NetworkAttachment{Network: attachment.Network, Aliases: aliases})
+	}
+	return attachments
+}
+
+func replicaIndex(container models.Container) int {
+	index, _ := strconv.Atoi(container.Labels["podium.replica.index"])
+	return index
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"podium/internal/events"
+	"podium/internal/image"
+	"podium/internal/models"
+	"podium/internal/network"
+	"podium/internal/runtime"
+	"podium/internal/store"
+	"podium/internal/volume"
+)
+
+// reconcileWriter reconciles every service when the backup is first written
+// to it, while the containers mounting the volume are stopped.
+type reconcileWriter struct {
+	manager *RuntimeServiceManager
+	err     error
+	done    bool
+}
+
+func (w *reconcileWriter) Write(p []byte) (int, error) {
+	if !w.done {
+		w.done = true
+		w.err = w.manager.ReconcileServices(context.Background())
+	}
+	return len(p), nil
+}
+
+func TestReconcileLeavesQuiescedReplicas(t *testing.T) {
+	ctx := context.Background()
+	stateStore := store.NewMemoryStore()
+	rt := runtime.NewFakeRuntime()
+	bus := events.NewBus(stateStore, 100)
+	puller := image.NewPuller(rt, stateStore)
+	networks := network.NewManager(stateStore, rt, time.Minute)
+	volumes := volume.NewManager(stateStore, rt, t.TempDir(), time.Minute)
+	manager := NewManager(rt, stateStore, puller, networks, volumes, bus)
+
+	svc := models.Service{
+		ID:       "svc-db",
+		Name:     "db",
+		Image:    "postgres:16",
+		Replicas: 1,
+		Mounts:   []models.Mount{{Source: "data", Target: "/var/lib/postgresql/data"}},
+	}
+	if err := stateStore.CreateService(svc); err != nil {
+		t.Fatalf("storing service: %v", err)
+	}
+	if err := manager.CreateService(ctx, &svc); err != nil {
+		t.Fatalf("creating service: %v", err)
+	}
+	replica := svc.ContainerIDs[0]
+
+	w := &reconcileWriter{manager: manager}
+	if err := volumes.Backup(ctx, "data", models.QuiesceStop, w); err != nil {
+		t.Fatalf("backing up volume: %v", err)
+	}
+	if w.err != nil {
+		t.Fatalf("reconciling during the backup: %v", w.err)
+	}
+
+	stored, err := stateStore.GetService(svc.ID)
+	if err != nil {
+		t.Fatalf("getting service: %v", err)
+	}
+	if len(stored.ContainerIDs) != 1 || stored.ContainerIDs[0] != replica {
+		t.Errorf("service has replicas %v after the backup, want %s kept", stored.ContainerIDs, replica)
+	}
+	if state, err := rt.GetContainerStatus(ctx, replica); err != nil || state != models.ContainerStateRunning {
+		t.Errorf("replica is %s, %v after the backup, want running", state, err)
+	}
+	if volumes.Quiesced(replica) {
+		t.Error("replica is still marked as quiesced after the backup")
+	}
+}
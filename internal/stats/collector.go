@@ -0,0 +1,140 @@
+package stats
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"podium/internal/models"
+	"podium/internal/runtime"
+	"podium/internal/store"
+)
+
+// Collector samples the resource usage of every running container at a fixed
+// interval and keeps a rolling in-memory history of the samples.
+type Collector struct {
+	store     store.Store
+	runtime   runtime.Runtime
+	interval  time.Duration
+	retention time.Duration
+	stopCh    chan struct{}
+
+	mu      sync.RWMutex
+	history map[string][]models.ContainerStats
+}
+
+func NewCollector(store store.Store, runtime runtime.Runtime, interval, retention time.Duration) *Collector {
+	return &Collector{
+		store:     store,
+		runtime:   runtime,
+		interval:  interval,
+		retention: retention,
+		stopCh:    make(chan struct{}),
+		history:   make(map[string][]models.ContainerStats),
+	}
+}
+
+func (c *Collector) Start() {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		c.collect()
+		for {
+			select {
+			case <-c.stopCh:
+				log.Println("Stats collector stopped")
+				return
+			case <-ticker.C:
+				c.collect()
+			}
+		}
+	}()
+	log.Println("Stats collector started")
+}
+
+func (c *Collector) Stop() {
+	close(c.stopCh)
+}
+
+func (c *Collector) collect() {
+	containers, err := c.store.ListContainers()
+	if err != nil {
+		log.Printf("Error listing containers for stats collection: %v", err)
+		return
+	}
+
+	running := make(map[string]bool, len(containers))
+	for _, container := range containers {
+		if container.State != models.ContainerStateRunning {
+			continue
+		}
+		running[container.ID] = true
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.interval)
+		sample, err := c.runtime.GetContainerStats(ctx, container.ID)
+		cancel()
+		if err != nil {
+			log.Printf("Error collecting stats for container %s: %v", container.ID, err)
+			continue
+		}
+		c.record(sample)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cutoff := time.Now().Add(-c.retention)
+	for id, samples := range c.history {
+		if !running[id] {
+			delete(c.history, id)
+			continue
+		}
+		i := 0
+		for i < len(samples) && samples[i].Timestamp.Before(cutoff) {
+			i++
+		}
+		c.history[id] = samples[i:]
+	}
+}
+
+// record derives the sample's CPU percentage from the container's previous
+// sample, if there is one, and adds it to the history.
+func (c *Collector) record(sample models.ContainerStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	samples := c.history[sample.ContainerID]
+	if len(samples) > 0 {
+		sample.CalculateCPUPercent(samples[len(samples)-1])
+	}
+	c.history[sample.ContainerID] = append(samples, sample)
+}
+
+// Latest returns the most recent sample of a container.
+func (c *Collector) Latest(id string) (models.ContainerStats, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	samples := c.history[id]
+	if len(samples) == 0 {
+		return models.ContainerStats{}, false
+	}
+	return samples[len(samples)-1], true
+}
+
+// History returns the samples of a container taken at or after since.
+func (c *Collector) History(id string, since time.Time) []models.ContainerStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	samples := c.history[id]
+	result := make([]models.ContainerStats, 0, len(samples))
+	for _, sample := range samples {
+		if !sample.Timestamp.Before(since) {
+			result = append(result, sample)
+		}
+	}
+	return result
+}
@@ -1,10 +1,13 @@
 package store
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"time"
 
+	"podium/internal/metrics"
 	"podium/internal/models"
 	bolt "go.etcd.io/bbolt"
 )
@@ -24,6 +27,38 @@ func NewBoltStore(path string) (*BoltStore, error) {
 		if err != nil {
 			return fmt.Errorf("failed to create containers bucket: %w", err)
 		}
+		_, err = tx.CreateBucketIfNotExists([]byte("services"))
+		if err != nil {
+			return fmt.Errorf("failed to create services bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte("registries"))
+		if err != nil {
+			return fmt.Errorf("failed to create registries bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte("events"))
+		if err != nil {
+			return fmt.Errorf("failed to create events bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte("service_revisions"))
+		if err != nil {
+			return fmt.Errorf("failed to create service revisions bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte("webhooks"))
+		if err != nil {
+			return fmt.Errorf("failed to create webhooks bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte("webhook_deliveries"))
+		if err != nil {
+			return fmt.Errorf("failed to create webhook deliveries bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte("networks"))
+		if err != nil {
+			return fmt.Errorf("failed to create networks bucket: %w", err)
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte("volumes"))
+		if err != nil {
+			return fmt.Errorf("failed to create volumes bucket: %w", err)
+		}
 		return nil
 	})
 	if err != nil {
@@ -37,8 +72,20 @@ func (s *BoltStore) Close() error {
 	return s.db.Close()
 }
 
+// view runs a read-only transaction, recording its duration under operation.
+func (s *BoltStore) view(operation string, fn func(tx *bolt.Tx) error) error {
+	defer metrics.ObserveStoreOperation(operation, time.Now())
+	return s.db.View(fn)
+}
+
+// update runs a read-write transaction, recording its duration under operation.
+func (s *BoltStore) update(operation string, fn func(tx *bolt.Tx) error) error {
+	defer metrics.ObserveStoreOperation(operation, time.Now())
+	return s.db.Update(fn)
+}
+
 func (s *BoltStore) CreateContainer(container models.Container) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update("CreateContainer", func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("containers"))
 		
 		data, err := json.Marshal(container)
@@ -53,12 +100,12 @@ func (s *BoltStore) CreateContainer(container models.Container) error {
 func (s *BoltStore) GetContainer(id string) (models.Container, error) {
 	var container models.Container
 	
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view("GetContainer", func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("containers"))
 		data := b.Get([]byte(id))
 		
 		if data == nil {
-			return fmt.Errorf("container not found: %s", id)
+			return fmt.Errorf("container %w: %s", ErrNotFound, id)
 		}
 		
 		return json.Unmarshal(data, &container)
@@ -70,7 +117,7 @@ func (s *BoltStore) GetContainer(id string) (models.Container, error) {
 func (s *BoltStore) ListContainers() ([]models.Container, error) {
 	var containers []models.Container
 	
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view("ListContainers", func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("containers"))
 		
 		return b.ForEach(func(k, v []byte) error {
@@ -88,7 +135,7 @@ func (s *BoltStore) ListContainers() ([]models.Container, error) {
 }
 
 func (s *BoltStore) UpdateContainer(container models.Container) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update("UpdateContainer", func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("containers"))
 		
 		data, err := json.Marshal(container)
@@ -101,7 +148,7 @@ func (s *BoltStore) UpdateContainer(container models.Container) error {
 }
 
 func (s *BoltStore) DeleteContainer(id string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update("DeleteContainer", func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("containers"))
 		return b.Delete([]byte(id))
 	})
@@ -110,7 +157,7 @@ func (s *BoltStore) DeleteContainer(id string) error {
 // Add these functions to your existing boltdb.go file
 
 func (s *BoltStore) CreateService(service models.Service) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update("CreateService", func(tx *bolt.Tx) error {
 		b, err := tx.CreateBucketIfNotExists([]byte("services"))
 		if err != nil {
 			return fmt.Errorf("failed to create services bucket: %w", err)
@@ -128,7 +175,7 @@ func (s *BoltStore) CreateService(service models.Service) error {
 func (s *BoltStore) GetService(id string) (models.Service, error) {
 	var service models.Service
 	
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view("GetService", func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("services"))
 		if b == nil {
 			return fmt.Errorf("services bucket not found")
@@ -136,7 +183,7 @@ func (s *BoltStore) GetService(id string) (models.Service, error) {
 		
 		data := b.Get([]byte(id))
 		if data == nil {
-			return fmt.Errorf("service not found: %s", id)
+			return fmt.Errorf("service %w: %s", ErrNotFound, id)
 		}
 		
 		return json.Unmarshal(data, &service)
@@ -146,7 +193,7 @@ func (s *BoltStore) GetService(id string) (models.Service, error) {
 }
 
 func (s *BoltStore) UpdateService(service models.Service) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update("UpdateService", func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("services"))
 		if b == nil {
 			return fmt.Errorf("services bucket not found")
@@ -161,12 +208,20 @@ func (s *BoltStore) UpdateService(service models.Service) error {
 	})
 }
 
+// DeleteService deletes the service along with its revisions.
 func (s *BoltStore) DeleteService(id string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update("DeleteService", func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("services"))
 		if b == nil {
 			return fmt.Errorf("services bucket not found")
 		}
+
+		revisions := tx.Bucket([]byte("service_revisions"))
+		if revisions.Bucket([]byte(id)) != nil {
+			if err := revisions.DeleteBucket([]byte(id)); err != nil {
+				return err
+			}
+		}
 		
 		return b.Delete([]byte(id))
 	})
@@ -175,7 +230,7 @@ func (s *BoltStore) DeleteService(id string) error {
 func (s *BoltStore) ListServices() ([]models.Service, error) {
 	var services []models.Service
 	
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view("ListServices", func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("services"))
 		if b == nil {
 			return nil
@@ -199,7 +254,7 @@ func (s *BoltStore) GetServiceByName(name string) (models.Service, error) {
 	var service models.Service
 	var found bool
 	
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view("GetServiceByName", func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("services"))
 		if b == nil {
 			return fmt.Errorf("services bucket not found")
@@ -221,17 +276,83 @@ func (s *BoltStore) GetServiceByName(name string) (models.Service, error) {
 	})
 	
 	if !found {
-		return service, fmt.Errorf("service not found: %s", name)
+		return service, fmt.Errorf("service %w: %s", ErrNotFound, name)
 	}
 	
 	return service, err
 }
 
 
+// SaveServiceRevision stores revision under the next revision number of its
+// service, which it sets. Revisions are never changed once stored.
+func (s *BoltStore) SaveServiceRevision(revision *models.ServiceRevision) error {
+	return s.update("SaveServiceRevision", func(tx *bolt.Tx) error {
+		b, err := tx.Bucket([]byte("service_revisions")).CreateBucketIfNotExists([]byte(revision.ServiceID))
+		if err != nil {
+			return fmt.Errorf("failed to create service revision bucket: %w", err)
+		}
+
+		n, err := b.NextSequence()
+		if err != nil {
+			return fmt.Errorf("failed to allocate revision number: %w", err)
+		}
+		revision.Revision = int(n)
+
+		data, err := json.Marshal(revision)
+		if err != nil {
+			return fmt.Errorf("failed to marshal service revision: %w", err)
+		}
+
+		return b.Put(eventKey(n), data)
+	})
+}
+
+func (s *BoltStore) GetServiceRevision(serviceID string, revision int) (models.ServiceRevision, error) {
+	var result models.ServiceRevision
+
+	err := s.view("GetServiceRevision", func(tx *bolt.Tx) error {
+		var data []byte
+		if b := tx.Bucket([]byte("service_revisions")).Bucket([]byte(serviceID)); b != nil && revision > 0 {
+			data = b.Get(eventKey(uint64(revision)))
+		}
+		if data == nil {
+			return fmt.Errorf("revision %d of service %s %w", revision, serviceID, ErrNotFound)
+		}
+
+		return json.Unmarshal(data, &result)
+	})
+
+	return result, err
+}
+
+// ListServiceRevisions returns the revisions of a service, oldest first.
+func (s *BoltStore) ListServiceRevisions(serviceID string) ([]models.ServiceRevision, error) {
+	var revisions []models.ServiceRevision
+
+	err := s.view("ListServiceRevisions", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("service_revisions")).Bucket([]byte(serviceID))
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			var revision models.ServiceRevision
+			if err := json.Unmarshal(v, &revision); err != nil {
+				return fmt.Errorf("failed to unmarshal service revision: %w", err)
+			}
+
+			revisions = append(revisions, revision)
+			return nil
+		})
+	})
+
+	return revisions, err
+}
+
 func (s *BoltStore) GetContainersByStatus(status string) ([]models.Container, error) {
 	var containers []models.Container
 	
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view("GetContainersByStatus", func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("containers"))
 		
 		return b.ForEach(func(k, v []byte) error {
@@ -240,7 +361,7 @@ func (s *BoltStore) GetContainersByStatus(status string) ([]models.Container, er
 				return fmt.Errorf("failed to unmarshal container: %w", err)
 			}
 			
-			if container.Status == status {
+			if string(container.State) == status {
 				containers = append(containers, container)
 			}
 			return nil
@@ -248,4 +369,393 @@ func (s *BoltStore) GetContainersByStatus(status string) ([]models.Container, er
 	})
 	
 	return containers, err
-}
\ No newline at end of file
+}
+
+func (s *BoltStore) SaveRegistryCredential(credential models.RegistryCredential) error {
+	return s.update("SaveRegistryCredential", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("registries"))
+
+		data, err := json.Marshal(credential)
+		if err != nil {
+			return fmt.Errorf("failed to marshal registry credential: %w", err)
+		}
+
+		return b.Put([]byte(credential.Registry), data)
+	})
+}
+
+func (s *BoltStore) GetRegistryCredential(registry string) (models.RegistryCredential, error) {
+	var credential models.RegistryCredential
+
+	err := s.view("GetRegistryCredential", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("registries"))
+		data := b.Get([]byte(registry))
+
+		if data == nil {
+			return fmt.Errorf("registry credential %w: %s", ErrNotFound, registry)
+		}
+
+		return json.Unmarshal(data, &credential)
+	})
+
+	return credential, err
+}
+
+func (s *BoltStore) ListRegistryCredentials() ([]models.RegistryCredential, error) {
+	var credentials []models.RegistryCredential
+
+	err := s.view("ListRegistryCredentials", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("registries"))
+
+		return b.ForEach(func(k, v []byte) error {
+			var credential models.RegistryCredential
+			if err := json.Unmarshal(v, &credential); err != nil {
+				return fmt.Errorf("failed to unmarshal registry credential: %w", err)
+			}
+
+			credentials = append(credentials, credential)
+			return nil
+		})
+	})
+
+	return credentials, err
+}
+
+func (s *BoltStore) DeleteRegistryCredential(registry string) error {
+	return s.update("DeleteRegistryCredential", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("registries"))
+		return b.Delete([]byte(registry))
+	})
+}
+
+// SaveEvent assigns the next event ID to event and stores it. Events are
+// keyed by ID in big-endian order, so they are iterated oldest first.
+func (s *BoltStore) SaveEvent(event *models.Event) error {
+	return s.update("SaveEvent", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("events"))
+
+		id, err := b.NextSequence()
+		if err != nil {
+			return fmt.Errorf("failed to allocate event ID: %w", err)
+		}
+		event.ID = id
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+
+		return b.Put(eventKey(id), data)
+	})
+}
+
+// ListEvents returns the events matching filter, oldest first.
+func (s *BoltStore) ListEvents(filter models.EventFilter) ([]models.Event, error) {
+	var events []models.Event
+
+	err := s.view("ListEvents", func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte("events")).Cursor()
+
+		for k, v := c.Seek(eventKey(filter.AfterID + 1)); k != nil; k, v = c.Next() {
+			var event models.Event
+			if err := json.Unmarshal(v, &event); err != nil {
+				return fmt.Errorf("failed to unmarshal event: %w", err)
+			}
+			if !filter.Matches(event) {
+				continue
+			}
+
+			events = append(events, event)
+			if filter.Limit > 0 && len(events) > filter.Limit {
+				events = events[1:]
+			}
+		}
+		return nil
+	})
+
+	return events, err
+}
+
+// PruneEvents deletes events older than the most recent keep.
+func (s *BoltStore) PruneEvents(keep int) error {
+	return s.update("PruneEvents", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("events"))
+
+		last := b.Sequence()
+		if last <= uint64(keep) {
+			return nil
+		}
+		oldest := eventKey(last - uint64(keep) + 1)
+
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, oldest) < 0; k, _ = c.First() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) SaveWebhook(webhook models.Webhook) error {
+	return s.update("SaveWebhook", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("webhooks"))
+
+		data, err := json.Marshal(webhook)
+		if err != nil {
+			return fmt.Errorf("failed to marshal webhook: %w", err)
+		}
+
+		return b.Put([]byte(webhook.ID), data)
+	})
+}
+
+func (s *BoltStore) GetWebhook(id string) (models.Webhook, error) {
+	var webhook models.Webhook
+
+	err := s.view("GetWebhook", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("webhooks"))
+		data := b.Get([]byte(id))
+
+		if data == nil {
+			return fmt.Errorf("webhook %w: %s", ErrNotFound, id)
+		}
+
+		return json.Unmarshal(data, &webhook)
+	})
+
+	return webhook, err
+}
+
+func (s *BoltStore) ListWebhooks() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+
+	err := s.view("ListWebhooks", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("webhooks"))
+
+		return b.ForEach(func(k, v []byte) error {
+			var webhook models.Webhook
+			if err := json.Unmarshal(v, &webhook); err != nil {
+				return fmt.Errorf("failed to unmarshal webhook: %w", err)
+			}
+
+			webhooks = append(webhooks, webhook)
+			return nil
+		})
+	})
+
+	return webhooks, err
+}
+
+// DeleteWebhook deletes the webhook along with its delivery log.
+func (s *BoltStore) DeleteWebhook(id string) error {
+	return s.update("DeleteWebhook", func(tx *bolt.Tx) error {
+		if err := tx.Bucket([]byte("webhooks")).Delete([]byte(id)); err != nil {
+			return err
+		}
+
+		deliveries := tx.Bucket([]byte("webhook_deliveries"))
+		if deliveries.Bucket([]byte(id)) == nil {
+			return nil
+		}
+		return deliveries.DeleteBucket([]byte(id))
+	})
+}
+
+// SaveWebhookDelivery stores delivery in the log of its webhook, which must
+// exist, assigning it the next delivery ID if it does not have one yet. Only
+// the most recent keep deliveries of each webhook are retained.
+func (s *BoltStore) SaveWebhookDelivery(delivery *models.WebhookDelivery, keep int) error {
+	return s.update("SaveWebhookDelivery", func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte("webhooks")).Get([]byte(delivery.WebhookID)) == nil {
+			return fmt.Errorf("webhook %w: %s", ErrNotFound, delivery.WebhookID)
+		}
+
+		deliveries := tx.Bucket([]byte("webhook_deliveries"))
+		b, err := deliveries.CreateBucketIfNotExists([]byte(delivery.WebhookID))
+		if err != nil {
+			return fmt.Errorf("failed to create webhook delivery bucket: %w", err)
+		}
+
+		isNew := delivery.ID == 0
+		if isNew {
+			id, err := deliveries.NextSequence()
+			if err != nil {
+				return fmt.Errorf("failed to allocate delivery ID: %w", err)
+			}
+			delivery.ID = id
+		}
+
+		data, err := json.Marshal(delivery)
+		if err != nil {
+			return fmt.Errorf("failed to marshal webhook delivery: %w", err)
+		}
+		if err := b.Put(eventKey(delivery.ID), data); err != nil {
+			return err
+		}
+
+		if !isNew || keep <= 0 {
+			return nil
+		}
+		// Delivery logs are short, so walking them is cheap.
+		var stale [][]byte
+		count := 0
+		c := b.Cursor()
+		for k, _ := c.Last(); k != nil; k, _ = c.Prev() {
+			if count++; count > keep {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+		}
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListWebhookDeliveries returns the deliveries recorded for a webhook, most
+// recent first.
+func (s *BoltStore) ListWebhookDeliveries(webhookID string) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	err := s.view("ListWebhookDeliveries", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("webhook_deliveries")).Bucket([]byte(webhookID))
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var delivery models.WebhookDelivery
+			if err := json.Unmarshal(v, &delivery); err != nil {
+				return fmt.Errorf("failed to unmarshal webhook delivery: %w", err)
+			}
+			deliveries = append(deliveries, delivery)
+		}
+		return nil
+	})
+
+	return deliveries, err
+}
+
+func eventKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
+
+func (s *BoltStore) SaveNetwork(network models.Network) error {
+	return s.update("SaveNetwork", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("networks"))
+
+		data, err := json.Marshal(network)
+		if err != nil {
+			return fmt.Errorf("failed to marshal network: %w", err)
+		}
+
+		return b.Put([]byte(network.Name), data)
+	})
+}
+
+func (s *BoltStore) GetNetwork(name string) (models.Network, error) {
+	var network models.Network
+
+	err := s.view("GetNetwork", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("networks"))
+		data := b.Get([]byte(name))
+
+		if data == nil {
+			return fmt.Errorf("network %w: %s", ErrNotFound, name)
+		}
+
+		return json.Unmarshal(data, &network)
+	})
+
+	return network, err
+}
+
+func (s *BoltStore) ListNetworks() ([]models.Network, error) {
+	var networks []models.Network
+
+	err := s.view("ListNetworks", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("networks"))
+
+		return b.ForEach(func(k, v []byte) error {
+			var network models.Network
+			if err := json.Unmarshal(v, &network); err != nil {
+				return fmt.Errorf("failed to unmarshal network: %w", err)
+			}
+
+			networks = append(networks, network)
+			return nil
+		})
+	})
+
+	return networks, err
+}
+
+func (s *BoltStore) DeleteNetwork(name string) error {
+	return s.update("DeleteNetwork", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("networks"))
+		return b.Delete([]byte(name))
+	})
+}
+
+func (s *BoltStore) SaveVolume(volume models.Volume) error {
+	return s.update("SaveVolume", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("volumes"))
+
+		data, err := json.Marshal(volume)
+		if err != nil {
+			return fmt.Errorf("failed to marshal volume: %w", err)
+		}
+
+		return b.Put([]byte(volume.Name), data)
+	})
+}
+
+func (s *BoltStore) GetVolume(name string) (models.Volume, error) {
+	var volume models.Volume
+
+	err := s.view("GetVolume", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("volumes"))
+		data := b.Get([]byte(name))
+
+		if data == nil {
+			return fmt.Errorf("volume %w: %s", ErrNotFound, name)
+		}
+
+		return json.Unmarshal(data, &volume)
+	})
+
+	return volume, err
+}
+
+func (s *BoltStore) ListVolumes() ([]models.Volume, error) {
+	var volumes []models.Volume
+
+	err := s.view("ListVolumes", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("volumes"))
+
+		return b.ForEach(func(k, v []byte) error {
+			var volume models.Volume
+			if err := json.Unmarshal(v, &volume); err != nil {
+				return fmt.Errorf("failed to unmarshal volume: %w", err)
+			}
+
+			volumes = append(volumes, volume)
+			return nil
+		})
+	})
+
+	return volumes, err
+}
+
+func (s *BoltStore) DeleteVolume(name string) error {
+	return s.update("DeleteVolume", func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("volumes"))
+		return b.Delete([]byte(name))
+	})
+}
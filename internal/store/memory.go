@@ -0,0 +1,542 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"podium/internal/metrics"
+	"podium/internal/models"
+)
+
+// memorySnapshotFormat marks the snapshots written by MemoryStore.Backup.
+const memorySnapshotFormat = "podium-memory-v1"
+
+// MemoryStore keeps all state in memory, so the API and workers can run
+// without a database file, as in tests. Records are kept JSON-encoded, as in
+// BoltStore, so callers never share them with the store.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	buckets map[string]*memoryBucket
+}
+
+// memoryBucket mirrors a bolt bucket: records by key, nested buckets and a
+// sequence. Keys of records numbered by a sequence are zero-padded, so they
+// sort in numeric order.
+type memoryBucket struct {
+	Sequence uint64                     `json:"sequence"`
+	Records  map[string]json.RawMessage `json:"records"`
+	Buckets  map[string]*memoryBucket   `json:"buckets,omitempty"`
+}
+
+type memorySnapshot struct {
+	Format  string                   `json:"format"`
+	Buckets map[string]*memoryBucket `json:"buckets"`
+}
+
+func newMemoryBucket() *memoryBucket {
+	return &memoryBucket{Records: make(map[string]json.RawMessage)}
+}
+
+// nested returns the nested bucket name, creating it if create is set.
+func (b *memoryBucket) nested(name string, create bool) *memoryBucket {
+	if nested := b.Buckets[name]; nested != nil || !create {
+		return nested
+	}
+	if b.Buckets == nil {
+		b.Buckets = make(map[string]*memoryBucket)
+	}
+	b.Buckets[name] = newMemoryBucket()
+	return b.Buckets[name]
+}
+
+func (b *memoryBucket) put(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	b.Records[key] = data
+	return nil
+}
+
+// keys returns the record keys in order.
+func (b *memoryBucket) keys() []string {
+	keys := make([]string, 0, len(b.Records))
+	for k := range b.Records {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sequenceKey(n uint64) string {
+	return fmt.Sprintf("%020d", n)
+}
+
+func NewMemoryStore() *MemoryStore {
+	buckets := make(map[string]*memoryBucket)
+	for _, name := range []string{"containers", "services", "registries", "events", "service_revisions",
+		"webhooks", "webhook_deliveries", "networks", "volumes"} {
+		buckets[name] = newMemoryBucket()
+	}
+	return &MemoryStore{buckets: buckets}
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// view runs fn under the read lock, recording its duration under operation.
+func (s *MemoryStore) view(operation string, fn func() error) error {
+	defer metrics.ObserveStoreOperation(operation, time.Now())
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fn()
+}
+
+// update runs fn under the write lock, recording its duration under
+// operation.
+func (s *MemoryStore) update(operation string, fn func() error) error {
+	defer metrics.ObserveStoreOperation(operation, time.Now())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn()
+}
+
+// get decodes the record key of bucket into v, failing with a not found
+// error naming kind if there is none.
+func (s *MemoryStore) get(operation, bucket, kind, key string, v interface{}) error {
+	return s.view(operation, func() error {
+		data, ok := s.buckets[bucket].Records[key]
+		if !ok {
+			return fmt.Errorf("%s %w: %s", kind, ErrNotFound, key)
+		}
+		return json.Unmarshal(data, v)
+	})
+}
+
+func (s *MemoryStore) put(operation, bucket, key string, value interface{}) error {
+	return s.update(operation, func() error {
+		return s.buckets[bucket].put(key, value)
+	})
+}
+
+func (s *MemoryStore) delete(operation, bucket, key string) error {
+	return s.update(operation, func() error {
+		delete(s.buckets[bucket].Records, key)
+		return nil
+	})
+}
+
+// forEach decodes the records of b in key order, calling fn with each.
+func forEach(b *memoryBucket, newRecord func() interface{}, fn func(record interface{})) error {
+	if b == nil {
+		return nil
+	}
+	for _, k := range b.keys() {
+		record := newRecord()
+		if err := json.Unmarshal(b.Records[k], record); err != nil {
+			return fmt.Errorf("failed to unmarshal record %s: %w", k, err)
+		}
+		fn(record)
+	}
+	return nil
+}
+
+func (s *MemoryStore) CreateContainer(container models.Container) error {
+	return s.put("CreateContainer", "containers", container.ID, container)
+}
+
+func (s *MemoryStore) GetContainer(id string) (models.Container, error) {
+	var container models.Container
+	err := s.get("GetContainer", "containers", "container", id, &container)
+	return container, err
+}
+
+func (s *MemoryStore) ListContainers() ([]models.Container, error) {
+	return s.listContainers("ListContainers", func(models.Container) bool { return true })
+}
+
+func (s *MemoryStore) GetContainersByStatus(status string) ([]models.Container, error) {
+	return s.listContainers("GetContainersByStatus", func(container models.Container) bool {
+		return string(container.State) == status
+	})
+}
+
+func (s *MemoryStore) listContainers(operation string, match func(models.Container) bool) ([]models.Container, error) {
+	var containers []models.Container
+	err := s.view(operation, func() error {
+		return forEach(s.buckets["containers"], func() interface{} { return &models.Container{} }, func(record interface{}) {
+			if container := *record.(*models.Container); match(container) {
+				containers = append(containers, container)
+			}
+		})
+	})
+	return containers, err
+}
+
+func (s *MemoryStore) UpdateContainer(container models.Container) error {
+	return s.put("UpdateContainer", "containers", container.ID, container)
+}
+
+func (s *MemoryStore) DeleteContainer(id string) error {
+	return s.delete("DeleteContainer", "containers", id)
+}
+
+func (s *MemoryStore) CreateService(service models.Service) error {
+	return s.put("CreateService", "services", service.ID, service)
+}
+
+func (s *MemoryStore) GetService(id string) (models.Service, error) {
+	var service models.Service
+	err := s.get("GetService", "services", "service", id, &service)
+	return service, err
+}
+
+func (s *MemoryStore) GetServiceByName(name string) (models.Service, error) {
+	var service models.Service
+	found := false
+	err := s.view("GetServiceByName", func() error {
+		return forEach(s.buckets["services"], func() interface{} { return &models.Service{} }, func(record interface{}) {
+			if candidate := *record.(*models.Service); !found && candidate.Name == name {
+				service, found = candidate, true
+			}
+		})
+	})
+	if err == nil && !found {
+		err = fmt.Errorf("service %w: %s", ErrNotFound, name)
+	}
+	return service, err
+}
+
+func (s *MemoryStore) ListServices() ([]models.Service, error) {
+	var services []models.Service
+	err := s.view("ListServices", func() error {
+		return forEach(s.buckets["services"], func() interface{} { return &models.Service{} }, func(record interface{}) {
+			services = append(services, *record.(*models.Service))
+		})
+	})
+	return services, err
+}
+
+func (s *MemoryStore) UpdateService(service models.Service) error {
+	return s.put("UpdateService", "services", service.ID, service)
+}
+
+func (s *MemoryStore) DeleteService(id string) error {
+	return s.update("DeleteService", func() error {
+		delete(s.buckets["service_revisions"].Buckets, id)
+		delete(s.buckets["services"].Records, id)
+		return nil
+	})
+}
+
+func (s *MemoryStore) SaveServiceRevision(revision *models.ServiceRevision) error {
+	return s.update("SaveServiceRevision", func() error {
+		b := s.buckets["service_revisions"].nested(revision.ServiceID, true)
+		b.Sequence++
+		revision.Revision = int(b.Sequence)
+		return b.put(sequenceKey(b.Sequence), revision)
+	})
+}
+
+func (s *MemoryStore) GetServiceRevision(serviceID string, revision int) (models.ServiceRevision, error) {
+	var result models.ServiceRevision
+	err := s.view("GetServiceRevision", func() error {
+		var data json.RawMessage
+		if b := s.buckets["service_revisions"].nested(serviceID, false); b != nil && revision > 0 {
+			data = b.Records[sequenceKey(uint64(revision))]
+		}
+		if data == nil {
+			return fmt.Errorf("revision %d of service %s %w", revision, serviceID, ErrNotFound)
+		}
+		return json.Unmarshal(data, &result)
+	})
+	return result, err
+}
+
+func (s *MemoryStore) ListServiceRevisions(serviceID string) ([]models.ServiceRevision, error) {
+	var revisions []models.ServiceRevision
+	err := s.view("ListServiceRevisions", func() error {
+		b := s.buckets["service_revisions"].nested(serviceID, false)
+		return forEach(b, func() interface{} { return &models.ServiceRevision{} }, func(record interface{}) {
+			revisions = append(revisions, *record.(*models.ServiceRevision))
+		})
+	})
+	return revisions, err
+}
+
+func (s *MemoryStore) SaveRegistryCredential(credential models.RegistryCredential) error {
+	return s.put("SaveRegistryCredential", "registries", credential.Registry, credential)
+}
+
+func (s *MemoryStore) GetRegistryCredential(registry string) (models.RegistryCredential, error) {
+	var credential models.RegistryCredential
+	err := s.get("GetRegistryCredential", "registries", "registry credential", registry, &credential)
+	return credential, err
+}
+
+func (s *MemoryStore) ListRegistryCredentials() ([]models.RegistryCredential, error) {
+	var credentials []models.RegistryCredential
+	err := s.view("ListRegistryCredentials", func() error {
+		return forEach(s.buckets["registries"], func() interface{} { return &models.RegistryCredential{} }, func(record interface{}) {
+			credentials = append(credentials, *record.(*models.RegistryCredential))
+		})
+	})
+	return credentials, err
+}
+
+func (s *MemoryStore) DeleteRegistryCredential(registry string) error {
+	return s.delete("DeleteRegistryCredential", "registries", registry)
+}
+
+func (s *MemoryStore) SaveEvent(event *models.Event) error {
+	return s.update("SaveEvent", func() error {
+		b := s.buckets["events"]
+		b.Sequence++
+		event.ID = b.Sequence
+		return b.put(sequenceKey(event.ID), event)
+	})
+}
+
+func (s *MemoryStore) ListEvents(filter models.EventFilter) ([]models.Event, error) {
+	var events []models.Event
+	err := s.view("ListEvents", func() error {
+		return forEach(s.buckets["events"], func() interface{} { return &models.Event{} }, func(record interface{}) {
+			event := *record.(*models.Event)
+			if !filter.Matches(event) {
+				return
+			}
+			events = append(events, event)
+			if filter.Limit > 0 && len(events) > filter.Limit {
+				events = events[1:]
+			}
+		})
+	})
+	return events, err
+}
+
+func (s *MemoryStore) PruneEvents(keep int) error {
+	return s.update("PruneEvents", func() error {
+		b := s.buckets["events"]
+		if b.Sequence <= uint64(keep) {
+			return nil
+		}
+		oldest := sequenceKey(b.Sequence - uint64(keep) + 1)
+		for k := range b.Records {
+			if k < oldest {
+				delete(b.Records, k)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *MemoryStore) SaveWebhook(webhook models.Webhook) error {
+	return s.put("SaveWebhook", "webhooks", webhook.ID, webhook)
+}
+
+func (s *MemoryStore) GetWebhook(id string) (models.Webhook, error) {
+	var webhook models.Webhook
+	err := s.get("GetWebhook", "webhooks", "webhook", id, &webhook)
+	return webhook, err
+}
+
+func (s *MemoryStore) ListWebhooks() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := s.view("ListWebhooks", func() error {
+		return forEach(s.buckets["webhooks"], func() interface{} { return &models.Webhook{} }, func(record interface{}) {
+			webhooks = append(webhooks, *record.(*models.Webhook))
+		})
+	})
+	return webhooks, err
+}
+
+func (s *MemoryStore) DeleteWebhook(id string) error {
+	return s.update("DeleteWebhook", func() error {
+		delete(s.buckets["webhooks"].Records, id)
+		delete(s.buckets["webhook_deliveries"].Buckets, id)
+		return nil
+	})
+}
+
+func (s *MemoryStore) SaveWebhookDelivery(delivery *models.WebhookDelivery, keep int) error {
+	return s.update("SaveWebhookDelivery", func() error {
+		if _, ok := s.buckets["webhooks"].Records[delivery.WebhookID]; !ok {
+			return fmt.Errorf("webhook %w: %s", ErrNotFound, delivery.WebhookID)
+		}
+
+		deliveries := s.buckets["webhook_deliveries"]
+		b := deliveries.nested(delivery.WebhookID, true)
+		isNew := delivery.ID == 0
+		if isNew {
+			deliveries.Sequence++
+			delivery.ID = deliveries.Sequence
+		}
+		if err := b.put(sequenceKey(delivery.ID), delivery); err != nil {
+			return fmt.Errorf("failed to marshal webhook delivery: %w", err)
+		}
+
+		if !isNew || keep <= 0 {
+			return nil
+		}
+		keys := b.keys()
+		for len(keys) > keep {
+			delete(b.Records, keys[0])
+			keys = keys[1:]
+		}
+		return nil
+	})
+}
+
+func (s *MemoryStore) ListWebhookDeliveries(webhookID string) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := s.view("ListWebhookDeliveries", func() error {
+		b := s.buckets["webhook_deliveries"].nested(webhookID, false)
+		return forEach(b, func() interface{} { return &models.WebhookDelivery{} }, func(record interface{}) {
+			deliveries = append(deliveries, *record.(*models.WebhookDelivery))
+		})
+	})
+	// Most recent first.
+	for i, j := 0, len(deliveries)-1; i < j; i, j = i+1, j-1 {
+		deliveries[i], deliveries[j] = deliveries[j], deliveries[i]
+	}
+	return deliveries, err
+}
+
+func (s *MemoryStore) SaveNetwork(network models.Network) error {
+	return s.put("SaveNetwork", "networks", network.Name, network)
+}
+
+func (s *MemoryStore) GetNetwork(name string) (models.Network, error) {
+	var network models.Network
+	err := s.get("GetNetwork", "networks", "network", name, &network)
+	return network, err
+}
+
+func (s *MemoryStore) ListNetworks() ([]models.Network, error) {
+	var networks []models.Network
+	err := s.view("ListNetworks", func() error {
+		return forEach(s.buckets["networks"], func() interface{} { return &models.Network{} }, func(record interface{}) {
+			networks = append(networks, *record.(*models.Network))
+		})
+	})
+	return networks, err
+}
+
+func (s *MemoryStore) DeleteNetwork(name string) error {
+	return s.delete("DeleteNetwork", "networks", name)
+}
+
+func (s *MemoryStore) SaveVolume(volume models.Volume) error {
+	return s.put("SaveVolume", "volumes", volume.Name, volume)
+}
+
+func (s *MemoryStore) GetVolume(name string) (models.Volume, error) {
+	var volume models.Volume
+	err := s.get("GetVolume", "volumes", "volume", name, &volume)
+	return volume, err
+}
+
+func (s *MemoryStore) ListVolumes() ([]models.Volume, error) {
+	var volumes []models.Volume
+	err := s.view("ListVolumes", func() error {
+		return forEach(s.buckets["volumes"], func() interface{} { return &models.Volume{} }, func(record interface{}) {
+			volumes = append(volumes, *record.(*models.Volume))
+		})
+	})
+	return volumes, err
+}
+
+func (s *MemoryStore) DeleteVolume(name string) error {
+	return s.delete("DeleteVolume", "volumes", name)
+}
+
+// Backup writes the whole store to w as a JSON document.
+func (s *MemoryStore) Backup(w io.Writer) (int64, error) {
+	var data []byte
+	err := s.view("Backup", func() error {
+		var err error
+		data, err = json.Marshal(memorySnapshot{Format: memorySnapshotFormat, Buckets: s.buckets})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// ValidateSnapshot checks that the file at path was written by
+// MemoryStore.Backup and that the records in it can be read.
+func (s *MemoryStore) ValidateSnapshot(path string) error {
+	_, err := readMemorySnapshot(path)
+	return err
+}
+
+// Restore replaces the store with the snapshot at path.
+func (s *MemoryStore) Restore(path string) error {
+	snapshot, err := readMemorySnapshot(path)
+	if err != nil {
+		return err
+	}
+	return s.update("Restore", func() error {
+		s.buckets = snapshot.Buckets
+		return nil
+	})
+}
+
+func readMemorySnapshot(path string) (memorySnapshot, error) {
+	var snapshot memorySnapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if snapshot.Format != memorySnapshotFormat {
+		return snapshot, fmt.Errorf("%w: not a memory store snapshot", ErrInvalidSnapshot)
+	}
+
+	records := map[string]func() interface{}{
+		"containers":         func() interface{} { return &models.Container{} },
+		"services":           func() interface{} { return &models.Service{} },
+		"registries":         func() interface{} { return &models.RegistryCredential{} },
+		"events":             func() interface{} { return &models.Event{} },
+		"service_revisions":  func() interface{} { return &models.ServiceRevision{} },
+		"webhooks":           func() interface{} { return &models.Webhook{} },
+		"webhook_deliveries": func() interface{} { return &models.WebhookDelivery{} },
+		"networks":           func() interface{} { return &models.Network{} },
+		"volumes":            func() interface{} { return &models.Volume{} },
+	}
+	for name, record := range records {
+		b := snapshot.Buckets[name]
+		if b == nil {
+			return snapshot, fmt.Errorf("%w: %s bucket is missing", ErrInvalidSnapshot, name)
+		}
+		if b.Records == nil {
+			b.Records = make(map[string]json.RawMessage)
+		}
+		buckets := []*memoryBucket{b}
+		for key, nested := range b.Buckets {
+			if nested == nil {
+				return snapshot, fmt.Errorf("%w: %s bucket %s is empty", ErrInvalidSnapshot, name, key)
+			}
+			if nested.Records == nil {
+				nested.Records = make(map[string]json.RawMessage)
+			}
+			buckets = append(buckets, nested)
+		}
+		for _, bucket := range buckets {
+			if err := forEach(bucket, record, func(interface{}) {}); err != nil {
+				return snapshot, fmt.Errorf("%w: %s: %v", ErrInvalidSnapshot, name, err)
+			}
+		}
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"podium/internal/models"
+)
+
+// QueryContainers scans every container, since bolt has no indexes.
+func (s *BoltStore) QueryContainers(filter models.ContainerFilter, options models.ListOptions) ([]models.Container, int, error) {
+	containers, err := s.ListContainers()
+	if err != nil {
+		return nil, 0, err
+	}
+	page, total := queryContainers(containers, filter, options)
+	return page, total, nil
+}
+
+// QueryServices scans every service, since bolt has no indexes.
+func (s *BoltStore) QueryServices(filter models.ServiceFilter, options models.ListOptions) ([]models.Service, int, error) {
+	services, err := s.ListServices()
+	if err != nil {
+		return nil, 0, err
+	}
+	page, total := queryServices(services, filter, options)
+	return page, total, nil
+}
+
+func (s *MemoryStore) QueryContainers(filter models.ContainerFilter, options models.ListOptions) ([]models.Container, int, error) {
+	containers, err := s.ListContainers()
+	if err != nil {
+		return nil, 0, err
+	}
+	page, total := queryContainers(containers, filter, options)
+	return page, total, nil
+}
+
+func (s *MemoryStore) QueryServices(filter models.ServiceFilter, options models.ListOptions) ([]models.Service, int, error) {
+	services, err := s.ListServices()
+	if err != nil {
+		return nil, 0, err
+	}
+	page, total := queryServices(services, filter, options)
+	return page, total, nil
+}
+
+// queryContainers filters, sorts and pages containers in memory, for the
+// stores that cannot query them. It returns the page along with how many
+// containers matched.
+func queryContainers(containers []models.Container, filter models.ContainerFilter, options models.ListOptions) ([]models.Container, int) {
+	var matched []models.Container
+	for _, container := range containers {
+		if filter.Matches(container) {
+			matched = append(matched, container)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		var c int
+		switch options.Sort {
+		case "", "id":
+			c = strings.Compare(a.ID, b.ID)
+		case "name":
+			c = strings.Compare(a.Name, b.Name)
+		case "state":
+			c = strings.Compare(string(a.State), string(b.State))
+		case "createdAt":
+			c = compareTimes(a.CreatedAt, b.CreatedAt)
+		}
+		return less(c, a.ID, b.ID, options.Descending)
+	})
+
+	start, end := pageBounds(len(matched), options)
+	return matched[start:end], len(matched)
+}
+
+// queryServices filters, sorts and pages services in memory, as
+// queryContainers does containers.
+func queryServices(services []models.Service, filter models.ServiceFilter, options models.ListOptions) ([]models.Service, int) {
+	var matched []models.Service
+	for _, service := range services {
+		if filter.Matches(service) {
+			matched = append(matched, service)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		var c int
+		switch options.Sort {
+		case "", "id":
+			c = strings.Compare(a.ID, b.ID)
+		case "name":
+			c = strings.Compare(a.Name, b.Name)
+		case "state":
+			c = strings.Compare(string(a.State), string(b.State))
+		case "createdAt":
+			c = compareTimes(a.CreatedAt, b.CreatedAt)
+		case "updatedAt":
+			c = compareTimes(a.UpdatedAt, b.UpdatedAt)
+		}
+		return less(c, a.ID, b.ID, options.Descending)
+	})
+
+	start, end := pageBounds(len(matched), options)
+	return matched[start:end], len(matched)
+}
+
+// less orders two records by the comparison c of their sort field, then by
+// ID. Only the sort field is reversed when descending, as in the SQL stores.
+func less(c int, idA, idB string, descending bool) bool {
+	if c == 0 {
+		return idA < idB
+	}
+	if descending {
+		return c > 0
+	}
+	return c < 0
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+func pageBounds(total int, options models.ListOptions) (int, int) {
+	start := options.Offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if options.Limit > 0 && start+options.Limit < total {
+		end = start + options.Limit
+	}
+	return start, end
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"podium/internal/models"
+)
+
+// ErrInvalidSnapshot is returned when restoring from a file that is not a
+// sound snapshot of a Podium store.
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// snapshotBuckets are the buckets every snapshot has, since NewBoltStore
+// creates them.
+var snapshotBuckets = []string{"containers", "registries", "events", "service_revisions", "webhooks", "webhook_deliveries"}
+
+// Backup writes a consistent copy of the database to w from a read-only
+// transaction, so the store stays usable while it is written.
+func (s *BoltStore) Backup(w io.Writer) (int64, error) {
+	var n int64
+	err := s.view("Backup", func(tx *bolt.Tx) error {
+		var err error
+		n, err = tx.WriteTo(w)
+		return err
+	})
+	return n, err
+}
+
+// ValidateSnapshot checks that the file at path is a snapshot of a bolt
+// store, as ValidateSnapshot does.
+func (s *BoltStore) ValidateSnapshot(path string) error {
+	return ValidateSnapshot(path)
+}
+
+// ValidateSnapshot checks that the file at path is an intact bolt database
+// with the buckets of a Podium store, and that the records in them can be
+// read.
+func ValidateSnapshot(path string) error {
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		for err := range tx.Check() {
+			return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		for _, name := range snapshotBuckets {
+			if tx.Bucket([]byte(name)) == nil {
+				return fmt.Errorf("%w: %s bucket is missing", ErrInvalidSnapshot, name)
+			}
+		}
+
+		checks := map[string]func() interface{}{
+			"containers": func() interface{} { return &models.Container{} },
+			"services":   func() interface{} { return &models.Service{} },
+			"registries": func() interface{} { return &models.RegistryCredential{} },
+			"webhooks":   func() interface{} { return &models.Webhook{} },
+			"networks":   func() interface{} { return &models.Network{} },
+			"volumes":    func() interface{} { return &models.Volume{} },
+		}
+		for name, record := range checks {
+			b := tx.Bucket([]byte(name))
+			if b == nil {
+				continue
+			}
+			err := b.ForEach(func(k, v []byte) error {
+				if err := json.Unmarshal(v, record()); err != nil {
+					return fmt.Errorf("%w: %s record %s: %v", ErrInvalidSnapshot, name, k, err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Restore replaces everything in the store with the contents of the
+// snapshot at path, which should have been checked with ValidateSnapshot.
+// The snapshot is copied in a single transaction, so readers see either the
+// old state or the restored one.
+func (s *BoltStore) Restore(path string) error {
+	snapshot, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	defer snapshot.Close()
+
+	return snapshot.View(func(src *bolt.Tx) error {
+		return s.update("Restore", func(dst *bolt.Tx) error {
+			var names [][]byte
+			if err := dst.ForEach(func(name []byte, _ *bolt.Bucket) error {
+				names = append(names, append([]byte(nil), name...))
+				return nil
+			}); err != nil {
+				return err
+			}
+			for _, name := range names {
+				if err := dst.DeleteBucket(name); err != nil {
+					return fmt.Errorf("failed to clear %s bucket: %w", name, err)
+				}
+			}
+
+			return src.ForEach(func(name []byte, b *bolt.Bucket) error {
+				copied, err := dst.CreateBucket(name)
+				if err != nil {
+					return fmt.Errorf("failed to restore %s bucket: %w", name, err)
+				}
+				return copyBucket(b, copied)
+			})
+		})
+	})
+}
+
+// copyBucket copies the keys, nested buckets and sequence of src into dst.
+func copyBucket(src, dst *bolt.Bucket) error {
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+	return src.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return dst.Put(k, v)
+		}
+		nested, err := dst.CreateBucket(k)
+		if err != nil {
+			return err
+		}
+		return copyBucket(src.Bucket(k), nested)
+	})
+}
@@ -0,0 +1,810 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+	"podium/internal/metrics"
+	"podium/internal/models"
+)
+
+// sqliteSchema creates the tables of the store. Each record is kept as JSON
+// in its data column, with the fields lists are filtered and sorted by
+// copied into indexed columns.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS containers (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	state TEXT NOT NULL,
+	service_id TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	data BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS containers_name ON containers (name);
+CREATE INDEX IF NOT EXISTS containers_state ON containers (state);
+CREATE INDEX IF NOT EXISTS containers_service_id ON containers (service_id);
+CREATE INDEX IF NOT EXISTS containers_created_at ON containers (created_at);
+
+CREATE TABLE IF NOT EXISTS container_labels (
+	container_id TEXT NOT NULL,
+	key TEXT NOT NULL,
+	value TEXT NOT NULL,
+	PRIMARY KEY (container_id, key)
+);
+CREATE INDEX IF NOT EXISTS container_labels_key_value ON container_labels (key, value);
+
+CREATE TABLE IF NOT EXISTS services (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	state TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL,
+	data BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS services_name ON services (name);
+CREATE INDEX IF NOT EXISTS services_state ON services (state);
+CREATE INDEX IF NOT EXISTS services_created_at ON services (created_at);
+CREATE INDEX IF NOT EXISTS services_updated_at ON services (updated_at);
+
+CREATE TABLE IF NOT EXISTS service_revisions (
+	service_id TEXT NOT NULL,
+	revision INTEGER NOT NULL,
+	data BLOB NOT NULL,
+	PRIMARY KEY (service_id, revision)
+);
+
+CREATE TABLE IF NOT EXISTS registries (
+	registry TEXT PRIMARY KEY,
+	data BLOB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	type TEXT NOT NULL,
+	container_id TEXT NOT NULL,
+	service_id TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	data BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS events_container_id ON events (container_id);
+CREATE INDEX IF NOT EXISTS events_service_id ON events (service_id);
+CREATE INDEX IF NOT EXISTS events_timestamp ON events (timestamp);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+	id TEXT PRIMARY KEY,
+	data BLOB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	webhook_id TEXT NOT NULL,
+	data BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id);
+
+CREATE TABLE IF NOT EXISTS networks (
+	name TEXT PRIMARY KEY,
+	data BLOB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS volumes (
+	name TEXT PRIMARY KEY,
+	data BLOB NOT NULL
+);
+`
+
+// sqliteTables are the tables of the store, with their columns.
+var sqliteTables = []struct {
+	name    string
+	columns string
+}{
+	{"containers", "id, name, state, service_id, created_at, data"},
+	{"container_labels", "container_id, key, value"},
+	{"services", "id, name, state, created_at, updated_at, data"},
+	{"service_revisions", "service_id, revision, data"},
+	{"registries", "registry, data"},
+	{"events", "id, type, container_id, service_id, timestamp, data"},
+	{"webhooks", "id, data"},
+	{"webhook_deliveries", "id, webhook_id, data"},
+	{"networks", "name, data"},
+	{"volumes", "name, data"},
+}
+
+// sqliteSortColumns map the fields lists can be sorted by to their columns.
+var sqliteSortColumns = map[string]string{
+	"id":        "id",
+	"name":      "name",
+	"state":     "state",
+	"createdAt": "created_at",
+	"updatedAt": "updated_at",
+}
+
+// sqliteTimeFormat writes times so that they sort as text.
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+// SQLiteStore keeps the state of Podium in a SQLite database, so that lists
+// can be filtered, sorted and paged by indexed queries.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite db: %w", err)
+	}
+	// A single connection serialises transactions, as bolt does, and keeps
+	// attached databases on the connection that uses them.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create tables: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// observe records the duration of operation, as BoltStore does.
+func observe(operation string) func() {
+	start := time.Now()
+	return func() { metrics.ObserveStoreOperation(operation, start) }
+}
+
+// inTx runs fn in a transaction, recording its duration under operation.
+func (s *SQLiteStore) inTx(operation string, fn func(tx *sql.Tx) error) error {
+	defer observe(operation)()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// get decodes the data of the single row query selects into v, failing with
+// a not found error naming kind and key if there is none.
+func (s *SQLiteStore) get(operation, kind, key string, v interface{}, query string, args ...interface{}) error {
+	defer observe(operation)()
+
+	var data []byte
+	err := s.db.QueryRow(query, args...).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s %w: %s", kind, ErrNotFound, key)
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// list calls fn with the data of each row query returns.
+func (s *SQLiteStore) list(operation string, fn func(data []byte) error, query string, args ...interface{}) error {
+	defer observe(operation)()
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *SQLiteStore) exec(operation, query string, args ...interface{}) error {
+	defer observe(operation)()
+
+	_, err := s.db.Exec(query, args...)
+	return err
+}
+
+func (s *SQLiteStore) CreateContainer(container models.Container) error {
+	return s.putContainer("CreateContainer", container)
+}
+
+func (s *SQLiteStore) UpdateContainer(container models.Container) error {
+	return s.putContainer("UpdateContainer", container)
+}
+
+func (s *SQLiteStore) putContainer(operation string, container models.Container) error {
+	data, err := json.Marshal(container)
+	if err != nil {
+		return fmt.Errorf("failed to marshal container: %w", err)
+	}
+
+	return s.inTx(operation, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO containers (id, name, state, service_id, created_at, data) VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET name = excluded.name, state = excluded.state,
+				service_id = excluded.service_id, created_at = excluded.created_at, data = excluded.data`,
+			container.ID, container.Name, string(container.State), container.Labels["podium.service.id"],
+			sqliteTime(container.CreatedAt), data)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`DELETE FROM container_labels WHERE container_id = ?`, container.ID); err != nil {
+			return err
+		}
+		for key, value := range container.Labels {
+			if _, err := tx.Exec(`INSERT INTO container_labels (container_id, key, value) VALUES (?, ?, ?)`,
+				container.ID, key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLiteStore) GetContainer(id string) (models.Container, error) {
+	var container models.Container
+	err := s.get("GetContainer", "container", id, &container, `SELECT data FROM containers WHERE id = ?`, id)
+	return container, err
+}
+
+func (s *SQLiteStore) ListContainers() ([]models.Container, error) {
+	var containers []models.Container
+	err := s.list("ListContainers", func(data []byte) error {
+		var container models.Container
+		if err := json.Unmarshal(data, &container); err != nil {
+			return fmt.Errorf("failed to unmarshal container: %w", err)
+		}
+		containers = append(containers, container)
+		return nil
+	}, `SELECT data FROM containers ORDER BY id`)
+	return containers, err
+}
+
+func (s *SQLiteStore) GetContainersByStatus(status string) ([]models.Container, error) {
+	var containers []models.Container
+	err := s.list("GetContainersByStatus", func(data []byte) error {
+		var container models.Container
+		if err := json.Unmarshal(data, &container); err != nil {
+			return fmt.Errorf("failed to unmarshal container: %w", err)
+		}
+		containers = append(containers, container)
+		return nil
+	}, `SELECT data FROM containers WHERE state = ? ORDER BY id`, status)
+	return containers, err
+}
+
+func (s *SQLiteStore) QueryContainers(filter models.ContainerFilter, options models.ListOptions) ([]models.Container, int, error) {
+	var where []string
+	var args []interface{}
+	if filter.State != "" {
+		where = append(where, "state = ?")
+		args = append(args, string(filter.State))
+	}
+	if filter.Name != "" {
+		where = append(where, "name = ?")
+		args = append(args, filter.Name)
+	}
+	if filter.ServiceID != "" {
+		where = append(where, "service_id = ?")
+		args = append(args, filter.ServiceID)
+	}
+	for key, value := range filter.Labels {
+		where = append(where, "id IN (SELECT container_id FROM container_labels WHERE key = ? AND value = ?)")
+		args = append(args, key, value)
+	}
+
+	var containers []models.Container
+	total, err := s.query("QueryContainers", "containers", where, args, options, func(data []byte) error {
+		var container models.Container
+		if err := json.Unmarshal(data, &container); err != nil {
+			return fmt.Errorf("failed to unmarshal container: %w", err)
+		}
+		containers = append(containers, container)
+		return nil
+	})
+	return containers, total, err
+}
+
+// query counts the rows of table matching the where conditions, then calls
+// fn with the data of each row on the page options select.
+func (s *SQLiteStore) query(operation, table string, where []string, args []interface{}, options models.ListOptions, fn func(data []byte) error) (int, error) {
+	column, ok := sqliteSortColumns[options.Sort]
+	if options.Sort == "" {
+		column, ok = "id", true
+	}
+	if !ok {
+		return 0, fmt.Errorf("cannot sort by %s", options.Sort)
+	}
+	order := column
+	if options.Descending {
+		order += " DESC"
+	}
+	if column != "id" {
+		order += ", id"
+	}
+
+	conditions := ""
+	if len(where) > 0 {
+		conditions = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	err := s.inTx(operation, func(tx *sql.Tx) error {
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM `+table+conditions, args...).Scan(&total); err != nil {
+			return err
+		}
+
+		limit := -1
+		if options.Limit > 0 {
+			limit = options.Limit
+		}
+		rows, err := tx.Query(`SELECT data FROM `+table+conditions+` ORDER BY `+order+` LIMIT ? OFFSET ?`,
+			append(args, limit, options.Offset)...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var data []byte
+			if err := rows.Scan(&data); err != nil {
+				return err
+			}
+			if err := fn(data); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+	return total, err
+}
+
+func (s *SQLiteStore) DeleteContainer(id string) error {
+	return s.inTx("DeleteContainer", func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM container_labels WHERE container_id = ?`, id); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`DELETE FROM containers WHERE id = ?`, id)
+		return err
+	})
+}
+
+func (s *SQLiteStore) CreateService(service models.Service) error {
+	return s.putService("CreateService", service)
+}
+
+func (s *SQLiteStore) UpdateService(service models.Service) error {
+	return s.putService("UpdateService", service)
+}
+
+func (s *SQLiteStore) putService(operation string, service models.Service) error {
+	data, err := json.Marshal(service)
+	if err != nil {
+		return fmt.Errorf("failed to marshal service: %w", err)
+	}
+
+	return s.exec(operation, `INSERT INTO services (id, name, state, created_at, updated_at, data) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET name = excluded.name, state = excluded.state,
+			created_at = excluded.created_at, updated_at = excluded.updated_at, data = excluded.data`,
+		service.ID, service.Name, string(service.State), sqliteTime(service.CreatedAt), sqliteTime(service.UpdatedAt), data)
+}
+
+func (s *SQLiteStore) GetService(id string) (models.Service, error) {
+	var service models.Service
+	err := s.get("GetService", "service", id, &service, `SELECT data FROM services WHERE id = ?`, id)
+	return service, err
+}
+
+func (s *SQLiteStore) GetServiceByName(name string) (models.Service, error) {
+	var service models.Service
+	err := s.get("GetServiceByName", "service", name, &service,
+		`SELECT data FROM services WHERE name = ? ORDER BY id LIMIT 1`, name)
+	return service, err
+}
+
+func (s *SQLiteStore) ListServices() ([]models.Service, error) {
+	var services []models.Service
+	err := s.list("ListServices", func(data []byte) error {
+		var service models.Service
+		if err := json.Unmarshal(data, &service); err != nil {
+			return fmt.Errorf("failed to unmarshal service: %w", err)
+		}
+		services = append(services, service)
+		return nil
+	}, `SELECT data FROM services ORDER BY id`)
+	return services, err
+}
+
+func (s *SQLiteStore) QueryServices(filter models.ServiceFilter, options models.ListOptions) ([]models.Service, int, error) {
+	var where []string
+	var args []interface{}
+	if filter.State != "" {
+		where = append(where, "state = ?")
+		args = append(args, string(filter.State))
+	}
+	if filter.Name != "" {
+		where = append(where, "name = ?")
+		args = append(args, filter.Name)
+	}
+
+	var services []models.Service
+	total, err := s.query("QueryServices", "services", where, args, options, func(data []byte) error {
+		var service models.Service
+		if err := json.Unmarshal(data, &service); err != nil {
+			return fmt.Errorf("failed to unmarshal service: %w", err)
+		}
+		services = append(services, service)
+		return nil
+	})
+	return services, total, err
+}
+
+func (s *SQLiteStore) DeleteService(id string) error {
+	return s.inTx("DeleteService", func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM service_revisions WHERE service_id = ?`, id); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`DELETE FROM services WHERE id = ?`, id)
+		return err
+	})
+}
+
+func (s *SQLiteStore) SaveServiceRevision(revision *models.ServiceRevision) error {
+	return s.inTx("SaveServiceRevision", func(tx *sql.Tx) error {
+		var n int
+		err := tx.QueryRow(`SELECT COALESCE(MAX(revision), 0) + 1 FROM service_revisions WHERE service_id = ?`,
+			revision.ServiceID).Scan(&n)
+		if err != nil {
+			return fmt.Errorf("failed to allocate revision number: %w", err)
+		}
+		revision.Revision = n
+
+		data, err := json.Marshal(revision)
+		if err != nil {
+			return fmt.Errorf("failed to marshal service revision: %w", err)
+		}
+		_, err = tx.Exec(`INSERT INTO service_revisions (service_id, revision, data) VALUES (?, ?, ?)`,
+			revision.ServiceID, n, data)
+		return err
+	})
+}
+
+func (s *SQLiteStore) GetServiceRevision(serviceID string, revision int) (models.ServiceRevision, error) {
+	defer observe("GetServiceRevision")()
+
+	var result models.ServiceRevision
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM service_revisions WHERE service_id = ? AND revision = ?`,
+		serviceID, revision).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return result, fmt.Errorf("revision %d of service %s %w", revision, serviceID, ErrNotFound)
+	}
+	if err != nil {
+		return result, err
+	}
+	return result, json.Unmarshal(data, &result)
+}
+
+func (s *SQLiteStore) ListServiceRevisions(serviceID string) ([]models.ServiceRevision, error) {
+	var revisions []models.ServiceRevision
+	err := s.list("ListServiceRevisions", func(data []byte) error {
+		var revision models.ServiceRevision
+		if err := json.Unmarshal(data, &revision); err != nil {
+			return fmt.Errorf("failed to unmarshal service revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+		return nil
+	}, `SELECT data FROM service_revisions WHERE service_id = ? ORDER BY revision`, serviceID)
+	return revisions, err
+}
+
+func (s *SQLiteStore) SaveRegistryCredential(credential models.RegistryCredential) error {
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return fmt.Errorf("failed to marshal registry credential: %w", err)
+	}
+	return s.exec("SaveRegistryCredential", `INSERT OR REPLACE INTO registries (registry, data) VALUES (?, ?)`,
+		credential.Registry, data)
+}
+
+func (s *SQLiteStore) GetRegistryCredential(registry string) (models.RegistryCredential, error) {
+	var credential models.RegistryCredential
+	err := s.get("GetRegistryCredential", "registry credential", registry, &credential,
+		`SELECT data FROM registries WHERE registry = ?`, registry)
+	return credential, err
+}
+
+func (s *SQLiteStore) ListRegistryCredentials() ([]models.RegistryCredential, error) {
+	var credentials []models.RegistryCredential
+	err := s.list("ListRegistryCredentials", func(data []byte) error {
+		var credential models.RegistryCredential
+		if err := json.Unmarshal(data, &credential); err != nil {
+			return fmt.Errorf("failed to unmarshal registry credential: %w", err)
+		}
+		credentials = append(credentials, credential)
+		return nil
+	}, `SELECT data FROM registries ORDER BY registry`)
+	return credentials, err
+}
+
+func (s *SQLiteStore) DeleteRegistryCredential(registry string) error {
+	return s.exec("DeleteRegistryCredential", `DELETE FROM registries WHERE registry = ?`, registry)
+}
+
+// SaveEvent assigns the next event ID to event and stores it. IDs are never
+// reused, even after the events holding them are pruned.
+func (s *SQLiteStore) SaveEvent(event *models.Event) error {
+	return s.inTx("SaveEvent", func(tx *sql.Tx) error {
+		result, err := tx.Exec(`INSERT INTO events (type, container_id, service_id, timestamp, data) VALUES (?, ?, ?, ?, '')`,
+			string(event.Type), event.ContainerID, event.ServiceID, sqliteTime(event.Timestamp))
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to allocate event ID: %w", err)
+		}
+		event.ID = uint64(id)
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		_, err = tx.Exec(`UPDATE events SET data = ? WHERE id = ?`, data, id)
+		return err
+	})
+}
+
+// ListEvents returns the events matching filter, oldest first.
+func (s *SQLiteStore) ListEvents(filter models.EventFilter) ([]models.Event, error) {
+	where := []string{"id > ?"}
+	args := []interface{}{filter.AfterID}
+	if len(filter.Types) > 0 {
+		placeholders := make([]string, len(filter.Types))
+		for i, t := range filter.Types {
+			placeholders[i] = "?"
+			args = append(args, string(t))
+		}
+		where = append(where, "type IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if filter.ContainerID != "" {
+		where = append(where, "container_id = ?")
+		args = append(args, filter.ContainerID)
+	}
+	if filter.ServiceID != "" {
+		where = append(where, "service_id = ?")
+		args = append(args, filter.ServiceID)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "timestamp >= ?")
+		args = append(args, sqliteTime(filter.Since))
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "timestamp <= ?")
+		args = append(args, sqliteTime(filter.Until))
+	}
+
+	// The most recent events are selected, then put back in order.
+	limit := -1
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	args = append(args, limit)
+
+	var events []models.Event
+	err := s.list("ListEvents", func(data []byte) error {
+		var event models.Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal event: %w", err)
+		}
+		events = append(events, event)
+		return nil
+	}, `SELECT data FROM events WHERE `+strings.Join(where, " AND ")+` ORDER BY id DESC LIMIT ?`, args...)
+
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, err
+}
+
+// PruneEvents deletes events older than the most recent keep.
+func (s *SQLiteStore) PruneEvents(keep int) error {
+	return s.inTx("PruneEvents", func(tx *sql.Tx) error {
+		var last int64
+		err := tx.QueryRow(`SELECT seq FROM sqlite_sequence WHERE name = 'events'`).Scan(&last)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if last <= int64(keep) {
+			return nil
+		}
+
+		_, err = tx.Exec(`DELETE FROM events WHERE id <= ?`, last-int64(keep))
+		return err
+	})
+}
+
+func (s *SQLiteStore) SaveWebhook(webhook models.Webhook) error {
+	data, err := json.Marshal(webhook)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook: %w", err)
+	}
+	return s.exec("SaveWebhook", `INSERT OR REPLACE INTO webhooks (id, data) VALUES (?, ?)`, webhook.ID, data)
+}
+
+func (s *SQLiteStore) GetWebhook(id string) (models.Webhook, error) {
+	var webhook models.Webhook
+	err := s.get("GetWebhook", "webhook", id, &webhook, `SELECT data FROM webhooks WHERE id = ?`, id)
+	return webhook, err
+}
+
+func (s *SQLiteStore) ListWebhooks() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := s.list("ListWebhooks", func(data []byte) error {
+		var webhook models.Webhook
+		if err := json.Unmarshal(data, &webhook); err != nil {
+			return fmt.Errorf("failed to unmarshal webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+		return nil
+	}, `SELECT data FROM webhooks ORDER BY id`)
+	return webhooks, err
+}
+
+// DeleteWebhook deletes the webhook along with its delivery log.
+func (s *SQLiteStore) DeleteWebhook(id string) error {
+	return s.inTx("DeleteWebhook", func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`DELETE FROM webhooks WHERE id = ?`, id)
+		return err
+	})
+}
+
+// SaveWebhookDelivery stores delivery in the log of its webhook, which must
+// exist, assigning it the next delivery ID if it does not have one yet. Only
+// the most recent keep deliveries of each webhook are retained.
+func (s *SQLiteStore) SaveWebhookDelivery(delivery *models.WebhookDelivery, keep int) error {
+	return s.inTx("SaveWebhookDelivery", func(tx *sql.Tx) error {
+		var exists int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM webhooks WHERE id = ?`, delivery.WebhookID).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists == 0 {
+			return fmt.Errorf("webhook %w: %s", ErrNotFound, delivery.WebhookID)
+		}
+
+		isNew := delivery.ID == 0
+		if isNew {
+			result, err := tx.Exec(`INSERT INTO webhook_deliveries (webhook_id, data) VALUES (?, '')`, delivery.WebhookID)
+			if err != nil {
+				return err
+			}
+			id, err := result.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("failed to allocate delivery ID: %w", err)
+			}
+			delivery.ID = uint64(id)
+		}
+
+		data, err := json.Marshal(delivery)
+		if err != nil {
+			return fmt.Errorf("failed to marshal webhook delivery: %w", err)
+		}
+		_, err = tx.Exec(`INSERT INTO webhook_deliveries (id, webhook_id, data) VALUES (?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET data = excluded.data`, delivery.ID, delivery.WebhookID, data)
+		if err != nil {
+			return err
+		}
+
+		if !isNew || keep <= 0 {
+			return nil
+		}
+		_, err = tx.Exec(`DELETE FROM webhook_deliveries WHERE webhook_id = ? AND id NOT IN
+			(SELECT id FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?)`,
+			delivery.WebhookID, delivery.WebhookID, keep)
+		return err
+	})
+}
+
+// ListWebhookDeliveries returns the deliveries recorded for a webhook, most
+// recent first.
+func (s *SQLiteStore) ListWebhookDeliveries(webhookID string) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := s.list("ListWebhookDeliveries", func(data []byte) error {
+		var delivery models.WebhookDelivery
+		if err := json.Unmarshal(data, &delivery); err != nil {
+			return fmt.Errorf("failed to unmarshal webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+		return nil
+	}, `SELECT data FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC`, webhookID)
+	return deliveries, err
+}
+
+func (s *SQLiteStore) SaveNetwork(network models.Network) error {
+	data, err := json.Marshal(network)
+	if err != nil {
+		return fmt.Errorf("failed to marshal network: %w", err)
+	}
+	return s.exec("SaveNetwork", `INSERT OR REPLACE INTO networks (name, data) VALUES (?, ?)`, network.Name, data)
+}
+
+func (s *SQLiteStore) GetNetwork(name string) (models.Network, error) {
+	var network models.Network
+	err := s.get("GetNetwork", "network", name, &network, `SELECT data FROM networks WHERE name = ?`, name)
+	return network, err
+}
+
+func (s *SQLiteStore) ListNetworks() ([]models.Network, error) {
+	var networks []models.Network
+	err := s.list("ListNetworks", func(data []byte) error {
+		var network models.Network
+		if err := json.Unmarshal(data, &network); err != nil {
+			return fmt.Errorf("failed to unmarshal network: %w", err)
+		}
+		networks = append(networks, network)
+		return nil
+	}, `SELECT data FROM networks ORDER BY name`)
+	return networks, err
+}
+
+func (s *SQLiteStore) DeleteNetwork(name string) error {
+	return s.exec("DeleteNetwork", `DELETE FROM networks WHERE name = ?`, name)
+}
+
+func (s *SQLiteStore) SaveVolume(volume models.Volume) error {
+	data, err := json.Marshal(volume)
+	if err != nil {
+		return fmt.Errorf("failed to marshal volume: %w", err)
+	}
+	return s.exec("SaveVolume", `INSERT OR REPLACE INTO volumes (name, data) VALUES (?, ?)`, volume.Name, data)
+}
+
+func (s *SQLiteStore) GetVolume(name string) (models.Volume, error) {
+	var volume models.Volume
+	err := s.get("GetVolume", "volume", name, &volume, `SELECT data FROM volumes WHERE name = ?`, name)
+	return volume, err
+}
+
+func (s *SQLiteStore) ListVolumes() ([]models.Volume, error) {
+	var volumes []models.Volume
+	err := s.list("ListVolumes", func(data []byte) error {
+		var volume models.Volume
+		if err := json.Unmarshal(data, &volume); err != nil {
+			return fmt.Errorf("failed to unmarshal volume: %w", err)
+		}
+		volumes = append(volumes, volume)
+		return nil
+	}, `SELECT data FROM volumes ORDER BY name`)
+	return volumes, err
+}
+
+func (s *SQLiteStore) DeleteVolume(name string) error {
+	return s.exec("DeleteVolume", `DELETE FROM volumes WHERE name = ?`, name)
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"podium/internal/models"
+)
+
+// sqliteHeader starts every SQLite database file.
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// Backup writes a consistent copy of the database to w. The copy is made
+// with VACUUM INTO, which only reads the database, so the store stays
+// usable while it is written.
+func (s *SQLiteStore) Backup(w io.Writer) (int64, error) {
+	dir, err := os.MkdirTemp("", "podium-backup-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "podium.sqlite")
+
+	if err := s.exec("Backup", `VACUUM INTO ?`, path); err != nil {
+		return 0, fmt.Errorf("failed to copy database: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return io.Copy(w, f)
+}
+
+// ValidateSnapshot checks that the file at path is a snapshot of a SQLite
+// store, as ValidateSQLiteSnapshot does.
+func (s *SQLiteStore) ValidateSnapshot(path string) error {
+	return ValidateSQLiteSnapshot(path)
+}
+
+// ValidateSQLiteSnapshot checks that the file at path is an intact SQLite
+// database with the tables of a Podium store, and that the records in them
+// can be read.
+func ValidateSQLiteSnapshot(path string) error {
+	header := make([]byte, len(sqliteHeader))
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	_, err = io.ReadFull(f, header)
+	f.Close()
+	if err != nil || !bytes.Equal(header, sqliteHeader) {
+		return fmt.Errorf("%w: not a SQLite database", ErrInvalidSnapshot)
+	}
+
+	db, err := openReadOnly(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("%w: %s", ErrInvalidSnapshot, result)
+	}
+
+	for _, table := range sqliteTables {
+		rows, err := db.Query(`SELECT ` + table.columns + ` FROM ` + table.name + ` LIMIT 0`)
+		if err != nil {
+			return fmt.Errorf("%w: %s table: %v", ErrInvalidSnapshot, table.name, err)
+		}
+		rows.Close()
+	}
+
+	checks := map[string]func() interface{}{
+		"containers": func() interface{} { return &models.Container{} },
+		"services":   func() interface{} { return &models.Service{} },
+		"registries": func() interface{} { return &models.RegistryCredential{} },
+		"webhooks":   func() interface{} { return &models.Webhook{} },
+		"networks":   func() interface{} { return &models.Network{} },
+		"volumes":    func() interface{} { return &models.Volume{} },
+	}
+	for name, record := range checks {
+		if err := checkRecords(db, name, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkRecords(db *sql.DB, table string, record func() interface{}) error {
+	rows, err := db.Query(`SELECT rowid, data FROM ` + table)
+	if err != nil {
+		return fmt.Errorf("%w: %s table: %v", ErrInvalidSnapshot, table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rowid int64
+		var data []byte
+		if err := rows.Scan(&rowid, &data); err != nil {
+			return fmt.Errorf("%w: %s table: %v", ErrInvalidSnapshot, table, err)
+		}
+		if err := json.Unmarshal(data, record()); err != nil {
+			return fmt.Errorf("%w: %s record %d: %v", ErrInvalidSnapshot, table, rowid, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %s table: %v", ErrInvalidSnapshot, table, err)
+	}
+	return nil
+}
+
+func openReadOnly(path string) (*sql.DB, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	uri := url.URL{Scheme: "file", Path: filepath.ToSlash(abs), RawQuery: "mode=ro"}
+	return sql.Open("sqlite", uri.String())
+}
+
+// Restore replaces everything in the store with the contents of the
+// snapshot at path, which should have been checked with ValidateSnapshot.
+// The snapshot is attached to the store's connection and copied in a single
+// transaction, so readers see either the old state or the restored one.
+func (s *SQLiteStore) Restore(path string) error {
+	defer observe("Restore")()
+
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS snapshot`, path); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	defer conn.ExecContext(ctx, `DETACH DATABASE snapshot`)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range sqliteTables {
+		if _, err := tx.Exec(`DELETE FROM main.` + table.name); err != nil {
+			return fmt.Errorf("failed to clear %s table: %w", table.name, err)
+		}
+		_, err := tx.Exec(`INSERT INTO main.` + table.name + ` (` + table.columns + `) SELECT ` + table.columns +
+			` FROM snapshot.` + table.name)
+		if err != nil {
+			return fmt.Errorf("failed to restore %s table: %w", table.name, err)
+		}
+	}
+
+	// The sequences of event and delivery IDs are restored too, as in bolt.
+	if _, err := tx.Exec(`DELETE FROM main.sqlite_sequence`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO main.sqlite_sequence (name, seq) SELECT name, seq FROM snapshot.sqlite_sequence`); err != nil {
+		return fmt.Errorf("failed to restore sequences: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"podium/internal/models"
+)
+
+// ErrNotFound is wrapped by the errors of lookups of missing records.
+var ErrNotFound = errors.New("not found")
+
+// Store is the persistent state of Podium. Lookups of missing records fail
+// with a "<kind> not found" error wrapping ErrNotFound, and records returned
+// are copies the caller is free to change.
+type Store interface {
+	Close() error
+
+	CreateContainer(container models.Container) error
+	GetContainer(id string) (models.Container, error)
+	ListContainers() ([]models.Container, error)
+	UpdateContainer(container models.Container) error
+	DeleteContainer(id string) error
+	GetContainersByStatus(status string) ([]models.Container, error)
+	// QueryContainers returns the page of containers matching filter that
+	// options select, along with how many containers match in all.
+	QueryContainers(filter models.ContainerFilter, options models.ListOptions) ([]models.Container, int, error)
+
+	CreateService(service models.Service) error
+	GetService(id string) (models.Service, error)
+	GetServiceByName(name string) (models.Service, error)
+	ListServices() ([]models.Service, error)
+	// QueryServices returns the page of services matching filter that
+	// options select, along with how many services match in all.
+	QueryServices(filter models.ServiceFilter, options models.ListOptions) ([]models.Service, int, error)
+	UpdateService(service models.Service) error
+	// DeleteService deletes the service along with its revisions.
+	DeleteService(id string) error
+
+	// SaveServiceRevision stores revision under the next revision number of
+	// its service, which it sets.
+	SaveServiceRevision(revision *models.ServiceRevision) error
+	GetServiceRevision(serviceID string, revision int) (models.ServiceRevision, error)
+	// ListServiceRevisions returns the revisions of a service, oldest first.
+	ListServiceRevisions(serviceID string) ([]models.ServiceRevision, error)
+
+	SaveRegistryCredential(credential models.RegistryCredential) error
+	GetRegistryCredential(registry string) (models.RegistryCredential, error)
+	ListRegistryCredentials() ([]models.RegistryCredential, error)
+	DeleteRegistryCredential(registry string) error
+
+	// SaveEvent assigns the next event ID to event and stores it.
+	SaveEvent(event *models.Event) error
+	// ListEvents returns the events matching filter, oldest first.
+	ListEvents(filter models.EventFilter) ([]models.Event, error)
+	// PruneEvents deletes events older than the most recent keep.
+	PruneEvents(keep int) error
+
+	SaveWebhook(webhook models.Webhook) error
+	GetWebhook(id string) (models.Webhook, error)
+	ListWebhooks() ([]models.Webhook, error)
+	// DeleteWebhook deletes the webhook along with its delivery log.
+	DeleteWebhook(id string) error
+	// SaveWebhookDelivery stores delivery in the log of its webhook,
+	// assigning it the next delivery ID if it has none, and keeps only the
+	// most recent keep deliveries of the webhook.
+	SaveWebhookDelivery(delivery *models.WebhookDelivery, keep int) error
+	// ListWebhookDeliveries returns the deliveries of a webhook, most recent
+	// first.
+	ListWebhookDeliveries(webhookID string) ([]models.WebhookDelivery, error)
+
+	SaveNetwork(network models.Network) error
+	GetNetwork(name string) (models.Network, error)
+	ListNetworks() ([]models.Network, error)
+	DeleteNetwork(name string) error
+
+	SaveVolume(volume models.Volume) error
+	GetVolume(name string) (models.Volume, error)
+	ListVolumes() ([]models.Volume, error)
+	DeleteVolume(name string) error
+
+	// Backup writes a consistent snapshot of the store to w while it stays
+	// usable.
+	Backup(w io.Writer) (int64, error)
+	// ValidateSnapshot checks that the file at path is a snapshot this store
+	// can restore, failing with ErrInvalidSnapshot otherwise.
+	ValidateSnapshot(path string) error
+	// Restore replaces everything in the store with the snapshot at path, in
+	// one step.
+	Restore(path string) error
+}
+
+var (
+	_ Store = (*BoltStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*SQLiteStore)(nil)
+)
+
+// ValidateSnapshotFile checks that the file at path is a snapshot one of the
+// stores can restore, telling which wrote it from its first bytes. It lets
+// clients check snapshots without knowing the store of the server.
+func ValidateSnapshotFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	header, err := bufio.NewReader(f).Peek(len(sqliteHeader))
+	f.Close()
+	if len(header) == 0 {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+
+	switch {
+	case header[0] == '{':
+		return NewMemoryStore().ValidateSnapshot(path)
+	case bytes.Equal(header, sqliteHeader):
+		return ValidateSQLiteSnapshot(path)
+	}
+	return ValidateSnapshot(path)
+}
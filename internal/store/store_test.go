@@ -0,0 +1,465 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"podium/internal/models"
+)
+
+// TestMemoryStore runs the store contract tests against the MemoryStore.
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func(t *testing.T) Store {
+		return NewMemoryStore()
+	})
+}
+
+// TestBoltStore runs the store contract tests against a BoltStore in a
+// temporary file.
+func TestBoltStore(t *testing.T) {
+	testStore(t, func(t *testing.T) Store {
+		s, err := NewBoltStore(filepath.Join(t.TempDir(), "podium.db"))
+		if err != nil {
+			t.Fatalf("opening bolt store: %v", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
+
+// TestSQLiteStore runs the store contract tests against a SQLiteStore in a
+// temporary file.
+func TestSQLiteStore(t *testing.T) {
+	testStore(t, func(t *testing.T) Store {
+		s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "podium.sqlite"))
+		if err != nil {
+			t.Fatalf("opening sqlite store: %v", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
+
+// testStore checks the behaviour every Store must share, each subtest on a
+// new store.
+func testStore(t *testing.T, newStore func(t *testing.T) Store) {
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, newStore(t)) })
+	t.Run("Containers", func(t *testing.T) { testContainers(t, newStore(t)) })
+	t.Run("QueryContainers", func(t *testing.T) { testQueryContainers(t, newStore(t)) })
+	t.Run("QueryServices", func(t *testing.T) { testQueryServices(t, newStore(t)) })
+	t.Run("ServiceRevisions", func(t *testing.T) { testServiceRevisions(t, newStore(t)) })
+	t.Run("Events", func(t *testing.T) { testEvents(t, newStore(t)) })
+	t.Run("WebhookDeliveries", func(t *testing.T) { testWebhookDeliveries(t, newStore(t)) })
+	t.Run("Snapshots", func(t *testing.T) { testSnapshots(t, newStore(t), newStore(t)) })
+}
+
+// created is a fixed time records are created at, so that sorting by time
+// does not depend on the clock.
+var created = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testNotFound(t *testing.T, s Store) {
+	lookups := map[string]func() error{
+		"GetContainer":          func() error { _, err := s.GetContainer("missing"); return err },
+		"GetService":            func() error { _, err := s.GetService("missing"); return err },
+		"GetServiceByName":      func() error { _, err := s.GetServiceByName("missing"); return err },
+		"GetServiceRevision":    func() error { _, err := s.GetServiceRevision("missing", 1); return err },
+		"GetRegistryCredential": func() error { _, err := s.GetRegistryCredential("missing"); return err },
+		"GetWebhook":            func() error { _, err := s.GetWebhook("missing"); return err },
+		"GetNetwork":            func() error { _, err := s.GetNetwork("missing"); return err },
+		"GetVolume":             func() error { _, err := s.GetVolume("missing"); return err },
+		"SaveWebhookDelivery":   func() error { return s.SaveWebhookDelivery(&models.WebhookDelivery{WebhookID: "missing"}, 0) },
+	}
+	for name, lookup := range lookups {
+		if err := lookup(); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s of a missing record = %v, want an error wrapping ErrNotFound", name, err)
+		}
+	}
+
+	if err := s.CreateService(models.Service{ID: "svc", Name: "web", CreatedAt: created}); err != nil {
+		t.Fatalf("creating service: %v", err)
+	}
+	if _, err := s.GetServiceRevision("svc", 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetServiceRevision of a missing revision = %v, want an error wrapping ErrNotFound", err)
+	}
+}
+
+func testContainers(t *testing.T, s Store) {
+	container := models.Container{ID: "c1", Name: "web-0", Image: "nginx", State: models.ContainerStatePending, CreatedAt: created}
+	if err := s.CreateContainer(container); err != nil {
+		t.Fatalf("creating container: %v", err)
+	}
+
+	got, err := s.GetContainer("c1")
+	if err != nil || got.Name != "web-0" || got.Image != "nginx" {
+		t.Fatalf("GetContainer = %+v, %v, want the created container", got, err)
+	}
+	// Records returned are copies.
+	got.Name = "changed"
+	if again, _ := s.GetContainer("c1"); again.Name != "web-0" {
+		t.Errorf("changing a returned container changed the stored one")
+	}
+
+	container.State = models.ContainerStateRunning
+	if err := s.UpdateContainer(container); err != nil {
+		t.Fatalf("updating container: %v", err)
+	}
+	running, err := s.GetContainersByStatus(string(models.ContainerStateRunning))
+	if err != nil || len(running) != 1 || running[0].ID != "c1" {
+		t.Errorf("GetContainersByStatus(running) = %+v, %v, want c1", running, err)
+	}
+
+	if err := s.DeleteContainer("c1"); err != nil {
+		t.Fatalf("deleting container: %v", err)
+	}
+	if _, err := s.GetContainer("c1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetContainer of deleted container = %v, want ErrNotFound", err)
+	}
+	if containers, err := s.ListContainers(); err != nil || len(containers) != 0 {
+		t.Errorf("ListContainers after delete = %+v, %v, want none", containers, err)
+	}
+}
+
+func testQueryContainers(t *testing.T, s Store) {
+	for i := range 5 {
+		state := models.ContainerStateRunning
+		if i%2 == 1 {
+			state = models.ContainerStateFailed
+		}
+		container := models.Container{
+			ID:        fmt.Sprintf("c%d", i),
+			Name:      fmt.Sprintf("web-%d", 4-i),
+			State:     state,
+			Labels:    map[string]string{"podium.service.id": "svc", "tier": fmt.Sprint(i % 3)},
+			CreatedAt: created.Add(time.Duration(i) * time.Minute),
+		}
+		if i == 4 {
+			container.Labels["podium.service.id"] = "other"
+		}
+		if err := s.CreateContainer(container); err != nil {
+			t.Fatalf("creating container: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		filter  models.ContainerFilter
+		options models.ListOptions
+		want    []string
+		total   int
+	}{
+		{"all", models.ContainerFilter{}, models.ListOptions{}, []string{"c0", "c1", "c2", "c3", "c4"}, 5},
+		{"state", models.ContainerFilter{State: models.ContainerStateFailed}, models.ListOptions{}, []string{"c1", "c3"}, 2},
+		{"name", models.ContainerFilter{Name: "web-2"}, models.ListOptions{}, []string{"c2"}, 1},
+		{"service", models.ContainerFilter{ServiceID: "svc"}, models.ListOptions{}, []string{"c0", "c1", "c2", "c3"}, 4},
+		{"labels", models.ContainerFilter{Labels: map[string]string{"tier": "1"}}, models.ListOptions{}, []string{"c1", "c4"}, 2},
+		{"sort by name", models.ContainerFilter{}, models.ListOptions{Sort: "name"}, []string{"c4", "c3", "c2", "c1", "c0"}, 5},
+		{"sort by state then ID", models.ContainerFilter{}, models.ListOptions{Sort: "state"}, []string{"c1", "c3", "c0", "c2", "c4"}, 5},
+		{"descending", models.ContainerFilter{}, models.ListOptions{Sort: "createdAt", Descending: true}, []string{"c4", "c3", "c2", "c1", "c0"}, 5},
+		{"page", models.ContainerFilter{}, models.ListOptions{Limit: 2, Offset: 1}, []string{"c1", "c2"}, 5},
+		{"last page", models.ContainerFilter{}, models.ListOptions{Limit: 2, Offset: 4}, []string{"c4"}, 5},
+		{"past the end", models.ContainerFilter{}, models.ListOptions{Offset: 10}, nil, 5},
+		{"filtered page", models.ContainerFilter{ServiceID: "svc"}, models.ListOptions{Limit: 1, Offset: 3}, []string{"c3"}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, total, err := s.QueryContainers(tt.filter, tt.options)
+			if err != nil {
+				t.Fatalf("QueryContainers: %v", err)
+			}
+			var ids []string
+			for _, container := range page {
+				ids = append(ids, container.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) || total != tt.total {
+				t.Errorf("QueryContainers = %v (%d in all), want %v (%d in all)", ids, total, tt.want, tt.total)
+			}
+		})
+	}
+}
+
+func testQueryServices(t *testing.T, s Store) {
+	states := []models.ServiceState{models.ServiceStateRunning, models.ServiceStateStopped, models.ServiceStateRunning}
+	for i, state := range states {
+		service := models.Service{
+			ID:        fmt.Sprintf("s%d", i),
+			Name:      fmt.Sprintf("svc-%d", 2-i),
+			State:     state,
+			CreatedAt: created,
+			UpdatedAt: created.Add(time.Duration(2-i) * time.Minute),
+		}
+		if err := s.CreateService(service); err != nil {
+			t.Fatalf("creating service: %v", err)
+		}
+	}
+
+	if service, err := s.GetServiceByName("svc-0"); err != nil || service.ID != "s2" {
+		t.Errorf("GetServiceByName(svc-0) = %+v, %v, want s2", service, err)
+	}
+
+	tests := []struct {
+		name    string
+		filter  models.ServiceFilter
+		options models.ListOptions
+		want    []string
+		total   int
+	}{
+		{"all", models.ServiceFilter{}, models.ListOptions{}, []string{"s0", "s1", "s2"}, 3},
+		{"state", models.ServiceFilter{State: models.ServiceStateRunning}, models.ListOptions{}, []string{"s0", "s2"}, 2},
+		{"name", models.ServiceFilter{Name: "svc-1"}, models.ListOptions{}, []string{"s1"}, 1},
+		{"sort by updatedAt", models.ServiceFilter{}, models.ListOptions{Sort: "updatedAt"}, []string{"s2", "s1", "s0"}, 3},
+		{"equal createdAt sorts by ID", models.ServiceFilter{}, models.ListOptions{Sort: "createdAt", Descending: true}, []string{"s0", "s1", "s2"}, 3},
+		{"page", models.ServiceFilter{}, models.ListOptions{Sort: "name", Limit: 1, Offset: 1}, []string{"s1"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, total, err := s.QueryServices(tt.filter, tt.options)
+			if err != nil {
+				t.Fatalf("QueryServices: %v", err)
+			}
+			var ids []string
+			for _, service := range page {
+				ids = append(ids, service.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) || total != tt.total {
+				t.Errorf("QueryServices = %v (%d in all), want %v (%d in all)", ids, total, tt.want, tt.total)
+			}
+		})
+	}
+}
+
+func testServiceRevisions(t *testing.T, s Store) {
+	for _, id := range []string{"a", "b"} {
+		if err := s.CreateService(models.Service{ID: id, Name: id, CreatedAt: created}); err != nil {
+			t.Fatalf("creating service: %v", err)
+		}
+	}
+
+	// Each service numbers its revisions on its own, from 1.
+	for _, tt := range []struct {
+		serviceID string
+		want      int
+	}{{"a", 1}, {"a", 2}, {"b", 1}, {"a", 3}} {
+		revision := &models.ServiceRevision{ServiceID: tt.serviceID, SpecHash: fmt.Sprintf("%s-%d", tt.serviceID, tt.want), CreatedAt: created}
+		if err := s.SaveServiceRevision(revision); err != nil {
+			t.Fatalf("saving revision: %v", err)
+		}
+		if revision.Revision != tt.want {
+			t.Errorf("revision of service %s numbered %d, want %d", tt.serviceID, revision.Revision, tt.want)
+		}
+	}
+
+	revision, err := s.GetServiceRevision("a", 2)
+	if err != nil || revision.SpecHash != "a-2" {
+		t.Errorf("GetServiceRevision(a, 2) = %+v, %v, want a-2", revision, err)
+	}
+	revisions, err := s.ListServiceRevisions("a")
+	if err != nil || len(revisions) != 3 || revisions[0].Revision != 1 || revisions[2].Revision != 3 {
+		t.Errorf("ListServiceRevisions(a) = %+v, %v, want revisions 1 to 3, oldest first", revisions, err)
+	}
+
+	if err := s.DeleteService("a"); err != nil {
+		t.Fatalf("deleting service: %v", err)
+	}
+	if revisions, err := s.ListServiceRevisions("a"); err != nil || len(revisions) != 0 {
+		t.Errorf("ListServiceRevisions of deleted service = %+v, %v, want none", revisions, err)
+	}
+	if revisions, err := s.ListServiceRevisions("b"); err != nil || len(revisions) != 1 {
+		t.Errorf("ListServiceRevisions(b) = %+v, %v, want it untouched", revisions, err)
+	}
+}
+
+func testEvents(t *testing.T, s Store) {
+	types := []models.EventType{models.EventContainerCreated, models.EventContainerStarted, models.EventServiceScaled}
+	for i := range 6 {
+		event := &models.Event{
+			Type:        types[i%3],
+			Timestamp:   created.Add(time.Duration(i) * time.Minute),
+			ContainerID: fmt.Sprintf("c%d", i%2),
+		}
+		if err := s.SaveEvent(event); err != nil {
+			t.Fatalf("saving event: %v", err)
+		}
+		if event.ID != uint64(i+1) {
+			t.Errorf("event %d got ID %d, want %d", i, event.ID, i+1)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter models.EventFilter
+		want   []uint64
+	}{
+		{"all", models.EventFilter{}, []uint64{1, 2, 3, 4, 5, 6}},
+		{"types", models.EventFilter{Types: []models.EventType{models.EventContainerCreated, models.EventServiceScaled}}, []uint64{1, 3, 4, 6}},
+		{"container", models.EventFilter{ContainerID: "c1"}, []uint64{2, 4, 6}},
+		{"since and until", models.EventFilter{Since: created.Add(2 * time.Minute), Until: created.Add(4 * time.Minute)}, []uint64{3, 4, 5}},
+		{"after ID", models.EventFilter{AfterID: 4}, []uint64{5, 6}},
+		{"limit keeps the most recent", models.EventFilter{Limit: 2}, []uint64{5, 6}},
+		{"filtered limit", models.EventFilter{ContainerID: "c0", Limit: 2}, []uint64{3, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := s.ListEvents(tt.filter)
+			if err != nil {
+				t.Fatalf("ListEvents: %v", err)
+			}
+			var ids []uint64
+			for _, event := range events {
+				ids = append(ids, event.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+				t.Errorf("ListEvents = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	if err := s.PruneEvents(2); err != nil {
+		t.Fatalf("pruning events: %v", err)
+	}
+	events, err := s.ListEvents(models.EventFilter{})
+	if err != nil || len(events) != 2 || events[0].ID != 5 {
+		t.Errorf("ListEvents after pruning to 2 = %+v, %v, want events 5 and 6", events, err)
+	}
+	// IDs keep counting up after a prune.
+	event := &models.Event{Type: models.EventContainerCreated, Timestamp: created}
+	if err := s.SaveEvent(event); err != nil || event.ID != 7 {
+		t.Errorf("event after pruning got ID %d, %v, want 7", event.ID, err)
+	}
+}
+
+func testWebhookDeliveries(t *testing.T, s Store) {
+	for _, id := range []string{"w1", "w2"} {
+		if err := s.SaveWebhook(models.Webhook{ID: id, URL: "http://example.com/" + id, CreatedAt: created}); err != nil {
+			t.Fatalf("saving webhook: %v", err)
+		}
+	}
+
+	var ids []uint64
+	for i := range 4 {
+		delivery := &models.WebhookDelivery{WebhookID: []string{"w1", "w2"}[i%2], EventID: uint64(i + 1)}
+		if err := s.SaveWebhookDelivery(delivery, 0); err != nil {
+			t.Fatalf("saving delivery: %v", err)
+		}
+		ids = append(ids, delivery.ID)
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Errorf("delivery IDs %v do not increase", ids)
+		}
+	}
+
+	// Saving a delivery with an ID updates it rather than adding one.
+	update := &models.WebhookDelivery{ID: ids[0], WebhookID: "w1", EventID: 1, State: models.WebhookDeliveryFailed}
+	if err := s.SaveWebhookDelivery(update, 1); err != nil {
+		t.Fatalf("updating delivery: %v", err)
+	}
+	deliveries, err := s.ListWebhookDeliveries("w1")
+	if err != nil || len(deliveries) != 2 || deliveries[0].ID != ids[2] || deliveries[1].State != models.WebhookDeliveryFailed {
+		t.Errorf("ListWebhookDeliveries(w1) = %+v, %v, want both deliveries, most recent first, the older one updated", deliveries, err)
+	}
+
+	// A new delivery trims the log to keep.
+	if err := s.SaveWebhookDelivery(&models.WebhookDelivery{WebhookID: "w1", EventID: 5}, 2); err != nil {
+		t.Fatalf("saving delivery: %v", err)
+	}
+	deliveries, err = s.ListWebhookDeliveries("w1")
+	if err != nil || len(deliveries) != 2 || deliveries[0].EventID != 5 || deliveries[1].EventID != 3 {
+		t.Errorf("ListWebhookDeliveries(w1) after trimming = %+v, %v, want the deliveries of events 5 and 3", deliveries, err)
+	}
+
+	if err := s.DeleteWebhook("w1"); err != nil {
+		t.Fatalf("deleting webhook: %v", err)
+	}
+	if deliveries, err := s.ListWebhookDeliveries("w1"); err != nil || len(deliveries) != 0 {
+		t.Errorf("ListWebhookDeliveries of deleted webhook = %+v, %v, want none", deliveries, err)
+	}
+	if deliveries, err := s.ListWebhookDeliveries("w2"); err != nil || len(deliveries) != 2 {
+		t.Errorf("ListWebhookDeliveries(w2) = %+v, %v, want it untouched", deliveries, err)
+	}
+}
+
+func testSnapshots(t *testing.T, s, restored Store) {
+	dir := t.TempDir()
+
+	if err := s.CreateService(models.Service{ID: "svc", Name: "web", CreatedAt: created}); err != nil {
+		t.Fatalf("creating service: %v", err)
+	}
+	if err := s.SaveServiceRevision(&models.ServiceRevision{ServiceID: "svc", CreatedAt: created}); err != nil {
+		t.Fatalf("saving revision: %v", err)
+	}
+	if err := s.CreateContainer(models.Container{ID: "c1", Name: "web-0", CreatedAt: created}); err != nil {
+		t.Fatalf("creating container: %v", err)
+	}
+	if err := s.SaveNetwork(models.Network{Name: "backend", CreatedAt: created}); err != nil {
+		t.Fatalf("saving network: %v", err)
+	}
+	if err := s.SaveEvent(&models.Event{Type: models.EventServiceScaled, Timestamp: created}); err != nil {
+		t.Fatalf("saving event: %v", err)
+	}
+
+	path := filepath.Join(dir, "snapshot")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := s.Backup(f)
+	f.Close()
+	if err != nil || n == 0 {
+		t.Fatalf("Backup = %d, %v, want a snapshot", n, err)
+	}
+	if err := s.ValidateSnapshot(path); err != nil {
+		t.Errorf("ValidateSnapshot of a backup: %v", err)
+	}
+	if err := ValidateSnapshotFile(path); err != nil {
+		t.Errorf("ValidateSnapshotFile of a backup: %v", err)
+	}
+
+	garbage := filepath.Join(dir, "garbage")
+	if err := os.WriteFile(garbage, []byte("not a snapshot at all"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ValidateSnapshot(garbage); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("ValidateSnapshot of garbage = %v, want ErrInvalidSnapshot", err)
+	}
+	if err := restored.Restore(garbage); err == nil {
+		t.Errorf("Restore of garbage succeeded, want an error")
+	}
+
+	// Restoring replaces everything in the store, including what the
+	// snapshot does not have.
+	if err := restored.CreateContainer(models.Container{ID: "stale", CreatedAt: created}); err != nil {
+		t.Fatalf("creating container: %v", err)
+	}
+	if err := restored.Restore(path); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if _, err := restored.GetContainer("stale"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("container missing from the snapshot survived the restore: %v", err)
+	}
+	if container, err := restored.GetContainer("c1"); err != nil || container.Name != "web-0" {
+		t.Errorf("GetContainer(c1) after restore = %+v, %v", container, err)
+	}
+	if service, err := restored.GetServiceByName("web"); err != nil || service.ID != "svc" {
+		t.Errorf("GetServiceByName(web) after restore = %+v, %v", service, err)
+	}
+	if revision, err := restored.GetServiceRevision("svc", 1); err != nil || revision.Revision != 1 {
+		t.Errorf("GetServiceRevision(svc, 1) after restore = %+v, %v", revision, err)
+	}
+	if _, err := restored.GetNetwork("backend"); err != nil {
+		t.Errorf("GetNetwork(backend) after restore: %v", err)
+	}
+
+	// Sequences carry over, so restored records are not handed out again.
+	event := &models.Event{Type: models.EventServiceUpdateStarted, Timestamp: created}
+	if err := restored.SaveEvent(event); err != nil || event.ID != 2 {
+		t.Errorf("event after restore got ID %d, %v, want 2", event.ID, err)
+	}
+	revision := &models.ServiceRevision{ServiceID: "svc", CreatedAt: created}
+	if err := restored.SaveServiceRevision(revision); err != nil || revision.Revision != 2 {
+		t.Errorf("revision after restore numbered %d, %v, want 2", revision.Revision, err)
+	}
+}
@@ -0,0 +1,516 @@
+package volume
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"podium/internal/models"
+	"podium/internal/runtime"
+)
+
+var (
+	// ErrVolumeBusy is returned when a volume is already being backed up
+	// or restored.
+	ErrVolumeBusy = errors.New("volume is being backed up or restored")
+	// ErrBackupNotFound is returned for a backup that is not in the backup
+	// directory.
+	ErrBackupNotFound = errors.New("backup not found")
+	// ErrInvalidArchive is returned when restoring from something that is
+	// not a tar archive of a volume.
+	ErrInvalidArchive = errors.New("invalid archive")
+)
+
+// backupTimeFormat is the time in the file names of backups.
+const backupTimeFormat = "20060102T150405.000Z"
+
+// Backup writes a tar archive of a volume to w. With quiesce, the running
+// containers that mount the volume are paused or stopped until it is done.
+func (m *Manager) Backup(ctx context.Context, name, quiesce string, w io.Writer) error {
+	if err := ValidateQuiesce(quiesce); err != nil {
+		return err
+	}
+	if _, err := m.store.GetVolume(name); err != nil {
+		return err
+	}
+	release, err := m.acquire(name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return m.backup(ctx, name, quiesce, w)
+}
+
+// backup writes the archive of a volume the caller has acquired.
+func (m *Manager) backup(ctx context.Context, name, quiesce string, w io.Writer) error {
+	resume, err := m.quiesce(ctx, name, quiesce)
+	if err != nil {
+		return err
+	}
+	defer resume()
+
+	return m.runtime.ExportVolume(ctx, name, w)
+}
+
+// Restore replaces the data in a volume with a tar archive written by
+// Backup. Running containers that mount the volume have to be stopped
+// while it is restored, which quiesce "stop" does.
+func (m *Manager) Restore(ctx context.Context, name, quiesce string, r io.Reader) error {
+	if err := ValidateQuiesce(quiesce); err != nil {
+		return err
+	}
+	if _, err := m.store.GetVolume(name); err != nil {
+		return err
+	}
+
+	// The archive is kept in a file until it has been checked, so a broken
+	// upload does not leave the volume half restored.
+	f, err := os.CreateTemp("", "podium-restore-*.tar")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("failed to receive archive: %w", err)
+	}
+
+	return m.restoreFile(ctx, name, quiesce, f)
+}
+
+// RestoreBackup restores a volume from one of its backups in the backup
+// directory.
+func (m *Manager) RestoreBackup(ctx context.Context, name, backup, quiesce string) error {
+	if err := ValidateQuiesce(quiesce); err != nil {
+		return err
+	}
+	if _, err := m.store.GetVolume(name); err != nil {
+		return err
+	}
+	if backup != filepath.Base(backup) || !strings.HasSuffix(backup, ".tar") {
+		return fmt.Errorf("%w: %s", ErrBackupNotFound, backup)
+	}
+
+	f, err := os.Open(filepath.Join(m.backupDir, name, backup))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrBackupNotFound, backup)
+		}
+		return err
+	}
+	defer f.Close()
+
+	return m.restoreFile(ctx, name, quiesce, f)
+}
+
+func (m *Manager) restoreFile(ctx context.Context, name, quiesce string, f *os.File) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := validateArchive(f); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	release, err := m.acquire(name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if quiesce != models.QuiesceStop {
+		running, err := m.runningContainers(name)
+		if err != nil {
+			return err
+		}
+		if len(running) > 0 {
+			return fmt.Errorf("%w: %d running containers mount volume %s, stop them or restore with quiesce=stop",
+				ErrVolumeInUse, len(running), name)
+		}
+	}
+	resume, err := m.quiesce(ctx, name, quiesce)
+	if err != nil {
+		return err
+	}
+	defer resume()
+
+	if err := m.runtime.ImportVolume(ctx, name, f); err != nil {
+		if errors.Is(err, runtime.ErrVolumeIncomplete) {
+			log.Printf("Volume %s holds only part of the restored data, restore it again: %v", name, err)
+		}
+		return err
+	}
+	log.Printf("Volume %s restored", name)
+	return nil
+}
+
+// CreateBackup backs a volume up to the backup directory.
+func (m *Manager) CreateBackup(ctx context.Context, name, quiesce string) (models.VolumeBackup, error) {
+	// The volume is checked and acquired before anything is written, so
+	// that a bad request leaves no directory or partial file behind.
+	if err := ValidateQuiesce(quiesce); err != nil {
+		return models.VolumeBackup{}, err
+	}
+	if _, err := m.store.GetVolume(name); err != nil {
+		return models.VolumeBackup{}, err
+	}
+	release, err := m.acquire(name)
+	if err != nil {
+		return models.VolumeBackup{}, err
+	}
+	defer release()
+
+	dir := filepath.Join(m.backupDir, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return models.VolumeBackup{}, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	now := time.Now().UTC()
+	backup := models.VolumeBackup{
+		Name:      name + "-" + now.Format(backupTimeFormat) + ".tar",
+		Volume:    name,
+		CreatedAt: now,
+	}
+	file := filepath.Join(dir, backup.Name)
+
+	// The archive is written under a temporary name so an unfinished backup
+	// is never mistaken for a complete one.
+	f, err := os.Create(file + ".partial")
+	if err != nil {
+		return models.VolumeBackup{}, err
+	}
+	err = m.backup(ctx, name, quiesce, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file+".partial", file)
+	}
+	if err != nil {
+		os.Remove(file + ".partial")
+		return models.VolumeBackup{}, err
+	}
+
+	if info, err := os.Stat(file); err == nil {
+		backup.Size = info.Size()
+	}
+	log.Printf("Volume %s backed up to %s", name, file)
+	return backup, nil
+}
+
+// ListBackups returns the backups of a volume in the backup directory,
+// newest first.
+func (m *Manager) ListBackups(name string) ([]models.VolumeBackup, error) {
+	entries, err := os.ReadDir(filepath.Join(m.backupDir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []models.VolumeBackup{}, nil
+		}
+		return nil, err
+	}
+
+	backups := []models.VolumeBackup{}
+	for _, entry := range entries {
+		stamp, ok := strings.CutPrefix(entry.Name(), name+"-")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, ".tar")
+		if !ok {
+			continue
+		}
+		createdAt, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, models.VolumeBackup{
+			Name:      entry.Name(),
+			Volume:    name,
+			Size:      info.Size(),
+			CreatedAt: createdAt,
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// SetBackupSchedule sets or, with nil, removes the backup schedule of a
+// volume.
+func (m *Manager) SetBackupSchedule(name string, schedule *models.VolumeBackupSchedule) (models.Volume, error) {
+	if err := ValidateBackupSchedule(schedule); err != nil {
+		return models.Volume{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	volume, err := m.store.GetVolume(name)
+	if err != nil {
+		return models.Volume{}, err
+	}
+	volume.BackupSchedule = schedule
+	if err := m.store.SaveVolume(volume); err != nil {
+		return models.Volume{}, err
+	}
+	return volume, nil
+}
+
+// runSchedules backs up every volume whose last backup is older than its
+// schedule's interval, then removes the backups beyond its retention count.
+func (m *Manager) runSchedules() {
+	volumes, err := m.store.ListVolumes()
+	if err != nil {
+		log.Printf("Error listing volumes for scheduled backups: %v", err)
+		return
+	}
+
+	for _, volume := range volumes {
+		schedule := volume.BackupSchedule
+		if schedule == nil {
+			continue
+		}
+		backups, err := m.ListBackups(volume.Name)
+		if err != nil {
+			log.Printf("Error listing backups of volume %s: %v", volume.Name, err)
+			continue
+		}
+		if len(backups) > 0 && time.Since(backups[0].CreatedAt) < schedule.Interval {
+			continue
+		}
+
+		log.Printf("Running scheduled backup of volume %s", volume.Name)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		_, err = m.CreateBackup(ctx, volume.Name, schedule.Quiesce)
+		cancel()
+		if err != nil {
+			log.Printf("Scheduled backup of volume %s failed: %v", volume.Name, err)
+			continue
+		}
+		m.pruneBackups(volume.Name, schedule.Retain)
+	}
+}
+
+// pruneBackups removes all but the newest retain backups of a volume.
+func (m *Manager) pruneBackups(name string, retain int) {
+	backups, err := m.ListBackups(name)
+	if err != nil {
+		log.Printf("Error listing backups of volume %s: %v", name, err)
+		return
+	}
+	for i := retain; i < len(backups); i++ {
+		file := filepath.Join(m.backupDir, name, backups[i].Name)
+		if err := os.Remove(file); err != nil {
+			log.Printf("Error removing old backup %s: %v", file, err)
+			continue
+		}
+		log.Printf("Removed old backup %s", file)
+	}
+}
+
+// acquire marks a volume as being backed up or restored, and returns a
+// function that clears the mark.
+func (m *Manager) acquire(name string) (func(), error) {
+	m.busyMu.Lock()
+	defer m.busyMu.Unlock()
+
+	if m.busy[name] {
+		return nil, fmt.Errorf("%w: %s", ErrVolumeBusy, name)
+	}
+	m.busy[name] = true
+	return func() {
+		m.busyMu.Lock()
+		defer m.busyMu.Unlock()
+		delete(m.busy, name)
+	}, nil
+}
+
+func (m *Manager) isBusy(name string) bool {
+	m.busyMu.Lock()
+	defer m.busyMu.Unlock()
+	return m.busy[name]
+}
+
+// Quiesced reports whether a container is stopped until a volume it mounts
+// is backed up or restored. It is started again afterwards, so it should not
+// be replaced.
+func (m *Manager) Quiesced(id string) bool {
+	m.busyMu.Lock()
+	defer m.busyMu.Unlock()
+	return m.quiesced[id]
+}
+
+func (m *Manager) setQuiesced(id string, quiesced bool) {
+	m.busyMu.Lock()
+	defer m.busyMu.Unlock()
+	if quiesced {
+		m.quiesced[id] = true
+	} else {
+		delete(m.quiesced, id)
+	}
+}
+
+// runningContainers returns the running containers that mount a volume.
+func (m *Manager) runningContainers(name string) ([]models.Container, error) {
+	usage, err := m.Usage(name)
+	if err != nil {
+		return nil, err
+	}
+	var running []models.Container
+	for _, id := range usage.Containers {
+		container, err := m.store.GetContainer(id)
+		if err != nil || container.State != models.ContainerStateRunning {
+			continue
+		}
+		running = append(running, container)
+	}
+	return running, nil
+}
+
+// quiesce pauses or stops the running containers that mount a volume and
+// returns a function that resumes them.
+func (m *Manager) quiesce(ctx context.Context, name, mode string) (func(), error) {
+	if mode == "" {
+		return func() {}, nil
+	}
+	running, err := m.runningContainers(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var quiesced []string
+	resume := func() {
+		for _, id := range quiesced {
+			m.resume(id, mode)
+		}
+	}
+	for _, container := range running {
+		if mode == models.QuiescePause {
+			err = m.runtime.PauseContainer(ctx, container.ID)
+		} else {
+			// The container is recorded as stopped first, so the health
+			// worker does not restart it, and marked as quiesced, so the
+			// reconciler does not replace it.
+			m.setQuiesced(container.ID, true)
+			container.State = models.ContainerStateSucceeded
+			now := time.Now()
+			container.FinishedAt = &now
+			if err = m.store.UpdateContainer(container); err == nil {
+				err = m.runtime.StopContainer(ctx, container.ID)
+			}
+		}
+		if err != nil {
+			log.Printf("Error quiescing container %s: %v", container.ID, err)
+			if mode == models.QuiesceStop {
+				quiesced = append(quiesced, container.ID)
+			}
+			resume()
+			return nil, fmt.Errorf("failed to %s container %s: %w", mode, container.ID, err)
+		}
+		quiesced = append(quiesced, container.ID)
+	}
+	if len(quiesced) > 0 {
+		log.Printf("Quiesced %d containers mounting volume %s (%s)", len(quiesced), name, mode)
+	}
+	return resume, nil
+}
+
+func (m *Manager) resume(id, mode string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if mode == models.QuiescePause {
+		if err := m.runtime.UnpauseContainer(ctx, id); err != nil {
+			log.Printf("Error unpausing container %s: %v", id, err)
+		}
+		return
+	}
+
+	// Once the mark is cleared, a container that failed to start again is
+	// left to the reconciler.
+	defer m.setQuiesced(id, false)
+	if err := m.runtime.StartContainer(ctx, id); err != nil {
+		log.Printf("Error restarting container %s: %v", id, err)
+		return
+	}
+	container, err := m.store.GetContainer(id)
+	if err != nil {
+		log.Printf("Warning: Failed to get container %s: %v", id, err)
+		return
+	}
+	container.State = models.ContainerStateRunning
+	now := time.Now()
+	container.StartedAt = &now
+	container.FinishedAt = nil
+	if err := m.store.UpdateContainer(container); err != nil {
+		log.Printf("Warning: Failed to update container state in database: %v", err)
+	}
+}
+
+// validateArchive checks that r is a tar archive whose paths all stay
+// within the volume.
+func validateArchive(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		if outsideVolume(header.Name) {
+			return fmt.Errorf("%w: path outside the volume: %s", ErrInvalidArchive, header.Name)
+		}
+		// Symbolic links may point anywhere, since they are resolved inside
+		// the container, but hard links must be to files in the archive.
+		if header.Typeflag == tar.TypeLink && (header.Linkname == "" || outsideVolume(header.Linkname)) {
+			return fmt.Errorf("%w: hard link to a file outside the volume: %s", ErrInvalidArchive, header.Name)
+		}
+	}
+}
+
+func outsideVolume(name string) bool {
+	return path.IsAbs(name) || strings.Contains("/"+name+"/", "/../")
+}
+
+// ValidateQuiesce checks how containers are to be quiesced.
+func ValidateQuiesce(quiesce string) error {
+	switch quiesce {
+	case "", models.QuiescePause, models.QuiesceStop:
+		return nil
+	}
+	return fmt.Errorf("unknown quiesce mode: %s", quiesce)
+}
+
+// ValidateBackupSchedule checks the interval, retention count and quiesce
+// mode of a backup schedule.
+func ValidateBackupSchedule(schedule *models.VolumeBackupSchedule) error {
+	if schedule == nil {
+		return nil
+	}
+	if schedule.Interval < time.Minute {
+		return errors.New("backup interval must be at least a minute")
+	}
+	if schedule.Retain < 1 {
+		return errors.New("at least one backup must be retained")
+	}
+	return ValidateQuiesce(schedule.Quiesce)
+}
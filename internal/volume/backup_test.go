@@ -0,0 +1,133 @@
+package volume
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"podium/internal/models"
+	"podium/internal/runtime"
+	"podium/internal/store"
+)
+
+// newTestManager returns a manager on the fake runtime with a volume named
+// data.
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+
+	m := NewManager(store.NewMemoryStore(), runtime.NewFakeRuntime(), t.TempDir(), time.Minute)
+	if err := m.CreateVolume(context.Background(), &models.Volume{Name: "data"}); err != nil {
+		t.Fatalf("creating volume: %v", err)
+	}
+	return m
+}
+
+func archive(t *testing.T, headers ...tar.Header) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, header := range headers {
+		header.Mode = 0644
+		if err := tw.WriteHeader(&header); err != nil {
+			t.Fatalf("writing header: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing archive: %v", err)
+	}
+	return &buf
+}
+
+func TestValidateArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  tar.Header
+		wantErr bool
+	}{
+		{"file", tar.Header{Name: "conf/app.conf", Typeflag: tar.TypeReg}, false},
+		{"symbolic link out of the volume", tar.Header{Name: "etc", Linkname: "/etc", Typeflag: tar.TypeSymlink}, false},
+		{"hard link", tar.Header{Name: "b", Linkname: "conf/app.conf", Typeflag: tar.TypeLink}, false},
+		{"absolute path", tar.Header{Name: "/etc/passwd", Typeflag: tar.TypeReg}, true},
+		{"parent path", tar.Header{Name: "conf/../../passwd", Typeflag: tar.TypeReg}, true},
+		{"absolute hard link", tar.Header{Name: "passwd", Linkname: "/etc/passwd", Typeflag: tar.TypeLink}, true},
+		{"parent hard link", tar.Header{Name: "passwd", Linkname: "../passwd", Typeflag: tar.TypeLink}, true},
+		{"empty hard link", tar.Header{Name: "passwd", Typeflag: tar.TypeLink}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateArchive(archive(t, tt.header))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateArchive() = %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidArchive) {
+				t.Errorf("validateArchive() = %v, want ErrInvalidArchive", err)
+			}
+		})
+	}
+}
+
+func TestCreateBackup(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+
+	backup, err := m.CreateBackup(ctx, "data", "")
+	if err != nil {
+		t.Fatalf("backing up volume: %v", err)
+	}
+	backups, err := m.ListBackups("data")
+	if err != nil || len(backups) != 1 || backups[0].Name != backup.Name {
+		t.Fatalf("got backups %v, %v, want only %s", backups, err, backup.Name)
+	}
+	if err := m.RestoreBackup(ctx, "data", backup.Name, ""); err != nil {
+		t.Errorf("restoring backup: %v", err)
+	}
+}
+
+func TestCreateBackupLeavesNothingOnError(t *testing.T) {
+	tests := []struct {
+		name, volume, quiesce string
+		busy                  bool
+		want                  error
+	}{
+		{"unknown volume", "../logs", "", false, nil},
+		{"unknown quiesce mode", "data", "freeze", false, nil},
+		{"busy volume", "data", "", true, ErrVolumeBusy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t)
+			if tt.busy {
+				release, err := m.acquire(tt.volume)
+				if err != nil {
+					t.Fatalf("acquiring volume: %v", err)
+				}
+				defer release()
+			}
+
+			_, err := m.CreateBackup(context.Background(), tt.volume, tt.quiesce)
+			if err == nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("CreateBackup() = %v, want an error", err)
+			}
+			if entries, _ := os.ReadDir(m.backupDir); len(entries) != 0 {
+				t.Errorf("backup directory holds %v, want nothing", entries)
+			}
+		})
+	}
+}
+
+func TestRestoreRejectsMaliciousArchive(t *testing.T) {
+	m := newTestManager(t)
+	malicious := archive(t,
+		tar.Header{Name: "out", Linkname: "/etc", Typeflag: tar.TypeSymlink},
+		tar.Header{Name: "passwd", Linkname: "../../etc/passwd", Typeflag: tar.TypeLink},
+	)
+
+	if err := m.Restore(context.Background(), "data", "", malicious); !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("Restore() = %v, want ErrInvalidArchive", err)
+	}
+}
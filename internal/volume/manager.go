@@ -0,0 +1,286 @@
+package volume
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"podium/internal/models"
+	"podium/internal/runtime"
+	"podium/internal/store"
+)
+
+// ErrVolumeInUse is returned when deleting a volume that containers or
+// services still mount.
+var ErrVolumeInUse = errors.New("volume is in use")
+
+// Manager keeps the volumes in the store and the runtime in step, and backs
+// volumes up on their schedules. Unlike networks, volumes created
+// automatically are never removed automatically, since they hold data.
+type Manager struct {
+	store     store.Store
+	runtime   runtime.Runtime
+	backupDir string
+	interval  time.Duration
+	stopCh    chan struct{}
+
+	// mu serialises creating and removing volumes.
+	mu sync.Mutex
+
+	// busy holds the volumes being backed up or restored, and quiesced the
+	// containers stopped until that is done.
+	busyMu   sync.Mutex
+	busy     map[string]bool
+	quiesced map[string]bool
+}
+
+// Usage lists what mounts a volume.
+type Usage struct {
+	Containers []string `json:"containers"`
+	Services   []string `json:"services"`
+}
+
+func (u Usage) InUse() bool {
+	return len(u.Containers) > 0 || len(u.Services) > 0
+}
+
+// NewManager keeps backups in backupDir, one directory per volume, and
+// checks every interval whether any volume is due to be backed up.
+func NewManager(store store.Store, runtime runtime.Runtime, backupDir string, interval time.Duration) *Manager {
+	return &Manager{
+		store:     store,
+		runtime:   runtime,
+		backupDir: backupDir,
+		interval:  interval,
+		stopCh:    make(chan struct{}),
+		busy:      make(map[string]bool),
+		quiesced:  make(map[string]bool),
+	}
+}
+
+func (m *Manager) Start() {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stopCh:
+				log.Println("Volume manager stopped")
+				return
+			case <-ticker.C:
+				m.runSchedules()
+			}
+		}
+	}()
+	log.Println("Volume manager started")
+}
+
+func (m *Manager) Stop() {
+	close(m.stopCh)
+}
+
+// CreateVolume creates a volume in the runtime and stores it.
+func (m *Manager) CreateVolume(ctx context.Context, volume *models.Volume) error {
+	if volume.Driver == "" {
+		volume.Driver = models.VolumeDriverLocal
+	}
+	if err := ValidateVolume(*volume); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.store.GetVolume(volume.Name); err == nil {
+		return fmt.Errorf("volume %s already exists", volume.Name)
+	}
+	return m.createLocked(ctx, volume)
+}
+
+func (m *Manager) createLocked(ctx context.Context, volume *models.Volume) error {
+	volume.CreatedAt = time.Now()
+	mountpoint, err := m.runtime.CreateVolume(ctx, *volume)
+	if err != nil {
+		return err
+	}
+	volume.Mountpoint = mountpoint
+	if err := m.store.SaveVolume(*volume); err != nil {
+		if deleteErr := m.runtime.DeleteVolume(ctx, volume.Name); deleteErr != nil {
+			log.Printf("Cleanup failed: could not remove volume %s: %v", volume.Name, deleteErr)
+		}
+		return err
+	}
+	log.Printf("Volume %s created", volume.Name)
+	return nil
+}
+
+// EnsureVolumes creates the volumes mounted by name that do not exist yet.
+// They are marked as automatic but kept until deleted explicitly.
+func (m *Manager) EnsureVolumes(ctx context.Context, mounts []models.Mount) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, mount := range mounts {
+		if !isVolumeMount(mount) {
+			continue
+		}
+		if _, err := m.store.GetVolume(mount.Source); err == nil {
+			continue
+		}
+		volume := &models.Volume{Name: mount.Source, Driver: models.VolumeDriverLocal, Auto: true}
+		if err := m.createLocked(ctx, volume); err != nil {
+			return fmt.Errorf("failed to create volume %s: %w", mount.Source, err)
+		}
+	}
+	return nil
+}
+
+// DeleteVolume removes a volume, and the data in it, that nothing mounts.
+// Its backups are kept.
+func (m *Manager) DeleteVolume(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.store.GetVolume(name); err != nil {
+		return err
+	}
+	if m.isBusy(name) {
+		return fmt.Errorf("%w: %s", ErrVolumeBusy, name)
+	}
+	usage, err := m.Usage(name)
+	if err != nil {
+		return err
+	}
+	if usage.InUse() {
+		return fmt.Errorf("%w: %d containers and %d services mount volume %s",
+			ErrVolumeInUse, len(usage.Containers), len(usage.Services), name)
+	}
+
+	if err := m.runtime.DeleteVolume(ctx, name); err != nil {
+		return err
+	}
+	if err := m.store.DeleteVolume(name); err != nil {
+		return err
+	}
+	log.Printf("Volume %s removed", name)
+	return nil
+}
+
+// Usage returns what mounts a volume.
+func (m *Manager) Usage(name string) (Usage, error) {
+	containers, err := m.store.ListContainers()
+	if err != nil {
+		return Usage{}, err
+	}
+	services, err := m.store.ListServices()
+	if err != nil {
+		return Usage{}, err
+	}
+
+	usage := Usage{Containers: []string{}, Services: []string{}}
+	for _, container := range containers {
+		if mountsVolume(container.Mounts, name) {
+			usage.Containers = append(usage.Containers, container.ID)
+		}
+	}
+	// Services count even with no replicas, since replicas they create
+	// later mount their volumes.
+	for _, service := range services {
+		if mountsVolume(service.Mounts, name) {
+			usage.Services = append(usage.Services, service.ID)
+		}
+	}
+	sort.Strings(usage.Containers)
+	sort.Strings(usage.Services)
+	return usage, nil
+}
+
+func mountsVolume(mounts []models.Mount, name string) bool {
+	for _, mount := range mounts {
+		if isVolumeMount(mount) && mount.Source == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isVolumeMount(mount models.Mount) bool {
+	return mount.Type == "" || mount.Type == models.MountTypeVolume
+}
+
+// ValidateVolume checks the name, driver and backup schedule of a volume.
+func ValidateVolume(volume models.Volume) error {
+	if err := validateName(volume.Name); err != nil {
+		return err
+	}
+	if volume.Driver != "" && volume.Driver != models.VolumeDriverLocal {
+		return fmt.Errorf("unsupported driver: %s", volume.Driver)
+	}
+	return ValidateBackupSchedule(volume.BackupSchedule)
+}
+
+// ValidateMounts checks the type, source and target of each mount, and
+// that no two mounts share a target.
+func ValidateMounts(mounts []models.Mount) error {
+	targets := map[string]bool{}
+	for _, mount := range mounts {
+		if mount.Target == "" || !path.IsAbs(mount.Target) {
+			return fmt.Errorf("mount target must be an absolute path: %q", mount.Target)
+		}
+		target := path.Clean(mount.Target)
+		if target == "/" {
+			return errors.New("cannot mount over /")
+		}
+		if targets[target] {
+			return fmt.Errorf("more than one mount at %s", target)
+		}
+		targets[target] = true
+
+		switch mount.Type {
+		case "", models.MountTypeVolume:
+			if err := validateName(mount.Source); err != nil {
+				return fmt.Errorf("mount at %s: %v", target, err)
+			}
+			if mount.TmpfsSize != 0 {
+				return fmt.Errorf("mount at %s: tmpfsSize only applies to tmpfs mounts", target)
+			}
+		case models.MountTypeBind:
+			if !path.IsAbs(mount.Source) {
+				return fmt.Errorf("mount at %s: bind source must be an absolute path: %q", target, mount.Source)
+			}
+			if mount.TmpfsSize != 0 {
+				return fmt.Errorf("mount at %s: tmpfsSize only applies to tmpfs mounts", target)
+			}
+		case models.MountTypeTmpfs:
+			if mount.Source != "" {
+				return fmt.Errorf("mount at %s: tmpfs mounts have no source", target)
+			}
+			if mount.TmpfsSize < 0 {
+				return fmt.Errorf("mount at %s: tmpfsSize cannot be negative", target)
+			}
+		default:
+			return fmt.Errorf("unknown mount type: %s", mount.Type)
+		}
+	}
+	return nil
+}
+
+func validateName(name string) error {
+	if name == "" {
+		return errors.New("volume name is required")
+	}
+	for i, ch := range name {
+		valid := ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' ||
+			i > 0 && (ch == '-' || ch == '_' || ch == '.')
+		if !valid {
+			return fmt.Errorf("invalid volume name: %s", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package volume
+
+import (
+	"testing"
+
+	"podium/internal/models"
+)
+
+func TestValidateMounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		mounts  []models.Mount
+		wantErr bool
+	}{
+		{"volume", []models.Mount{{Source: "data", Target: "/data"}}, false},
+		{"bind", []models.Mount{{Type: models.MountTypeBind, Source: "/srv/www", Target: "/www", ReadOnly: true}}, false},
+		{"tmpfs", []models.Mount{{Type: models.MountTypeTmpfs, Target: "/tmp", TmpfsSize: 1 << 20}}, false},
+		{"relative target", []models.Mount{{Source: "data", Target: "data"}}, true},
+		{"root target", []models.Mount{{Source: "data", Target: "/"}}, true},
+		{"same target", []models.Mount{{Source: "a", Target: "/data"}, {Source: "b", Target: "/data/"}}, true},
+		{"invalid volume name", []models.Mount{{Source: "../data", Target: "/data"}}, true},
+		{"volume with tmpfs size", []models.Mount{{Source: "data", Target: "/data", TmpfsSize: 1}}, true},
+		{"relative bind source", []models.Mount{{Type: models.MountTypeBind, Source: "www", Target: "/www"}}, true},
+		{"tmpfs with source", []models.Mount{{Type: models.MountTypeTmpfs, Source: "data", Target: "/tmp"}}, true},
+		{"negative tmpfs size", []models.Mount{{Type: models.MountTypeTmpfs, Target: "/tmp", TmpfsSize: -1}}, true},
+		{"unknown type", []models.Mount{{Type: "nfs", Source: "data", Target: "/data"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMounts(tt.mounts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMounts() = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
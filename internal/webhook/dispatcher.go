@@ -0,0 +1,401 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"podium/internal/events"
+	"podium/internal/models"
+	"podium/internal/store"
+)
+
+const (
+	// maxAttempts is how many times a delivery is tried before it is marked
+	// as failed.
+	maxAttempts = 6
+	// initialBackoff is the wait before the first retry. It doubles after
+	// each failed attempt, up to maxBackoff.
+	initialBackoff = time.Second
+	maxBackoff     = 5 * time.Minute
+	// attemptTimeout bounds a single POST to a webhook.
+	attemptTimeout = 10 * time.Second
+	// deliveriesKept is how many deliveries are kept in each webhook's log.
+	deliveriesKept = 100
+
+	SignatureHeader = "X-Podium-Signature"
+	EventHeader     = "X-Podium-Event"
+	DeliveryHeader  = "X-Podium-Delivery"
+)
+
+// Payload is the JSON body POSTed to webhooks.
+type Payload struct {
+	DeliveryID uint64       `json:"deliveryId"`
+	WebhookID  string       `json:"webhookId"`
+	Event      models.Event `json:"event"`
+}
+
+// Dispatcher delivers lifecycle events from the event bus to the webhooks
+// subscribed to them, retrying failed deliveries with exponential backoff.
+type Dispatcher struct {
+	store  store.Store
+	events *events.Bus
+	client *http.Client
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	cancel func()
+
+	// last is the ID of the latest event dispatched, and delivered that of
+	// the latest event recorded for delivery to each webhook. Only the
+	// dispatch loop uses them.
+	last      uint64
+	delivered map[string]uint64
+}
+
+func NewDispatcher(store store.Store, bus *events.Bus) *Dispatcher {
+	return &Dispatcher{
+		store:     store,
+		events:    bus,
+		client:    &http.Client{Timeout: attemptTimeout},
+		stopCh:    make(chan struct{}),
+		delivered: make(map[string]uint64),
+	}
+}
+
+// Start delivers events from the event log in the background. It resumes
+// the deliveries that were pending when Podium stopped, and catches up on the
+// events stored since the latest one recorded for delivery to each webhook.
+func (d *Dispatcher) Start() error {
+	backlog, live, cancel, err := d.events.Subscribe(models.EventFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+	d.setCancel(cancel)
+	if err := d.resume(backlog); err != nil {
+		cancel()
+		return err
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.dispatch(backlog)
+		for {
+			for event := range live {
+				d.dispatch([]models.Event{event})
+			}
+
+			// The bus drops subscribers that fall behind, so catch up on
+			// the missed events from the event log.
+			select {
+			case <-d.stopCh:
+				return
+			default:
+			}
+			backlog, next, cancel, err := d.events.Subscribe(models.EventFilter{AfterID: d.last})
+			if err != nil {
+				log.Printf("Error resubscribing to events: %v", err)
+				select {
+				case <-time.After(time.Second):
+					continue
+				case <-d.stopCh:
+					return
+				}
+			}
+			d.setCancel(cancel)
+			d.dispatch(backlog)
+			live = next
+		}
+	}()
+
+	log.Println("Webhook dispatcher started")
+	return nil
+}
+
+// setCancel records how to cancel the current subscription, cancelling it
+// right away if the dispatcher has been stopped.
+func (d *Dispatcher) setCancel(cancel func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	select {
+	case <-d.stopCh:
+		cancel()
+	default:
+	}
+	d.cancel = cancel
+}
+
+// Stop stops delivering events and abandons pending retries.
+func (d *Dispatcher) Stop() {
+	close(d.stopCh)
+	d.mu.Lock()
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.mu.Unlock()
+	d.wg.Wait()
+	log.Println("Webhook dispatcher stopped")
+}
+
+// resume restarts the deliveries left pending in the delivery logs, taking
+// their events from eventLog, and records the latest event recorded for
+// delivery to each webhook, which dispatch continues from.
+func (d *Dispatcher) resume(eventLog []models.Event) error {
+	webhooks, err := d.store.ListWebhooks()
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	byID := make(map[uint64]models.Event, len(eventLog))
+	for _, event := range eventLog {
+		byID[event.ID] = event
+	}
+
+	for _, webhook := range webhooks {
+		deliveries, err := d.store.ListWebhookDeliveries(webhook.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list deliveries of webhook %s: %w", webhook.ID, err)
+		}
+		for _, delivery := range deliveries {
+			if delivery.Test {
+				continue
+			}
+			if delivery.EventID > d.delivered[webhook.ID] {
+				d.delivered[webhook.ID] = delivery.EventID
+			}
+			if delivery.State != models.WebhookDeliveryPending {
+				continue
+			}
+
+			event, ok := byID[delivery.EventID]
+			switch {
+			case !ok:
+				delivery.Error = "event no longer in the event log"
+				d.finish(&delivery)
+			case !webhook.Enabled:
+				delivery.Error = "webhook disabled"
+				d.finish(&delivery)
+			default:
+				log.Printf("Resuming delivery %d of %s event to webhook %s", delivery.ID, event.Type, webhook.ID)
+				d.wg.Add(1)
+				go func(webhook models.Webhook, delivery models.WebhookDelivery) {
+					defer d.wg.Done()
+					d.deliver(webhook, delivery, event)
+				}(webhook, delivery)
+			}
+		}
+	}
+	return nil
+}
+
+// dispatch starts a delivery of each of events to every enabled webhook
+// subscribed to its type, unless the event has already been recorded for
+// delivery to the webhook or is older than the webhook's settings. Events
+// that could not be stored, which have no ID, are always delivered.
+func (d *Dispatcher) dispatch(events []models.Event) {
+	if len(events) == 0 {
+		return
+	}
+	webhooks, err := d.store.ListWebhooks()
+	if err != nil {
+		log.Printf("Error listing webhooks for %d event(s): %v", len(events), err)
+		return
+	}
+
+	for _, event := range events {
+		if event.ID != 0 && event.ID <= d.last {
+			continue
+		}
+		for _, webhook := range webhooks {
+			if !webhook.Enabled || !webhook.Subscribes(event.Type) || event.Timestamp.Before(webhook.UpdatedAt) {
+				continue
+			}
+			if event.ID != 0 && event.ID <= d.delivered[webhook.ID] {
+				continue
+			}
+
+			delivery := newDelivery(webhook, event)
+			if err := d.store.SaveWebhookDelivery(&delivery, deliveriesKept); err != nil {
+				log.Printf("Failed to record delivery of %s event to webhook %s: %v", event.Type, webhook.ID, err)
+				continue
+			}
+			if event.ID != 0 {
+				d.delivered[webhook.ID] = event.ID
+			}
+
+			d.wg.Add(1)
+			go func(webhook models.Webhook, delivery models.WebhookDelivery, event models.Event) {
+				defer d.wg.Done()
+				d.deliver(webhook, delivery, event)
+			}(webhook, delivery, event)
+		}
+		if event.ID != 0 {
+			d.last = event.ID
+		}
+	}
+}
+
+// deliver tries to POST event to the webhook until it succeeds, fails
+// permanently or runs out of attempts, recording each attempt.
+func (d *Dispatcher) deliver(webhook models.Webhook, delivery models.WebhookDelivery, event models.Event) {
+	backoff := initialBackoff
+
+	for {
+		retry := d.attempt(context.Background(), webhook, &delivery, event)
+		if !retry || delivery.Attempts >= maxAttempts {
+			d.finish(&delivery)
+			return
+		}
+
+		if err := d.store.SaveWebhookDelivery(&delivery, deliveriesKept); err != nil {
+			log.Printf("Failed to update webhook delivery %d: %v", delivery.ID, err)
+		}
+		log.Printf("Delivery %d to webhook %s failed (attempt %d/%d), retrying in %s: %s",
+			delivery.ID, webhook.ID, delivery.Attempts, maxAttempts, backoff, delivery.Error)
+
+		select {
+		case <-time.After(backoff):
+		case <-d.stopCh:
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+
+		// Retry with the webhook's current settings, and give up if it has
+		// since been deleted or disabled.
+		current, err := d.store.GetWebhook(webhook.ID)
+		if err != nil {
+			return
+		}
+		if !current.Enabled {
+			delivery.Error = "webhook disabled"
+			d.finish(&delivery)
+			return
+		}
+		webhook = current
+	}
+}
+
+// Test sends a webhook.test event to the webhook once, without retrying, and
+// returns the recorded delivery.
+func (d *Dispatcher) Test(ctx context.Context, webhook models.Webhook) (models.WebhookDelivery, error) {
+	event := models.Event{
+		Type:      models.EventWebhookTest,
+		Timestamp: time.Now(),
+		Message:   "Test delivery from Podium",
+	}
+
+	delivery := newDelivery(webhook, event)
+	delivery.Test = true
+	if err := d.store.SaveWebhookDelivery(&delivery, deliveriesKept); err != nil {
+		return delivery, fmt.Errorf("failed to record delivery: %w", err)
+	}
+
+	d.attempt(ctx, webhook, &delivery, event)
+	if err := d.finish(&delivery); err != nil {
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+// attempt makes one delivery attempt, updating delivery with its outcome,
+// and reports whether a failure is worth retrying.
+func (d *Dispatcher) attempt(ctx context.Context, webhook models.Webhook, delivery *models.WebhookDelivery, event models.Event) bool {
+	delivery.Attempts++
+	delivery.StatusCode = 0
+	delivery.Error = ""
+
+	body, err := json.Marshal(Payload{
+		DeliveryID: delivery.ID,
+		WebhookID:  webhook.ID,
+		Event:      event,
+	})
+	if err != nil {
+		delivery.Error = fmt.Sprintf("failed to marshal payload: %v", err)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, attemptTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = fmt.Sprintf("invalid request: %v", err)
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Podium-Webhook")
+	req.Header.Set(EventHeader, string(event.Type))
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		delivery.Error = err.Error()
+		return true
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		delivery.State = models.WebhookDeliverySucceeded
+		return false
+	}
+
+	delivery.Error = fmt.Sprintf("unexpected status: %s", resp.Status)
+	// Other client errors mean the receiver rejected the payload, and
+	// sending it again will not help.
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusRequestTimeout
+}
+
+// finish marks delivery as completed and stores it.
+func (d *Dispatcher) finish(delivery *models.WebhookDelivery) error {
+	if delivery.State != models.WebhookDeliverySucceeded {
+		delivery.State = models.WebhookDeliveryFailed
+		log.Printf("Delivery %d to webhook %s failed after %d attempt(s): %s",
+			delivery.ID, delivery.WebhookID, delivery.Attempts, delivery.Error)
+	}
+	now := time.Now()
+	delivery.CompletedAt = &now
+
+	if err := d.store.SaveWebhookDelivery(delivery, deliveriesKept); err != nil {
+		log.Printf("Failed to update webhook delivery %d: %v", delivery.ID, err)
+		return fmt.Errorf("failed to record delivery: %w", err)
+	}
+	return nil
+}
+
+func newDelivery(webhook models.Webhook, event models.Event) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		State:     models.WebhookDeliveryPending,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex-encoded HMAC-SHA256 of body keyed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
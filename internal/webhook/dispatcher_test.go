@@ -0,0 +1,151 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"podium/internal/events"
+	"podium/internal/models"
+	"podium/internal/store"
+)
+
+// receiver records the IDs of the events POSTed to it, in order.
+type receiver struct {
+	*httptest.Server
+	mu     sync.Mutex
+	events []uint64
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var payload Payload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.mu.Lock()
+		r.events = append(r.events, payload.Event.ID)
+		r.mu.Unlock()
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() []uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]uint64(nil), r.events...)
+}
+
+// waitForEvents waits until the receiver has been sent want events, and
+// checks that it was sent each of them once.
+func (r *receiver) waitForEvents(t *testing.T, want int) []uint64 {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for len(r.received()) < want && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Give duplicate deliveries a chance to show up.
+	time.Sleep(50 * time.Millisecond)
+
+	received := r.received()
+	seen := make(map[uint64]bool)
+	for _, id := range received {
+		if seen[id] {
+			t.Errorf("event %d was delivered more than once", id)
+		}
+		seen[id] = true
+	}
+	if len(received) != want {
+		t.Fatalf("got %d deliveries, want %d", len(received), want)
+	}
+	return received
+}
+
+func saveWebhook(t *testing.T, stateStore store.Store, url string) models.Webhook {
+	t.Helper()
+
+	now := time.Now()
+	webhook := models.Webhook{ID: "hook", URL: url, Enabled: true, CreatedAt: now, UpdatedAt: now}
+	if err := stateStore.SaveWebhook(webhook); err != nil {
+		t.Fatalf("saving webhook: %v", err)
+	}
+	return webhook
+}
+
+func TestDispatcherDeliversBurstsWithoutLoss(t *testing.T) {
+	stateStore := store.NewMemoryStore()
+	bus := events.NewBus(stateStore, 10000)
+	receiver := newReceiver(t)
+	saveWebhook(t, stateStore, receiver.URL)
+
+	dispatcher := NewDispatcher(stateStore, bus)
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("starting dispatcher: %v", err)
+	}
+	defer dispatcher.Stop()
+
+	const published = 500
+	for i := 0; i < published; i++ {
+		bus.Publish(models.Event{Type: models.EventContainerStarted})
+	}
+	receiver.waitForEvents(t, published)
+}
+
+func TestDispatcherResumesFromEventLog(t *testing.T) {
+	stateStore := store.NewMemoryStore()
+	bus := events.NewBus(stateStore, 10000)
+	receiver := newReceiver(t)
+
+	// Events published before the webhook was set up are not delivered.
+	bus.Publish(models.Event{Type: models.EventContainerStarted})
+	time.Sleep(time.Millisecond)
+	webhook := saveWebhook(t, stateStore, receiver.URL)
+
+	// As left by a server that stopped while delivering the first event and
+	// before dispatching the second.
+	bus.Publish(models.Event{Type: models.EventContainerStarted})
+	bus.Publish(models.Event{Type: models.EventContainerStopped})
+	stored, err := stateStore.ListEvents(models.EventFilter{})
+	if err != nil {
+		t.Fatalf("listing events: %v", err)
+	}
+	pending := newDelivery(webhook, stored[1])
+	pending.Attempts = 1
+	if err := stateStore.SaveWebhookDelivery(&pending, deliveriesKept); err != nil {
+		t.Fatalf("saving delivery: %v", err)
+	}
+
+	dispatcher := NewDispatcher(stateStore, bus)
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("starting dispatcher: %v", err)
+	}
+	defer dispatcher.Stop()
+
+	received := receiver.waitForEvents(t, 2)
+	want := map[uint64]bool{stored[1].ID: true, stored[2].ID: true}
+	for _, id := range received {
+		if !want[id] {
+			t.Errorf("event %d was delivered, want only events %d and %d", id, stored[1].ID, stored[2].ID)
+		}
+	}
+
+	deliveries, err := stateStore.ListWebhookDeliveries(webhook.ID)
+	if err != nil {
+		t.Fatalf("listing deliveries: %v", err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("got %d deliveries, want 2", len(deliveries))
+	}
+	for _, delivery := range deliveries {
+		if delivery.State != models.WebhookDeliverySucceeded {
+			t.Errorf("delivery %d of event %d is %s, want succeeded", delivery.ID, delivery.EventID, delivery.State)
+		}
+	}
+}